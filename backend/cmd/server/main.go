@@ -9,6 +9,8 @@ import (
 	_ "github.com/EmilioCliff/crocheted-ecommerce/backend/docs/statik"
 	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/handlers"
 	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/mysql"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/services"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/workers"
 	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
 )
 
@@ -37,7 +39,7 @@ func main() {
 		log.Fatalf("failed to load config: %v", err)
 	}
 
-	tokenMaker, err := pkg.NewPaseto(config.TOKEN_SYMMETRY_KEY)
+	tokenMaker, err := pkg.NewMaker(config)
 	if err != nil {
 		log.Fatalf("failed to create token maker: %v", err)
 	}
@@ -53,6 +55,17 @@ func main() {
 
 	server.SetDependencies(store)
 
+	mailer, err := services.NewMailer(config)
+	if err != nil {
+		log.Fatalf("failed to create mailer: %v", err)
+	}
+
+	outboxWorker := workers.NewEmailOutboxWorker(mysql.NewEmailOutboxRepository(store), mailer, config)
+	outboxWorker.Start()
+
+	newsletterWorker := workers.NewNewsletterWorker(mysql.NewNewsletterRepository(store), mailer, config)
+	newsletterWorker.Start()
+
 	log.Println("Starting server at port: ", config.HTTP_PORT)
 	go func() {
 		if err := server.Start(); err != nil {
@@ -72,6 +85,9 @@ func main() {
 		log.Fatalf("failed to close server: %v", err)
 	}
 
+	newsletterWorker.Stop()
+	outboxWorker.Stop()
+
 	if err := store.Close(); err != nil {
 		log.Fatalf("failed to close store: %v", err)
 	}
@@ -0,0 +1,61 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// order event types
+const (
+	OrderCreated       = "order.created"
+	OrderPaid          = "order.paid"
+	OrderStatusChanged = "order.status_changed"
+)
+
+// Event is something that happened to an order, published once the change is saved.
+type Event struct {
+	Type    string  `json:"type"`
+	OrderID uint32  `json:"order_id"`
+	UserID  uint32  `json:"user_id"`
+	Status  string  `json:"status"`
+	Amount  float64 `json:"amount"`
+	// PreviousStatus is only set on OrderStatusChanged
+	PreviousStatus string    `json:"previous_status,omitempty"`
+	OccurredAt     time.Time `json:"occurred_at"`
+}
+
+type Handler func(ctx context.Context, event Event)
+
+// Bus hands events to the handlers subscribed in this process. Handlers run one
+// after the other in the publishing goroutine, so they should queue slow work
+// (like sending an email) instead of doing it.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers []Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+func (b *Bus) Subscribe(handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers = append(b.handlers, handler)
+}
+
+func (b *Bus) Publish(ctx context.Context, event Event) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	b.mu.RLock()
+	handlers := b.handlers
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(ctx, event)
+	}
+}
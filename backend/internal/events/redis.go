@@ -0,0 +1,107 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisChannel        = "crocheted-ecommerce:order-events"
+	redisConnectTimeout = 5 * time.Second
+)
+
+var _ Stream = (*RedisStream)(nil)
+
+// RedisStream publishes events to a redis channel. Each replica holds one
+// subscription to the channel and hands what arrives, its own events included,
+// to its local subscribers.
+type RedisStream struct {
+	client *redis.Client
+	pubsub *redis.PubSub
+	local  *MemoryStream
+	done   chan struct{}
+}
+
+func NewRedisStream(url string) (*RedisStream, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisConnectTimeout)
+	defer cancel()
+
+	pubsub := client.Subscribe(ctx, redisChannel)
+
+	// wait for the subscription so a wrong url fails on start up
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		client.Close()
+
+		return nil, fmt.Errorf("failed to subscribe to redis: %w", err)
+	}
+
+	s := &RedisStream{
+		client: client,
+		pubsub: pubsub,
+		local:  NewMemoryStream(),
+		done:   make(chan struct{}),
+	}
+
+	go s.receive()
+
+	return s, nil
+}
+
+// receive runs until the pubsub is closed, go-redis reconnects on its own.
+func (s *RedisStream) receive() {
+	defer close(s.done)
+
+	for msg := range s.pubsub.Channel() {
+		var event Event
+		if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+			log.Printf("failed to decode event from redis: %v", err)
+
+			continue
+		}
+
+		_ = s.local.Publish(context.Background(), event)
+	}
+}
+
+func (s *RedisStream) Publish(ctx context.Context, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	if err := s.client.Publish(ctx, redisChannel, data).Err(); err != nil {
+		return fmt.Errorf("failed to publish event to redis: %w", err)
+	}
+
+	return nil
+}
+
+func (s *RedisStream) Subscribe() *Subscription {
+	return s.local.Subscribe()
+}
+
+func (s *RedisStream) Close() error {
+	err := s.pubsub.Close()
+	<-s.done
+
+	s.local.Close()
+
+	if cerr := s.client.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
@@ -0,0 +1,116 @@
+package events
+
+import (
+	"context"
+	"sync"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+)
+
+// events a subscriber can fall behind by before it starts missing them
+const subscriptionBuffer = 32
+
+// Stream fans published events out to live subscribers, like the SSE
+// endpoints. Unlike Bus it is fire and forget, a slow subscriber misses events
+// instead of holding up the publisher.
+type Stream interface {
+	Publish(ctx context.Context, event Event) error
+	Subscribe() *Subscription
+	Close() error
+}
+
+// NewStream keeps events in this process, or shares them through redis pub/sub
+// when REDIS_URL is set so every replica sees events published on the others.
+func NewStream(config pkg.Config) (Stream, error) {
+	if config.REDIS_URL == "" {
+		return NewMemoryStream(), nil
+	}
+
+	return NewRedisStream(config.REDIS_URL)
+}
+
+// Subscription receives events until it is closed, Events is closed when the
+// stream shuts down.
+type Subscription struct {
+	Events <-chan Event
+
+	once   sync.Once
+	cancel func()
+}
+
+func (s *Subscription) Close() {
+	s.once.Do(s.cancel)
+}
+
+var _ Stream = (*MemoryStream)(nil)
+
+type MemoryStream struct {
+	mu          sync.Mutex
+	subscribers map[*Subscription]chan Event
+	closed      bool
+}
+
+func NewMemoryStream() *MemoryStream {
+	return &MemoryStream{
+		subscribers: make(map[*Subscription]chan Event),
+	}
+}
+
+func (m *MemoryStream) Publish(ctx context.Context, event Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, ch := range m.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// the subscriber is not keeping up, drop the event for it
+		}
+	}
+
+	return nil
+}
+
+func (m *MemoryStream) Subscribe() *Subscription {
+	ch := make(chan Event, subscriptionBuffer)
+	sub := &Subscription{Events: ch}
+	sub.cancel = func() { m.unsubscribe(sub) }
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		close(ch)
+
+		return sub
+	}
+
+	m.subscribers[sub] = ch
+
+	return sub
+}
+
+func (m *MemoryStream) unsubscribe(sub *Subscription) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if ch, ok := m.subscribers[sub]; ok {
+		delete(m.subscribers, sub)
+		close(ch)
+	}
+}
+
+// Close ends every subscription, later subscriptions are closed straight away.
+func (m *MemoryStream) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for sub, ch := range m.subscribers {
+		delete(m.subscribers, sub)
+		close(ch)
+	}
+
+	m.closed = true
+
+	return nil
+}
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+type userExportResponse struct {
+	ExportedAt              time.Time                           `json:"exported_at"`
+	Profile                 adminUserResponse                   `json:"profile"`
+	Addresses               []*repository.Address               `json:"addresses"`
+	Identities              []*repository.UserIdentity          `json:"identities"`
+	Orders                  []*orderResponse                    `json:"orders"`
+	Reviews                 []*repository.Review                `json:"reviews"`
+	Blogs                   []*repository.Blog                  `json:"blogs"`
+	Comments                []*repository.Comment               `json:"comments"`
+	Cart                    []*repository.Cart                  `json:"cart"`
+	Notifications           []*repository.Notification          `json:"notifications"`
+	NotificationPreferences *repository.NotificationPreferences `json:"notification_preferences"`
+	NewsletterDeliveries    []*repository.Delivery              `json:"newsletter_deliveries"`
+	Emails                  []exportedEmail                     `json:"emails"`
+	SecurityEvents          []*repository.SecurityEvent         `json:"security_events"`
+}
+
+// exportedEmail leaves the body out, a queued one can hold a password reset link.
+type exportedEmail struct {
+	Subject   string     `json:"subject"`
+	Template  string     `json:"template"`
+	Status    string     `json:"status"`
+	SentAt    *time.Time `json:"sent_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// exportUserData returns everything stored about the user as a single JSON
+// archive. Credentials and tokens are left out.
+func (s *HttpServer) exportUserData(ctx *gin.Context) {
+	id, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	rsp, err := s.userData(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"user-%d-export.json\"", id))
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// getUserOverview gives support staff the customers account, orders, reviews
+// and cart in one response.
+func (s *HttpServer) getUserOverview(ctx *gin.Context) {
+	id, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	rsp, err := s.userData(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+func (s *HttpServer) userData(ctx *gin.Context, id uint32) (*userExportResponse, error) {
+	user, err := s.repo.u.GetUserById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := &userExportResponse{
+		ExportedAt:     time.Now().UTC(),
+		Profile:        newAdminUserResponse(user),
+		Orders:         []*orderResponse{},
+		Notifications:  []*repository.Notification{},
+		SecurityEvents: []*repository.SecurityEvent{},
+	}
+
+	if rsp.Addresses, err = s.repo.addr.ListUserAddresses(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if rsp.Identities, err = s.repo.oauth.ListUserIdentities(ctx, id); err != nil {
+		return nil, err
+	}
+
+	orders, err := s.repo.o.ListUserOrders(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, order := range orders {
+		result, err := s.structureOrderResponse(ctx, order)
+		if err != nil {
+			return nil, err
+		}
+
+		rsp.Orders = append(rsp.Orders, result)
+	}
+
+	if rsp.Reviews, err = s.repo.r.ListUsersReviews(ctx, id); err != nil {
+		return nil, err
+	}
+
+	// drafts and scheduled blogs are the users data too
+	if rsp.Blogs, err = s.repo.b.ListAuthorBlogs(ctx, id, nil); err != nil {
+		return nil, err
+	}
+
+	if rsp.Comments, err = s.repo.comment.ListUserComments(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if rsp.Cart, err = s.repo.cart.ListUserCarts(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if rsp.NotificationPreferences, err = s.repo.notif.GetNotificationPreferences(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if rsp.NewsletterDeliveries, err = s.repo.newsletter.ListUserDeliveries(ctx, id); err != nil {
+		return nil, err
+	}
+
+	emails, err := s.repo.outbox.ListRecipientEmails(ctx, user.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp.Emails = []exportedEmail{}
+	for _, email := range emails {
+		rsp.Emails = append(rsp.Emails, exportedEmail{
+			Subject:   email.Subject,
+			Template:  email.Template,
+			Status:    email.Status,
+			SentAt:    email.SentAt,
+			CreatedAt: email.CreatedAt,
+		})
+	}
+
+	// notifications and security events are only listed a page at a time
+	filter := repository.NotificationFilter{UserID: id, Limit: maxPageLimit}
+	for {
+		notifications, err := s.repo.notif.ListUserNotifications(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		rsp.Notifications = append(rsp.Notifications, notifications...)
+
+		if len(notifications) < maxPageLimit {
+			break
+		}
+
+		filter.Offset += maxPageLimit
+	}
+
+	eventFilter := repository.SecurityEventFilter{UserID: &id, Limit: maxPageLimit}
+	for {
+		events, err := s.repo.sec.ListSecurityEvents(ctx, eventFilter)
+		if err != nil {
+			return nil, err
+		}
+
+		rsp.SecurityEvents = append(rsp.SecurityEvents, events...)
+
+		if len(events) < maxPageLimit {
+			break
+		}
+
+		eventFilter.Offset += maxPageLimit
+	}
+
+	return rsp, nil
+}
+
+type deleteAccountRequest struct {
+	Password string `binding:"required" json:"password"`
+}
+
+// deleteAccount lets users close their own account. Personal data is removed
+// or anonymised, orders and transactions stay for accounting.
+func (s *HttpServer) deleteAccount(ctx *gin.Context) {
+	id, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var req deleteAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+
+		return
+	}
+
+	user, err := s.repo.u.GetUserById(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	if err := pkg.ComparePasswordAndHash(user.Password, req.Password); err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(pkg.Errorf(pkg.AUTHENTICATION_ERROR, "invalid password")))
+
+		return
+	}
+
+	if err := s.repo.u.DeleteUser(ctx, id); err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	s.logSecurityEvent(ctx, &repository.SecurityEvent{
+		Event:  repository.EventAccountDeleted,
+		UserID: &id,
+	})
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+type createAddressRequest struct {
+	RecipientName string `binding:"required,max=255" json:"recipient_name"`
+	PhoneNumber   string `binding:"required,max=50"  json:"phone_number"`
+	County        string `binding:"required,max=100" json:"county"`
+	City          string `binding:"required,max=100" json:"city"`
+	Street        string `binding:"required,max=255" json:"street"`
+	PostalCode    string `binding:"max=20"           json:"postal_code"`
+	IsDefault     bool   `                           json:"is_default"`
+}
+
+func (s *HttpServer) createAddress(ctx *gin.Context) {
+	id, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var req createAddressRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+
+		return
+	}
+
+	address, err := s.repo.addr.CreateAddress(ctx, &repository.Address{
+		UserID:        id,
+		RecipientName: req.RecipientName,
+		PhoneNumber:   req.PhoneNumber,
+		County:        req.County,
+		City:          req.City,
+		Street:        req.Street,
+		PostalCode:    req.PostalCode,
+		IsDefault:     req.IsDefault,
+	})
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, address)
+}
+
+func (s *HttpServer) listUserAddresses(ctx *gin.Context) {
+	id, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	addresses, err := s.repo.addr.ListUserAddresses(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, addresses)
+}
+
+func (s *HttpServer) getAddress(ctx *gin.Context) {
+	id, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	addressId, err := getParam(ctx.Param("addressId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	address, err := s.repo.addr.GetUserAddress(ctx, id, addressId)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, address)
+}
+
+type updateAddressRequest struct {
+	RecipientName *string `binding:"omitempty,min=1,max=255" json:"recipient_name"`
+	PhoneNumber   *string `binding:"omitempty,min=1,max=50"  json:"phone_number"`
+	County        *string `binding:"omitempty,min=1,max=100" json:"county"`
+	City          *string `binding:"omitempty,min=1,max=100" json:"city"`
+	Street        *string `binding:"omitempty,min=1,max=255" json:"street"`
+	PostalCode    *string `binding:"omitempty,max=20"        json:"postal_code"`
+}
+
+func (s *HttpServer) updateAddress(ctx *gin.Context) {
+	id, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	addressId, err := getParam(ctx.Param("addressId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var req updateAddressRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+
+		return
+	}
+
+	address, err := s.repo.addr.UpdateAddress(ctx, &repository.UpdateAddress{
+		ID:            addressId,
+		UserID:        id,
+		RecipientName: req.RecipientName,
+		PhoneNumber:   req.PhoneNumber,
+		County:        req.County,
+		City:          req.City,
+		Street:        req.Street,
+		PostalCode:    req.PostalCode,
+	})
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, address)
+}
+
+func (s *HttpServer) setDefaultAddress(ctx *gin.Context) {
+	id, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	addressId, err := getParam(ctx.Param("addressId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	if err := s.repo.addr.SetDefaultAddress(ctx, id, addressId); err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+func (s *HttpServer) deleteAddress(ctx *gin.Context) {
+	id, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	addressId, err := getParam(ctx.Param("addressId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	if err := s.repo.addr.DeleteAddress(ctx, id, addressId); err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
+}
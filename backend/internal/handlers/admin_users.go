@@ -0,0 +1,425 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/services"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+// likeEscaper makes user input match literally inside a LIKE pattern
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+type adminUserResponse struct {
+	userResponse
+	PasswordResetRequired bool       `json:"password_reset_required"`
+	UpdatedAt             time.Time  `json:"updated_at"`
+	CreatedAt             time.Time  `json:"created_at"`
+	DisabledAt            *time.Time `json:"disabled_at"`
+	DeletedAt             *time.Time `json:"deleted_at"`
+}
+
+func newAdminUserResponse(user *repository.User) adminUserResponse {
+	return adminUserResponse{
+		userResponse: userResponse{
+			ID:           user.ID,
+			Email:        user.Email,
+			FullName:     user.FullName,
+			PhoneNumber:  user.PhoneNumber,
+			Role:         user.Role,
+			Subscription: user.Subscription,
+		},
+		PasswordResetRequired: user.PasswordResetRequired,
+		UpdatedAt:             user.UpdatedAt,
+		CreatedAt:             user.CreatedAt,
+		DisabledAt:            user.DisabledAt,
+		DeletedAt:             user.DeletedAt,
+	}
+}
+
+// auditedUser loads the user in the shape that is recorded in the audit log.
+func (s *HttpServer) auditedUser(ctx *gin.Context, id uint32) (*adminUserResponse, error) {
+	user, err := s.repo.u.GetUserById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := newAdminUserResponse(user)
+
+	return &rsp, nil
+}
+
+// listUsers searches users by email, role, signup date, whether they ordered
+// and whether they are disabled. Dates are RFC3339 or YYYY-MM-DD.
+func (s *HttpServer) listUsers(ctx *gin.Context) {
+	filter := repository.UserFilter{}
+
+	if email := strings.TrimSpace(ctx.Query("email")); email != "" {
+		filter.Email = pkg.StringPtr("%" + likeEscaper.Replace(strings.ToLower(email)) + "%")
+	}
+
+	if role := ctx.Query("role"); role != "" {
+		filter.Role = pkg.StringPtr(strings.ToUpper(role))
+	}
+
+	for key, dst := range map[string]**time.Time{
+		"created_after":  &filter.CreatedAfter,
+		"created_before": &filter.CreatedBefore,
+	} {
+		value := ctx.Query(key)
+		if value == "" {
+			continue
+		}
+
+		t, err := parseQueryTime(value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s must be RFC3339 or YYYY-MM-DD", key)))
+
+			return
+		}
+
+		*dst = &t
+	}
+
+	for key, dst := range map[string]**bool{
+		"has_orders": &filter.HasOrders,
+		"disabled":   &filter.Disabled,
+	} {
+		value := ctx.Query(key)
+		if value == "" {
+			continue
+		}
+
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s must be true or false", key)))
+
+			return
+		}
+
+		*dst = &b
+	}
+
+	limit, offset, err := parsePagination(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	filter.Limit = limit
+	filter.Offset = offset
+
+	users, err := s.repo.u.SearchUsers(ctx, filter)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	response := []adminUserResponse{}
+	for _, user := range users {
+		response = append(response, newAdminUserResponse(user))
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func parseQueryTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	return time.Parse(time.DateOnly, value)
+}
+
+type setUserDisabledRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (s *HttpServer) disableUser(ctx *gin.Context) {
+	s.setUserDisabled(ctx, true)
+}
+
+func (s *HttpServer) enableUser(ctx *gin.Context) {
+	s.setUserDisabled(ctx, false)
+}
+
+func (s *HttpServer) setUserDisabled(ctx *gin.Context, disabled bool) {
+	payload, err := getPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	id, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	if disabled && id == payload.UserID {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "cannot disable your own account")))
+
+		return
+	}
+
+	// the reason is optional so an empty body is fine
+	var req setUserDisabledRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+
+			return
+		}
+	}
+
+	before, err := s.auditedUser(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	if err := s.repo.u.SetUserDisabled(ctx, payload.UserID, id, disabled); err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	after, err := s.auditedUser(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	s.audit(ctx, repository.AuditUpdate, repository.AuditEntityUser, id, before, after)
+
+	event := repository.EventUserEnabled
+	if disabled {
+		event = repository.EventUserDisabled
+	}
+
+	s.logSecurityEvent(ctx, &repository.SecurityEvent{
+		Event:   event,
+		UserID:  &id,
+		ActorID: &payload.UserID,
+		Details: req.Reason,
+	})
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+// passwordTokenResponse leaves out the token, it is only ever emailed to the
+// user so an admin cannot use it to take the account over.
+type passwordTokenResponse struct {
+	UserID    uint32    `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// newPasswordToken returns the token to hand to the user and the record that
+// stores its hash.
+func newPasswordToken(purpose string, createdBy uint32, duration time.Duration) (string, *repository.PasswordToken, error) {
+	token, err := pkg.RandomURLToken(32)
+	if err != nil {
+		return "", nil, pkg.Errorf(pkg.INTERNAL_ERROR, "%v", err)
+	}
+
+	return token, &repository.PasswordToken{
+		TokenHash: pkg.HashToken(token),
+		Purpose:   purpose,
+		ExpiresAt: time.Now().Add(duration),
+		CreatedBy: &createdBy,
+	}, nil
+}
+
+// passwordTokenLink is the page where a reset or invite token is redeemed.
+func (s *HttpServer) passwordTokenLink(token string) string {
+	return pkg.JoinURL(s.config.PUBLIC_BASE_URL, "/reset-password") + "?token=" + url.QueryEscape(token)
+}
+
+// forcePasswordReset signs the user out and blocks logins until a new password
+// is set with the token emailed to the user.
+func (s *HttpServer) forcePasswordReset(ctx *gin.Context) {
+	payload, err := getPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	id, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	token, record, err := newPasswordToken(repository.PasswordTokenReset, payload.UserID, s.config.PASSWORD_RESET_DURATION)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	record.UserID = id
+
+	before, err := s.auditedUser(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	if err := s.repo.u.RequirePasswordReset(ctx, record); err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	after, err := s.auditedUser(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	s.audit(ctx, repository.AuditUpdate, repository.AuditEntityUser, id, before, after)
+
+	s.logSecurityEvent(ctx, &repository.SecurityEvent{
+		Event:   repository.EventResetForced,
+		UserID:  &id,
+		ActorID: &payload.UserID,
+	})
+
+	s.queueEmail(ctx, after.Email, services.EmailPasswordResetRequired, services.PasswordResetEmailData{
+		Link:      s.passwordTokenLink(token),
+		ExpiresIn: s.config.PASSWORD_RESET_DURATION,
+	})
+
+	ctx.JSON(http.StatusAccepted, passwordTokenResponse{
+		UserID:    id,
+		ExpiresAt: record.ExpiresAt,
+	})
+}
+
+type inviteUserRequest struct {
+	Email    string `binding:"required,email" json:"email"`
+	Role     string `                         json:"role"`
+	FullName string `binding:"max=255"        json:"full_name"`
+}
+
+// inviteUser creates an account for someone else and emails them a token, which
+// is redeemed with confirmPasswordReset to choose a password.
+func (s *HttpServer) inviteUser(ctx *gin.Context) {
+	payload, err := getPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var req inviteUserRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+
+		return
+	}
+
+	role := strings.ToUpper(req.Role)
+	if role == "" {
+		role = "USER"
+	}
+
+	token, record, err := newPasswordToken(repository.PasswordTokenInvite, payload.UserID, s.config.INVITE_DURATION)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	user, err := s.repo.u.InviteUser(ctx, &repository.User{
+		Email:    strings.ToLower(strings.TrimSpace(req.Email)),
+		FullName: req.FullName,
+		Role:     role,
+	}, record)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	s.audit(ctx, repository.AuditCreate, repository.AuditEntityUser, user.ID, nil, newAdminUserResponse(user))
+
+	s.logSecurityEvent(ctx, &repository.SecurityEvent{
+		Event:   repository.EventUserInvited,
+		UserID:  &user.ID,
+		ActorID: &payload.UserID,
+		Email:   user.Email,
+		Details: fmt.Sprintf("invited as %s", user.Role),
+	})
+
+	s.queueEmail(ctx, user.Email, services.EmailInvitation, services.PasswordResetEmailData{
+		Link:      s.passwordTokenLink(token),
+		ExpiresIn: s.config.INVITE_DURATION,
+	})
+
+	ctx.JSON(http.StatusAccepted, passwordTokenResponse{
+		UserID:    user.ID,
+		ExpiresAt: record.ExpiresAt,
+	})
+}
+
+type confirmPasswordResetRequest struct {
+	Token    string `binding:"required" json:"token"`
+	Password string `binding:"required" json:"password"`
+}
+
+// confirmPasswordReset redeems a reset or invite token for a new password.
+func (s *HttpServer) confirmPasswordReset(ctx *gin.Context) {
+	var req confirmPasswordResetRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+
+		return
+	}
+
+	user, err := s.repo.u.ResetPassword(ctx, pkg.HashToken(req.Token), req.Password)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	s.logSecurityEvent(ctx, &repository.SecurityEvent{
+		Event:  repository.EventPasswordReset,
+		UserID: &user.ID,
+		Email:  user.Email,
+	})
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+// checkAccountActive runs once the credentials are known to be right, so it
+// does not tell strangers whether an account exists.
+func checkAccountActive(user *repository.User) error {
+	if user.DisabledAt != nil {
+		return pkg.Errorf(pkg.FORBIDDEN_ERROR, "account is disabled")
+	}
+
+	if user.PasswordResetRequired {
+		return pkg.Errorf(pkg.FORBIDDEN_ERROR, "password reset required")
+	}
+
+	return nil
+}
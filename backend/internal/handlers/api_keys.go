@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// role set on the payload of requests made with an api key
+	apiKeyRole = "API_KEY"
+
+	apiKeyPrefix       = "crk_"
+	apiKeyDisplayChars = 12
+	// last_used_at is written at most this often per key
+	apiKeyTouchInterval = time.Minute
+)
+
+// apiKeyCaller is stored on requests authenticated with an api key.
+type apiKeyCaller struct {
+	key       *repository.APIKey
+	ownerRole string
+}
+
+type createAPIKeyRequest struct {
+	Name      string     `binding:"required,max=124" json:"name"`
+	Scopes    []string   `binding:"required,min=1"   json:"scopes"`
+	ExpiresAt *time.Time `binding:""                 json:"expires_at"`
+}
+
+type createAPIKeyResponse struct {
+	*repository.APIKey
+	// only returned here, it cannot be recovered later
+	Key string `json:"key"`
+}
+
+func (s *HttpServer) createAPIKey(ctx *gin.Context) {
+	payload, err := getPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var req createAPIKeyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+
+		return
+	}
+
+	scopes, err := s.apiKeyScopes(ctx, payload, req.Scopes)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	secret, err := pkg.RandomURLToken(32)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "%v", err)))
+
+		return
+	}
+
+	rawKey := apiKeyPrefix + secret
+
+	key, err := s.repo.apiKey.CreateAPIKey(ctx, &repository.APIKey{
+		Name:      strings.TrimSpace(req.Name),
+		Prefix:    rawKey[:apiKeyDisplayChars],
+		KeyHash:   pkg.HashToken(rawKey),
+		Scopes:    scopes,
+		CreatedBy: payload.UserID,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	s.audit(ctx, repository.AuditCreate, repository.AuditEntityAPIKey, key.ID, nil, key)
+
+	ctx.JSON(http.StatusCreated, createAPIKeyResponse{
+		APIKey: key,
+		Key:    rawKey,
+	})
+}
+
+// apiKeyScopes checks every scope is a known permission the caller holds, so a
+// key can never be used to gain access its creator does not have.
+func (s *HttpServer) apiKeyScopes(ctx *gin.Context, payload *pkg.Payload, requested []string) ([]string, error) {
+	permissions, err := s.repo.role.ListPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(permissions))
+	for _, permission := range permissions {
+		known[permission.Name] = true
+	}
+
+	scopes := []string{}
+	for _, scope := range requested {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if containsString(scopes, scope) {
+			continue
+		}
+
+		if !known[scope] {
+			return nil, pkg.Errorf(pkg.INVALID_ERROR, "unknown scope: %s", scope)
+		}
+
+		allowed, err := s.hasPermission(ctx, payload, scope)
+		if err != nil {
+			return nil, err
+		}
+
+		if !allowed {
+			return nil, pkg.Errorf(pkg.FORBIDDEN_ERROR, "cannot grant a scope you do not hold: %s", scope)
+		}
+
+		scopes = append(scopes, scope)
+	}
+
+	return scopes, nil
+}
+
+func (s *HttpServer) listAPIKeys(ctx *gin.Context) {
+	keys, err := s.repo.apiKey.ListAPIKeys(ctx)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, keys)
+}
+
+func (s *HttpServer) getAPIKey(ctx *gin.Context) {
+	id, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	key, err := s.repo.apiKey.GetAPIKey(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, key)
+}
+
+func (s *HttpServer) revokeAPIKey(ctx *gin.Context) {
+	id, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	before, err := s.repo.apiKey.GetAPIKey(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	if err := s.repo.apiKey.RevokeAPIKey(ctx, id); err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	after, err := s.repo.apiKey.GetAPIKey(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	s.audit(ctx, repository.AuditUpdate, repository.AuditEntityAPIKey, id, before, after)
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+// fields that never belong in the audit log
+var auditRedactedFields = []string{"password", "refresh_token"}
+
+// audit records an administrative change made by the caller. before is the
+// entity as it was and after as it is now, either may be nil for creates and
+// deletes. Only the fields that differ are stored. Like logSecurityEvent it
+// never fails the request, a lost entry is only logged.
+func (s *HttpServer) audit(ctx *gin.Context, action string, entityType string, entityID any, before any, after any) {
+	entry := &repository.AuditEntry{
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   fmt.Sprint(entityID),
+		IPAddress:  ctx.ClientIP(),
+		RequestID:  ctx.GetString(requestIDKey),
+	}
+
+	if payload, err := getPayload(ctx); err == nil {
+		entry.ActorID = &payload.UserID
+	}
+
+	var err error
+
+	entry.Before, entry.After, err = auditDiff(before, after)
+	if err != nil {
+		log.Printf("failed to diff audit entry %s %s %s: %v", action, entityType, entry.EntityID, err)
+
+		return
+	}
+
+	if err := s.repo.audit.CreateAuditEntry(ctx, entry); err != nil {
+		log.Printf("failed to log audit entry %s %s %s: %v", action, entityType, entry.EntityID, err)
+	}
+}
+
+// auditDiff compares the json form of before and after and returns only the
+// fields that were added, removed or changed on each side.
+func auditDiff(before any, after any) (json.RawMessage, json.RawMessage, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if beforeFields != nil && afterFields != nil {
+		for key, value := range beforeFields {
+			if other, ok := afterFields[key]; ok && reflect.DeepEqual(value, other) {
+				delete(beforeFields, key)
+				delete(afterFields, key)
+			}
+		}
+	}
+
+	beforeJSON, err := marshalAuditFields(beforeFields)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	afterJSON, err := marshalAuditFields(afterFields)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return beforeJSON, afterJSON, nil
+}
+
+func auditFields(v any) (map[string]any, error) {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	for _, field := range auditRedactedFields {
+		delete(fields, field)
+	}
+
+	return fields, nil
+}
+
+func marshalAuditFields(fields map[string]any) (json.RawMessage, error) {
+	if fields == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(fields)
+}
+
+func (s *HttpServer) listAuditLog(ctx *gin.Context) {
+	filter := repository.AuditFilter{}
+
+	if actor := ctx.Query("actor_id"); actor != "" {
+		id, err := getParam(actor)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "actor_id must be a user id")))
+
+			return
+		}
+
+		filter.ActorID = &id
+	}
+
+	if action := ctx.Query("action"); action != "" {
+		filter.Action = pkg.StringPtr(strings.ToUpper(action))
+	}
+
+	if entityType := ctx.Query("entity_type"); entityType != "" {
+		filter.EntityType = pkg.StringPtr(strings.ToLower(entityType))
+	}
+
+	if entityID := ctx.Query("entity_id"); entityID != "" {
+		filter.EntityID = pkg.StringPtr(entityID)
+	}
+
+	if requestID := ctx.Query("request_id"); requestID != "" {
+		filter.RequestID = pkg.StringPtr(requestID)
+	}
+
+	for key, dst := range map[string]**time.Time{
+		"from": &filter.CreatedAfter,
+		"to":   &filter.CreatedBefore,
+	} {
+		value := ctx.Query(key)
+		if value == "" {
+			continue
+		}
+
+		t, err := parseQueryTime(value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%s must be RFC3339 or YYYY-MM-DD", key)))
+
+			return
+		}
+
+		*dst = &t
+	}
+
+	limit, offset, err := parsePagination(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	filter.Limit = limit
+	filter.Offset = offset
+
+	entries, err := s.repo.audit.ListAuditEntries(ctx, filter)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entries)
+}
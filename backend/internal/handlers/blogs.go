@@ -3,6 +3,8 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
 	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
@@ -10,77 +12,110 @@ import (
 )
 
 type createBlogRequest struct {
-	Title   string   `binding:"required" json:"title"`
-	Content string   `binding:"required" json:"content"`
-	ImgUrls []string `binding:""         json:"img_urls"`
+	Title      string     `binding:"required"                                json:"title"`
+	Content    string     `binding:"required,max=100000"                     json:"content"` // markdown
+	ImgUrls    []string   `binding:""                                        json:"img_urls"`
+	Slug       string     `binding:""                                        json:"slug"`        // made from the title when empty
+	Status     string     `binding:"omitempty,oneof=DRAFT PUBLISHED ARCHIVED" json:"status"`     // DRAFT when empty
+	PublishAt  *time.Time `binding:""                                        json:"publish_at"`  // schedules a PUBLISHED blog
+	Tags       []string   `binding:"max=10,dive,required,max=50"             json:"tags"`        // e.g. pattern-of-the-week
+	ProductIDs []uint32   `binding:"max=10"                                  json:"product_ids"` // products featured in the post
+}
+
+// blogLinksRequest tells an update that leaves the tags or products alone
+// apart from one that clears them.
+type blogLinksRequest struct {
+	Tags       *[]string `json:"tags"`
+	ProductIDs *[]uint32 `json:"product_ids"`
 }
 
 func (s *HttpServer) createBlog(ctx *gin.Context) {
-	payload, err := getPayload(ctx)
+	id, err := getParam(ctx.Param("id"))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 
 		return
 	}
 
-	if isAdmin, err := isAdmin(payload); !isAdmin {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	var req createBlogRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
 
 		return
 	}
 
-	id, err := getParam(ctx.Param("id"))
+	data := &repository.Blog{
+		Author:     id,
+		Title:      req.Title,
+		Slug:       req.Slug,
+		Content:    req.Content,
+		Status:     req.Status,
+		PublishAt:  req.PublishAt,
+		Tags:       req.Tags,
+		ProductIDs: req.ProductIDs,
+	}
+
+	err = data.MarshalOptions(req.ImgUrls)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
 
 		return
 	}
 
-	if id != payload.UserID {
-		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "user id does not match")))
+	blog, err := s.repo.b.CreateBlog(ctx, data)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
 
 		return
 	}
 
-	var req createBlogRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+	s.audit(ctx, repository.AuditCreate, repository.AuditEntityBlog, blog.ID, nil, blog)
 
-		return
+	ctx.JSON(http.StatusOK, blog)
+}
+
+// listBlogs serves GET /blogs?tag=&q=&limit=&offset=. q is a full-text
+// search on the title and content, results are then ordered by relevance.
+func (s *HttpServer) listBlogs(ctx *gin.Context) {
+	filter := repository.BlogFilter{}
+
+	if tag := ctx.Query("tag"); tag != "" {
+		filter.Tag = pkg.StringPtr(tag)
 	}
 
-	data := &repository.Blog{
-		Author:  payload.UserID,
-		Title:   req.Title,
-		Content: req.Content,
+	if q := strings.TrimSpace(ctx.Query("q")); q != "" {
+		filter.Query = pkg.StringPtr(q)
 	}
 
-	err = data.MarshalOptions(req.ImgUrls)
+	limit, offset, err := parsePagination(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 
 		return
 	}
 
-	blog, err := s.repo.b.CreateBlog(ctx, data)
+	filter.Limit = limit
+	filter.Offset = offset
+
+	blogs, err := s.repo.b.SearchBlogs(ctx, filter)
 	if err != nil {
 		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
 
 		return
 	}
 
-	ctx.JSON(http.StatusOK, blog)
+	ctx.JSON(http.StatusOK, blogs)
 }
 
-func (S *HttpServer) listBlogs(ctx *gin.Context) {
-	blogs, err := S.repo.b.ListBlogs(ctx)
+func (s *HttpServer) listBlogTags(ctx *gin.Context) {
+	tags, err := s.repo.b.ListTags(ctx)
 	if err != nil {
 		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
 
 		return
 	}
 
-	ctx.JSON(http.StatusOK, blogs)
+	ctx.JSON(http.StatusOK, tags)
 }
 
 func (s *HttpServer) getBlog(ctx *gin.Context) {
@@ -91,7 +126,7 @@ func (s *HttpServer) getBlog(ctx *gin.Context) {
 		return
 	}
 
-	blog, err := s.repo.b.GetBlog(ctx, id)
+	blog, err := s.repo.b.GetPublishedBlog(ctx, id)
 	if err != nil {
 		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
 
@@ -101,16 +136,21 @@ func (s *HttpServer) getBlog(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, blog)
 }
 
-func (s *HttpServer) updateBlog(ctx *gin.Context) {
-	payload, err := getPayload(ctx)
+func (s *HttpServer) getBlogBySlug(ctx *gin.Context) {
+	blog, err := s.repo.b.GetPublishedBlogBySlug(ctx, ctx.Param("slug"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
 
 		return
 	}
 
-	if isAdmin, err := isAdmin(payload); !isAdmin {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	ctx.JSON(http.StatusOK, blog)
+}
+
+func (s *HttpServer) updateBlog(ctx *gin.Context) {
+	payload, err := getPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 
 		return
 	}
@@ -129,6 +169,13 @@ func (s *HttpServer) updateBlog(ctx *gin.Context) {
 		return
 	}
 
+	var links blogLinksRequest
+	if err := json.Unmarshal(body, &links); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+
+		return
+	}
+
 	id, err := getParam(ctx.Param("blogId"))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
@@ -136,44 +183,61 @@ func (s *HttpServer) updateBlog(ctx *gin.Context) {
 		return
 	}
 
-	blog, err := s.repo.b.GetBlog(ctx, id)
+	data := &repository.UpdateBlog{
+		ID:      id,
+		Title:   pkg.StringPtr(req.Title),
+		Content: pkg.StringPtr(req.Content),
+	}
+
+	err = data.MarshalOptions(req.ImgUrls)
 	if err != nil {
-		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
 
 		return
 	}
 
-	if blog.Author != payload.UserID {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "unauthorized to change another user blog")))
+	before, err := s.repo.b.GetBlog(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
 
 		return
 	}
 
-	data := &repository.UpdateBlog{
-		ID:      id,
-		Title:   pkg.StringPtr(req.Title),
-		Content: pkg.StringPtr(req.Content),
+	update := &repository.UpdateBlog{
+		ID:         id,
+		Author:     payload.UserID,
+		Title:      pkg.StringPtr(req.Title),
+		Content:    pkg.StringPtr(req.Content),
+		ImgUrls:    data.ImgUrls,
+		PublishAt:  req.PublishAt,
+		Tags:       links.Tags,
+		ProductIDs: links.ProductIDs,
 	}
 
-	err = data.MarshalOptions(req.ImgUrls)
+	if req.Slug != "" {
+		update.Slug = pkg.StringPtr(req.Slug)
+	}
+
+	if req.Status != "" {
+		update.Status = pkg.StringPtr(strings.ToUpper(req.Status))
+	}
+
+	err = s.repo.b.UpdateBlog(ctx, update)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
 
 		return
 	}
 
-	err = s.repo.b.UpdateBlog(ctx, &repository.UpdateBlog{
-		ID:      id,
-		Title:   pkg.StringPtr(req.Title),
-		Content: pkg.StringPtr(req.Content),
-		ImgUrls: data.ImgUrls,
-	})
+	after, err := s.repo.b.GetBlog(ctx, id)
 	if err != nil {
 		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
 
 		return
 	}
 
+	s.audit(ctx, repository.AuditUpdate, repository.AuditEntityBlog, id, before, after)
+
 	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
 }
 
@@ -195,46 +259,61 @@ func (s *HttpServer) getBlogsByAuthor(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, blogs)
 }
 
-func (s *HttpServer) deleteBlog(ctx *gin.Context) {
-	id, err := getParam(ctx.Param("blogId"))
+// listAuthorBlogs is the editor view of an author's blogs, drafts and
+// scheduled posts included. ?status= narrows it to one status.
+func (s *HttpServer) listAuthorBlogs(ctx *gin.Context) {
+	id, err := getParam(ctx.Param("id"))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 
 		return
 	}
 
-	payload, err := getPayload(ctx)
+	var status *string
+	if q := ctx.Query("status"); q != "" {
+		status = pkg.StringPtr(strings.ToUpper(q))
+	}
+
+	blogs, err := s.repo.b.ListAuthorBlogs(ctx, id, status)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
 
 		return
 	}
 
-	if isAdmin, err := isAdmin(payload); !isAdmin {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	ctx.JSON(http.StatusOK, blogs)
+}
+
+func (s *HttpServer) deleteBlog(ctx *gin.Context) {
+	id, err := getParam(ctx.Param("blogId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 
 		return
 	}
 
-	blog, err := s.repo.b.GetBlog(ctx, id)
+	payload, err := getPayload(ctx)
 	if err != nil {
-		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 
 		return
 	}
 
-	if blog.Author != payload.UserID {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "unauthorized to delete another user blog")))
+	before, err := s.repo.b.GetBlog(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
 
 		return
 	}
 
-	err = s.repo.b.DeleteBlog(ctx, id)
+	err = s.repo.b.DeleteBlog(ctx, id, payload.UserID)
 	if err != nil {
 		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
 
 		return
 	}
 
+	s.audit(ctx, repository.AuditDelete, repository.AuditEntityBlog, id, before, nil)
+
 	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
 }
@@ -39,13 +39,6 @@ type createCart struct {
 }
 
 func (s *HttpServer) createCart(ctx *gin.Context) {
-	payload, err := getPayload(ctx)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-
-		return
-	}
-
 	id, err := getParam(ctx.Param("id"))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
@@ -53,12 +46,6 @@ func (s *HttpServer) createCart(ctx *gin.Context) {
 		return
 	}
 
-	if id != payload.UserID {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(pkg.Errorf(pkg.AUTHENTICATION_ERROR, "cannot create anothet users cart")))
-
-		return
-	}
-
 	var req createCart
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
@@ -71,7 +58,7 @@ func (s *HttpServer) createCart(ctx *gin.Context) {
 	// create cart
 	for _, cart := range req.Data {
 		cart, err := s.repo.cart.CreateCart(ctx, &repository.Cart{
-			UserID:    payload.UserID,
+			UserID:    id,
 			ProductID: cart.ProductID,
 			Quantity:  cart.Quantity,
 		})
@@ -91,19 +78,12 @@ func (s *HttpServer) createCart(ctx *gin.Context) {
 		return
 	}
 
-	rsp.ID = payload.UserID
+	rsp.ID = id
 
 	ctx.JSON(http.StatusOK, rsp)
 }
 
 func (s *HttpServer) updateCart(ctx *gin.Context) {
-	payload, err := getPayload(ctx)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-
-		return
-	}
-
 	id, err := getParam(ctx.Param("id"))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
@@ -111,12 +91,6 @@ func (s *HttpServer) updateCart(ctx *gin.Context) {
 		return
 	}
 
-	if id != payload.UserID {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(pkg.Errorf(pkg.AUTHENTICATION_ERROR, "cannot create anothet users cart")))
-
-		return
-	}
-
 	body, err := ctx.GetRawData()
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
@@ -133,7 +107,7 @@ func (s *HttpServer) updateCart(ctx *gin.Context) {
 
 	// update cart
 	for _, cart := range req.Data {
-		err := s.repo.cart.UpdateCart(ctx, cart.Quantity, payload.UserID, cart.ProductID)
+		err := s.repo.cart.UpdateCart(ctx, cart.Quantity, id, cart.ProductID)
 		if err != nil {
 			ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
 
@@ -141,7 +115,7 @@ func (s *HttpServer) updateCart(ctx *gin.Context) {
 		}
 	}
 
-	carts, err := s.repo.cart.ListUserCarts(ctx, payload.UserID)
+	carts, err := s.repo.cart.ListUserCarts(ctx, id)
 	if err != nil {
 		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
 
@@ -155,25 +129,12 @@ func (s *HttpServer) updateCart(ctx *gin.Context) {
 		return
 	}
 
-	rsp.ID = payload.UserID
+	rsp.ID = id
 
 	ctx.JSON(http.StatusOK, rsp)
 }
 
 func (s *HttpServer) listCarts(ctx *gin.Context) {
-	payload, err := getPayload(ctx)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-
-		return
-	}
-
-	if isAdmin, err := isAdmin(payload); !isAdmin {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-
-		return
-	}
-
 	usersCart, err := s.repo.cart.ListCarts(ctx)
 	if err != nil {
 		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
@@ -199,13 +160,6 @@ func (s *HttpServer) listCarts(ctx *gin.Context) {
 }
 
 func (s *HttpServer) getCart(ctx *gin.Context) {
-	payload, err := getPayload(ctx)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-
-		return
-	}
-
 	id, err := getParam(ctx.Param("id"))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
@@ -213,12 +167,6 @@ func (s *HttpServer) getCart(ctx *gin.Context) {
 		return
 	}
 
-	if id != payload.UserID && payload.Role != "ADMIN" {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(pkg.Errorf(pkg.AUTHENTICATION_ERROR, "not enough permission to view users cart")))
-
-		return
-	}
-
 	carts, err := s.repo.cart.ListUserCarts(ctx, id)
 	if err != nil {
 		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
@@ -239,20 +187,14 @@ func (s *HttpServer) getCart(ctx *gin.Context) {
 }
 
 func (s *HttpServer) deleteCart(ctx *gin.Context) {
-	payload, err := getPayload(ctx)
+	id, err := getParam(ctx.Param("id"))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 
 		return
 	}
 
-	if isAdmin, err := isAdmin(payload); !isAdmin {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-
-		return
-	}
-
-	err = s.repo.cart.DeleteCart(ctx, payload.UserID)
+	err = s.repo.cart.DeleteCart(ctx, id)
 	if err != nil {
 		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
 
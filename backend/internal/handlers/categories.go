@@ -15,19 +15,6 @@ type createCategoryRequest struct {
 }
 
 func (s *HttpServer) createCategory(ctx *gin.Context) {
-	payload, err := getPayload(ctx)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-
-		return
-	}
-
-	if isAdmin, err := isAdmin(payload); !isAdmin {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-
-		return
-	}
-
 	var req createCategoryRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
@@ -45,6 +32,8 @@ func (s *HttpServer) createCategory(ctx *gin.Context) {
 		return
 	}
 
+	s.audit(ctx, repository.AuditCreate, repository.AuditEntityCategory, category.ID, nil, category)
+
 	ctx.JSON(http.StatusOK, category)
 }
 
@@ -78,19 +67,6 @@ func (s *HttpServer) getCategory(ctx *gin.Context) {
 }
 
 func (s *HttpServer) updateCategory(ctx *gin.Context) {
-	payload, err := getPayload(ctx)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-
-		return
-	}
-
-	if isAdmin, err := isAdmin(payload); !isAdmin {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-
-		return
-	}
-
 	body, err := ctx.GetRawData()
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
@@ -112,6 +88,13 @@ func (s *HttpServer) updateCategory(ctx *gin.Context) {
 		return
 	}
 
+	before, err := s.repo.cate.GetCategory(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
 	err = s.repo.cate.UpdateCategory(ctx, &repository.Category{
 		ID:          id,
 		Name:        req.Name,
@@ -123,26 +106,29 @@ func (s *HttpServer) updateCategory(ctx *gin.Context) {
 		return
 	}
 
+	after, err := s.repo.cate.GetCategory(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	s.audit(ctx, repository.AuditUpdate, repository.AuditEntityCategory, id, before, after)
+
 	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
 }
 
 func (s *HttpServer) deleteCategory(ctx *gin.Context) {
-	payload, err := getPayload(ctx)
+	id, err := getParam(ctx.Param("id"))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 
 		return
 	}
 
-	if isAdmin, err := isAdmin(payload); !isAdmin {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-
-		return
-	}
-
-	id, err := getParam(ctx.Param("id"))
+	before, err := s.repo.cate.GetCategory(ctx, id)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
 
 		return
 	}
@@ -154,5 +140,7 @@ func (s *HttpServer) deleteCategory(ctx *gin.Context) {
 		return
 	}
 
+	s.audit(ctx, repository.AuditDelete, repository.AuditEntityCategory, id, before, nil)
+
 	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
 }
@@ -0,0 +1,314 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+// commentResponse is the public view of a comment, replies are nested under
+// the comment they answer.
+type commentResponse struct {
+	ID         uint32             `json:"id"`
+	UserID     uint32             `json:"user_id"`
+	AuthorName string             `json:"author_name"`
+	Content    string             `json:"content"`
+	Deleted    bool               `json:"deleted"`
+	Edited     bool               `json:"edited"`
+	CreatedAt  time.Time          `json:"created_at"`
+	Replies    []*commentResponse `json:"replies"`
+}
+
+func newCommentResponse(comment *repository.Comment) *commentResponse {
+	rsp := &commentResponse{
+		ID:         comment.ID,
+		UserID:     comment.UserID,
+		AuthorName: comment.AuthorName,
+		Content:    comment.Content,
+		Deleted:    comment.DeletedAt != nil,
+		Edited:     comment.DeletedAt == nil && comment.UpdatedAt.After(comment.CreatedAt),
+		CreatedAt:  comment.CreatedAt,
+		Replies:    []*commentResponse{},
+	}
+
+	// the author of a deleted comment is not shown either
+	if rsp.Deleted {
+		rsp.UserID = 0
+		rsp.AuthorName = ""
+	}
+
+	return rsp
+}
+
+// commentThreads nests the replies under their parents. Replies to comments
+// that are not shown are dropped, and so are deleted comments nobody replied to.
+func commentThreads(comments []*repository.Comment) []*commentResponse {
+	nodes := make(map[uint32]*commentResponse, len(comments))
+	roots := []*commentResponse{}
+
+	// comments come oldest first so a parent is always seen before its replies
+	for _, comment := range comments {
+		node := newCommentResponse(comment)
+		nodes[comment.ID] = node
+
+		if comment.ParentID == nil {
+			roots = append(roots, node)
+
+			continue
+		}
+
+		if parent, ok := nodes[*comment.ParentID]; ok {
+			parent.Replies = append(parent.Replies, node)
+		}
+	}
+
+	return pruneDeletedComments(roots)
+}
+
+func pruneDeletedComments(nodes []*commentResponse) []*commentResponse {
+	result := []*commentResponse{}
+
+	for _, node := range nodes {
+		node.Replies = pruneDeletedComments(node.Replies)
+
+		if node.Deleted && len(node.Replies) == 0 {
+			continue
+		}
+
+		result = append(result, node)
+	}
+
+	return result
+}
+
+func (s *HttpServer) listBlogComments(ctx *gin.Context) {
+	blogID, err := getParam(ctx.Param("blogId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	comments, err := s.repo.comment.ListBlogComments(ctx, blogID)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, commentThreads(comments))
+}
+
+type createCommentRequest struct {
+	Content  string  `binding:"required,max=2000" json:"content"`
+	ParentID *uint32 `binding:""                  json:"parent_id"` // set to reply to a comment
+}
+
+// createBlogComment responds with the comment and its status, comments the
+// filter flags wait for a moderator before they are shown.
+func (s *HttpServer) createBlogComment(ctx *gin.Context) {
+	payload, err := getPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	blogID, err := getParam(ctx.Param("blogId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var req createCommentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+
+		return
+	}
+
+	comment, err := s.repo.comment.CreateComment(ctx, &repository.Comment{
+		BlogID:   blogID,
+		UserID:   payload.UserID,
+		ParentID: req.ParentID,
+		Content:  strings.TrimSpace(req.Content),
+	})
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, comment)
+}
+
+type updateCommentRequest struct {
+	Content string `binding:"required,max=2000" json:"content"`
+}
+
+func (s *HttpServer) updateBlogComment(ctx *gin.Context) {
+	payload, err := getPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	id, err := s.blogCommentParam(ctx)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	var req updateCommentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+
+		return
+	}
+
+	comment, err := s.repo.comment.UpdateComment(ctx, id, payload.UserID, strings.TrimSpace(req.Content))
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, comment)
+}
+
+func (s *HttpServer) deleteBlogComment(ctx *gin.Context) {
+	payload, err := getPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	id, err := s.blogCommentParam(ctx)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	if err := s.repo.comment.DeleteComment(ctx, id, payload.UserID); err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+// blogCommentParam reads :commentId and makes sure it is a comment on :blogId.
+func (s *HttpServer) blogCommentParam(ctx *gin.Context) (uint32, error) {
+	blogID, err := getParam(ctx.Param("blogId"))
+	if err != nil {
+		return 0, pkg.Errorf(pkg.INVALID_ERROR, "%v", pkg.ErrorMessage(err))
+	}
+
+	id, err := getParam(ctx.Param("commentId"))
+	if err != nil {
+		return 0, pkg.Errorf(pkg.INVALID_ERROR, "%v", pkg.ErrorMessage(err))
+	}
+
+	comment, err := s.repo.comment.GetComment(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	if comment.BlogID != blogID {
+		return 0, pkg.Errorf(pkg.NOT_FOUND_ERROR, "no comment found with id %d", id)
+	}
+
+	return id, nil
+}
+
+func (s *HttpServer) listCommentModerationQueue(ctx *gin.Context) {
+	status := repository.CommentPending
+	if q := ctx.Query("status"); q != "" {
+		status = strings.ToUpper(q)
+	}
+
+	if status != repository.CommentPending && status != repository.CommentApproved && status != repository.CommentHidden {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "status must be %s, %s or %s", repository.CommentPending, repository.CommentApproved, repository.CommentHidden)))
+
+		return
+	}
+
+	limit, offset, err := parsePagination(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	comments, err := s.repo.comment.ListCommentsByStatus(ctx, status, limit, offset)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, comments)
+}
+
+func (s *HttpServer) approveComment(ctx *gin.Context) {
+	s.moderateComment(ctx, repository.CommentApproved, "")
+}
+
+type hideCommentRequest struct {
+	Reason string `binding:"required,max=255" json:"reason"`
+}
+
+func (s *HttpServer) hideComment(ctx *gin.Context) {
+	var req hideCommentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+
+		return
+	}
+
+	s.moderateComment(ctx, repository.CommentHidden, req.Reason)
+}
+
+func (s *HttpServer) moderateComment(ctx *gin.Context, status string, reason string) {
+	payload, err := getPayload(ctx)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	id, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	before, err := s.repo.comment.GetComment(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	comment, err := s.repo.comment.ModerateComment(ctx, id, payload.UserID, status, reason)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	s.audit(ctx, repository.AuditUpdate, repository.AuditEntityComment, id, before, comment)
+
+	ctx.JSON(http.StatusOK, comment)
+}
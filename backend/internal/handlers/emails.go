@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/services"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+)
+
+// queueEmail renders the template into the outbox, the outbox worker sends it.
+// Failures are only logged, an email never fails the request that caused it.
+func (s *HttpServer) queueEmail(ctx context.Context, to string, template string, data any) {
+	email, err := s.templates.Render(to, template, data)
+	if err != nil {
+		log.Printf("failed to render %s email: %v", template, err)
+
+		return
+	}
+
+	if err := s.repo.outbox.EnqueueEmail(ctx, &repository.OutboxEmail{
+		To:       email.To,
+		Subject:  email.Subject,
+		Template: template,
+		Text:     email.Text,
+		HTML:     email.HTML,
+		Headers:  email.Headers,
+	}); err != nil {
+		log.Printf("failed to queue %s email: %v", template, err)
+	}
+}
+
+// queueOrderEmail sends one of the order emails to the customer.
+func (s *HttpServer) queueOrderEmail(ctx context.Context, order *repository.Order, template string) {
+	email, err := s.repo.u.GetUserEmail(ctx, order.UserID)
+	if err != nil {
+		log.Printf("failed to get email for %s email of order %d: %v", template, order.ID, err)
+
+		return
+	}
+
+	rsp, err := s.structureOrderResponse(ctx, order)
+	if err != nil {
+		log.Printf("failed to get items for %s email of order %d: %v", template, order.ID, err)
+
+		return
+	}
+
+	data := services.OrderEmailData{
+		OrderID:         order.ID,
+		Amount:          order.Amount,
+		ShippingAmount:  order.ShippingAmount,
+		ShippingAddress: order.ShippingAddress,
+		Link:            pkg.JoinURL(s.config.PUBLIC_BASE_URL, fmt.Sprintf("/orders/%d", order.ID)),
+	}
+
+	for _, item := range rsp.Data {
+		emailItem := services.OrderEmailItem{
+			Name:     item.ProductName,
+			Quantity: item.Quantity,
+			Price:    item.Price,
+		}
+
+		if item.Color != nil {
+			emailItem.Color = *item.Color
+		}
+
+		if item.Size != nil {
+			emailItem.Size = *item.Size
+		}
+
+		data.Items = append(data.Items, emailItem)
+	}
+
+	s.queueEmail(ctx, email, template, data)
+}
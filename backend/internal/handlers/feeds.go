@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+// entries in a feed, readers only poll for what is new
+const feedLimit = 20
+
+const (
+	feedRSS  = "rss"
+	feedAtom = "atom"
+)
+
+func (s *HttpServer) blogsRSSFeed(ctx *gin.Context) {
+	s.blogFeed(ctx, "", feedRSS)
+}
+
+func (s *HttpServer) blogsAtomFeed(ctx *gin.Context) {
+	s.blogFeed(ctx, "", feedAtom)
+}
+
+func (s *HttpServer) blogTagRSSFeed(ctx *gin.Context) {
+	s.blogFeed(ctx, ctx.Param("tag"), feedRSS)
+}
+
+func (s *HttpServer) blogTagAtomFeed(ctx *gin.Context) {
+	s.blogFeed(ctx, ctx.Param("tag"), feedAtom)
+}
+
+func (s *HttpServer) newProductsRSSFeed(ctx *gin.Context) {
+	s.newProductsFeed(ctx, feedRSS)
+}
+
+func (s *HttpServer) newProductsAtomFeed(ctx *gin.Context) {
+	s.newProductsFeed(ctx, feedAtom)
+}
+
+// blogFeed serves the latest published blogs, only the ones with tag when it is set.
+func (s *HttpServer) blogFeed(ctx *gin.Context, tag string, format string) {
+	filter := repository.BlogFilter{
+		Limit: feedLimit,
+	}
+
+	feed := &pkg.Feed{
+		Title:       s.config.FEED_TITLE + " Blog",
+		Description: "Latest posts from the " + s.config.FEED_TITLE + " blog",
+		Link:        pkg.JoinURL(s.config.PUBLIC_BASE_URL, "/blogs"),
+	}
+
+	if tag != "" {
+		filter.Tag = pkg.StringPtr(tag)
+
+		feed.Title = fmt.Sprintf("%s Blog: %s", s.config.FEED_TITLE, tag)
+		feed.Description = fmt.Sprintf("Latest posts tagged %s from the %s blog", tag, s.config.FEED_TITLE)
+		feed.Link += "?tag=" + url.QueryEscape(tag)
+	}
+
+	blogs, err := s.repo.b.SearchBlogs(ctx, filter)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	authors := make(map[uint32]string)
+
+	for _, blog := range blogs {
+		if _, ok := authors[blog.Author]; !ok {
+			author, err := s.repo.u.GetUserById(ctx, blog.Author)
+			if err != nil {
+				ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+				return
+			}
+
+			authors[blog.Author] = author.FullName
+		}
+
+		link := pkg.JoinURL(s.config.PUBLIC_BASE_URL, "/blogs/"+url.PathEscape(blog.Slug))
+
+		published := blog.CreatedAt
+		if blog.PublishAt != nil {
+			published = *blog.PublishAt
+		}
+
+		// a scheduled blog goes live after its last edit, readers should see it as new
+		updated := blog.UpdatedAt
+		if published.After(updated) {
+			updated = published
+		}
+
+		feed.Items = append(feed.Items, pkg.FeedItem{
+			Title:       blog.Title,
+			Link:        link,
+			Author:      authors[blog.Author],
+			Summary:     blog.Excerpt,
+			ContentHTML: pkg.AbsoluteHTML(blog.ContentHTML, link),
+			Categories:  blog.Tags,
+			Published:   published,
+			Updated:     updated,
+		})
+	}
+
+	s.serveFeed(ctx, feed, format)
+}
+
+// newProductsFeed serves the products added in the last week, newest first.
+func (s *HttpServer) newProductsFeed(ctx *gin.Context, format string) {
+	products, err := s.repo.p.ListNewProducts(ctx)
+	if err != nil && pkg.ErrorCode(err) != pkg.NOT_FOUND_ERROR {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	sort.SliceStable(products, func(i, j int) bool {
+		return products[i].CreatedAt.After(products[j].CreatedAt)
+	})
+
+	if len(products) > feedLimit {
+		products = products[:feedLimit]
+	}
+
+	feed := &pkg.Feed{
+		Title:       s.config.FEED_TITLE + " New Arrivals",
+		Description: "Products added to " + s.config.FEED_TITLE + " this week",
+		Link:        pkg.JoinURL(s.config.PUBLIC_BASE_URL, "/products?type=new"),
+	}
+
+	for _, product := range products {
+		link := pkg.JoinURL(s.config.PUBLIC_BASE_URL, fmt.Sprintf("/products/%d", product.ID))
+
+		feed.Items = append(feed.Items, pkg.FeedItem{
+			Title:       product.Name,
+			Link:        link,
+			Summary:     product.Description,
+			ContentHTML: productFeedHTML(product, link),
+			Published:   product.CreatedAt,
+			Updated:     product.UpdatedAt,
+		})
+	}
+
+	s.serveFeed(ctx, feed, format)
+}
+
+// productFeedHTML shows the first photo, the description and the price.
+func productFeedHTML(product *repository.Product, link string) string {
+	var b strings.Builder
+
+	if _, _, imgUrls, err := product.UnmarshalOptions(); err == nil && len(imgUrls) > 0 {
+		fmt.Fprintf(&b, `<p><img src="%s" alt="%s"></p>`, html.EscapeString(pkg.AbsoluteURL(link, imgUrls[0])), html.EscapeString(product.Name))
+	}
+
+	fmt.Fprintf(&b, "<p>%s</p>", html.EscapeString(product.Description))
+
+	price := product.RegularPrice
+	if product.DiscountedPrice > 0 && product.DiscountedPrice < price {
+		price = product.DiscountedPrice
+	}
+
+	fmt.Fprintf(&b, "<p>Price: %.2f</p>", price)
+
+	return b.String()
+}
+
+// serveFeed writes the feed with an ETag over its bytes and a Last-Modified
+// from its newest entry, and answers 304 when the reader already has it.
+func (s *HttpServer) serveFeed(ctx *gin.Context, feed *pkg.Feed, format string) {
+	feed.SelfLink = pkg.JoinURL(s.config.PUBLIC_BASE_URL, ctx.Request.URL.Path)
+
+	var (
+		data        []byte
+		contentType string
+		err         error
+	)
+
+	switch format {
+	case feedAtom:
+		data, err = feed.Atom()
+		contentType = "application/atom+xml; charset=utf-8"
+	default:
+		data, err = feed.RSS()
+		contentType = "application/rss+xml; charset=utf-8"
+	}
+
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	sum := sha256.Sum256(data)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	modified := feed.Updated().Truncate(time.Second)
+
+	ctx.Header("ETag", etag)
+	ctx.Header("Last-Modified", modified.Format(http.TimeFormat))
+	ctx.Header("Cache-Control", "public, max-age=300")
+
+	if feedNotModified(ctx.Request, etag, modified) {
+		ctx.Status(http.StatusNotModified)
+
+		return
+	}
+
+	ctx.Data(http.StatusOK, contentType, data)
+}
+
+// feedNotModified follows RFC 9110, If-Modified-Since is ignored when the
+// request also sends If-None-Match.
+func feedNotModified(r *http.Request, etag string, modified time.Time) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	return !modified.After(since)
+}
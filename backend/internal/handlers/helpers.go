@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+// page size of the list endpoints when ?limit= is not given, and the most they return
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+// parsePagination reads the ?limit= and ?offset= query params of a list endpoint.
+func parsePagination(ctx *gin.Context) (limit int32, offset int32, err error) {
+	limit = defaultPageLimit
+
+	if q := ctx.Query("limit"); q != "" {
+		n, err := strconv.Atoi(q)
+		if err != nil || n <= 0 || n > maxPageLimit {
+			return 0, 0, pkg.Errorf(pkg.INVALID_ERROR, "limit must be between 1 and %d", maxPageLimit)
+		}
+
+		limit = int32(n)
+	}
+
+	if q := ctx.Query("offset"); q != "" {
+		n, err := strconv.Atoi(q)
+		if err != nil || n < 0 || n > math.MaxInt32 {
+			return 0, 0, pkg.Errorf(pkg.INVALID_ERROR, "offset must be between 0 and %d", math.MaxInt32)
+		}
+
+		offset = int32(n)
+	}
+
+	return limit, offset, nil
+}
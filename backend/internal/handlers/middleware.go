@@ -10,6 +10,7 @@ import (
 
 	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -19,10 +20,24 @@ const (
 	authorizationHeaderKey        = "Authorization"
 	authorizationHeaderBearerType = "bearer"
 	authorizationPayloadKey       = "payload"
+	apiKeyHeaderKey               = "X-API-Key"
+	apiKeyKey                     = "api_key"
+	requestIDHeaderKey            = "X-Request-ID"
+	requestIDKey                  = "request_id"
 )
 
-func authMiddleware(maker pkg.Maker) gin.HandlerFunc {
+// a client supplied request id longer than this is replaced with a fresh one
+const maxRequestIDLength = 64
+
+// authMiddleware accepts either a bearer token or an X-API-Key header.
+func (s *HttpServer) authMiddleware() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
+		if key := ctx.GetHeader(apiKeyHeaderKey); key != "" {
+			s.authenticateAPIKey(ctx, key)
+
+			return
+		}
+
 		authHeader := ctx.GetHeader(authorizationHeaderKey)
 		if authHeader == "" {
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(pkg.Errorf(pkg.AUTHENTICATION_ERROR, "No header was passed")))
@@ -46,19 +61,207 @@ func authMiddleware(maker pkg.Maker) gin.HandlerFunc {
 
 		token := fields[1]
 
-		payload, err := maker.VerifyToken(token)
+		// refresh tokens are only good at the refresh-token route
+		payload, err := s.tokenMaker.VerifyToken(token)
+		if err != nil || payload.Type != pkg.TokenTypeAccess || payload.Role == twoFactorChallengeRole {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(pkg.Errorf(pkg.AUTHENTICATION_ERROR, "Access Token Not Valid")))
+
+			return
+		}
+
+		// a token outlives disabling, deleting or forcing a reset on its
+		// account, so the account is checked on every request
+		user, err := s.repo.u.GetUserById(ctx, payload.UserID)
 		if err != nil {
+			if pkg.ErrorCode(err) == pkg.NOT_FOUND_ERROR {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(pkg.Errorf(pkg.AUTHENTICATION_ERROR, "Access Token Not Valid")))
+
+				return
+			}
+
+			ctx.AbortWithStatusJSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+			return
+		}
+
+		if user.DeletedAt != nil || user.TokenRevoked(payload.CreatedAt) {
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(pkg.Errorf(pkg.AUTHENTICATION_ERROR, "Access Token Not Valid")))
 
 			return
 		}
 
+		if err := checkAccountActive(user); err != nil {
+			ctx.AbortWithStatusJSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+			return
+		}
+
+		// permissions follow the role the account has now, not the one in the
+		// token, so a role change applies to tokens already handed out
+		payload.Role, err = s.currentRole(ctx, user)
+		if err != nil {
+			ctx.AbortWithStatusJSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+			return
+		}
+
 		ctx.Set(authorizationPayloadKey, payload)
 
 		ctx.Next()
 	}
 }
 
+// authenticateAPIKey lets an integration in with an api key. The request acts
+// for the admin that created the key, but only with the scopes of the key and
+// never on the owner only routes, see hasPermission and requireOwner.
+func (s *HttpServer) authenticateAPIKey(ctx *gin.Context, rawKey string) {
+	key, owner, err := s.repo.apiKey.AuthenticateAPIKey(ctx, pkg.HashToken(rawKey))
+	if err != nil {
+		if pkg.ErrorCode(err) == pkg.NOT_FOUND_ERROR {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(pkg.Errorf(pkg.AUTHENTICATION_ERROR, "API Key Not Valid")))
+
+			return
+		}
+
+		ctx.AbortWithStatusJSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ownerRole, err := s.currentRole(ctx, owner)
+	if err != nil {
+		ctx.AbortWithStatusJSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	if err := s.repo.apiKey.TouchAPIKey(ctx, key.ID, apiKeyTouchInterval); err != nil {
+		log.Error().Err(err).Uint32("api_key_id", key.ID).Msg("failed to record api key use")
+	}
+
+	payload := &pkg.Payload{
+		UserID: owner.ID,
+		Email:  owner.Email,
+		Role:   apiKeyRole,
+	}
+
+	if key.ExpiresAt != nil {
+		payload.ExpiryAt = *key.ExpiresAt
+	}
+
+	ctx.Set(authorizationPayloadKey, payload)
+	ctx.Set(apiKeyKey, &apiKeyCaller{
+		key:       key,
+		ownerRole: ownerRole,
+	})
+
+	ctx.Next()
+}
+
+// denyAPIKeys keeps api keys off routes that have no permission to scope them.
+func denyAPIKeys() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.GetHeader(apiKeyHeaderKey) != "" {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(pkg.Errorf(pkg.FORBIDDEN_ERROR, "api keys cannot be used here")))
+
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// requirePermission allows the request only when the callers role holds every
+// one of the given permissions. It must run after authMiddleware.
+func (s *HttpServer) requirePermission(permissions ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		payload, err := getPayload(ctx)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(pkg.Errorf(pkg.AUTHENTICATION_ERROR, "%v", pkg.ErrorMessage(err))))
+
+			return
+		}
+
+		for _, permission := range permissions {
+			allowed, err := s.hasPermission(ctx, payload, permission)
+			if err != nil {
+				ctx.AbortWithStatusJSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+				return
+			}
+
+			if !allowed {
+				ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(pkg.Errorf(pkg.FORBIDDEN_ERROR, "missing permission: %s", permission)))
+
+				return
+			}
+		}
+
+		ctx.Next()
+	}
+}
+
+// requireOwner guards the /users/:id routes. The request is allowed when :id is
+// the callers own user id, or when their role holds any of the given permissions.
+// With no permissions only the owner gets through. It must run after authMiddleware.
+func (s *HttpServer) requireOwner(permissions ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		payload, err := getPayload(ctx)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(pkg.Errorf(pkg.AUTHENTICATION_ERROR, "%v", pkg.ErrorMessage(err))))
+
+			return
+		}
+
+		id, err := getParam(ctx.Param("id"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(err))
+
+			return
+		}
+
+		if id == payload.UserID && payload.Role != apiKeyRole {
+			ctx.Next()
+
+			return
+		}
+
+		for _, permission := range permissions {
+			allowed, err := s.hasPermission(ctx, payload, permission)
+			if err != nil {
+				ctx.AbortWithStatusJSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+				return
+			}
+
+			if allowed {
+				ctx.Next()
+
+				return
+			}
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(pkg.Errorf(pkg.FORBIDDEN_ERROR, "cannot access another users resources")))
+	}
+}
+
+// requestIDMiddleware tags every request with an id so audit entries written by
+// the same call can be found together. A valid X-Request-ID from the client is
+// kept, otherwise one is generated. The id is echoed in the response.
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := strings.TrimSpace(ctx.GetHeader(requestIDHeaderKey))
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = uuid.NewString()
+		}
+
+		ctx.Set(requestIDKey, requestID)
+		ctx.Header(requestIDHeaderKey, requestID)
+
+		ctx.Next()
+	}
+}
+
 func loggerMiddleware() gin.HandlerFunc {
 	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stdout})
 
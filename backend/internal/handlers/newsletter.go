@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/services"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+type createCampaignRequest struct {
+	Subject string `binding:"required,max=255" json:"subject"`
+	Content string `binding:"required"         json:"content"` // markdown
+}
+
+// createCampaign queues the campaign for every subscribed user and confirmed subscriber, the
+// newsletter worker sends it in the background.
+func (s *HttpServer) createCampaign(ctx *gin.Context) {
+	payload, err := getPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var req createCampaignRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+
+		return
+	}
+
+	campaign, err := s.repo.newsletter.CreateCampaign(ctx, &repository.Campaign{
+		Subject:   strings.TrimSpace(req.Subject),
+		Content:   req.Content,
+		CreatedBy: &payload.UserID,
+	})
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	s.audit(ctx, repository.AuditCreate, repository.AuditEntityCampaign, campaign.ID, nil, campaign)
+
+	ctx.JSON(http.StatusOK, campaign)
+}
+
+func (s *HttpServer) listCampaigns(ctx *gin.Context) {
+	limit, offset, err := parsePagination(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	campaigns, err := s.repo.newsletter.ListCampaigns(ctx, limit, offset)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, campaigns)
+}
+
+func (s *HttpServer) getCampaign(ctx *gin.Context) {
+	id, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	campaign, err := s.repo.newsletter.GetCampaign(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, campaign)
+}
+
+func (s *HttpServer) getCampaignStats(ctx *gin.Context) {
+	id, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	stats, err := s.repo.newsletter.GetCampaignStats(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, stats)
+}
+
+// listCampaignDeliveries shows the send status of each recipient, ?status=
+// narrows it down to PENDING, SENT, FAILED or SKIPPED.
+func (s *HttpServer) listCampaignDeliveries(ctx *gin.Context) {
+	id, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	status := strings.ToUpper(ctx.Query("status"))
+	if status != "" && status != repository.DeliveryPending && status != repository.DeliverySent && status != repository.DeliveryFailed && status != repository.DeliverySkipped {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "status must be %s, %s, %s or %s", repository.DeliveryPending, repository.DeliverySent, repository.DeliveryFailed, repository.DeliverySkipped)))
+
+		return
+	}
+
+	limit, offset, err := parsePagination(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	deliveries, err := s.repo.newsletter.ListCampaignDeliveries(ctx, id, status, limit, offset)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, deliveries)
+}
+
+type subscribeNewsletterRequest struct {
+	Email string `binding:"required,email,max=255" json:"email"`
+}
+
+// subscribeNewsletter lets visitors sign up with just an email, the
+// subscription starts once the link in the confirmation email is opened. The
+// response is the same whether or not the email was subscribed already.
+func (s *HttpServer) subscribeNewsletter(ctx *gin.Context) {
+	var req subscribeNewsletterRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+
+		return
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+
+	token, err := pkg.RandomURLToken(32)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "%v", err)))
+
+		return
+	}
+
+	pending, err := s.repo.newsletter.Subscribe(ctx, email, pkg.HashToken(token), time.Now().Add(s.config.NEWSLETTER_CONFIRM_DURATION))
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	if pending {
+		s.queueEmail(ctx, email, services.EmailVerification, services.VerificationEmailData{
+			Link:      pkg.JoinURL(s.config.PUBLIC_BASE_URL, "/api/v1/newsletter/confirm") + "?token=" + url.QueryEscape(token),
+			Reason:    "to receive the " + s.config.FEED_TITLE + " newsletter",
+			ExpiresIn: s.config.NEWSLETTER_CONFIRM_DURATION,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "confirmation_sent"})
+}
+
+// confirmNewsletterSubscription is the link in the confirmation email.
+func (s *HttpServer) confirmNewsletterSubscription(ctx *gin.Context) {
+	token := ctx.Query("token")
+	if token == "" {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "token is required")))
+
+		return
+	}
+
+	if _, err := s.repo.newsletter.ConfirmSubscriber(ctx, pkg.HashToken(token)); err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "subscribed"})
+}
+
+// unsubscribeNewsletter is the one click link in every newsletter, the signed
+// token stands in for signing in. GET serves the link, POST mail clients.
+func (s *HttpServer) unsubscribeNewsletter(ctx *gin.Context) {
+	kind, id, err := pkg.ParseUnsubscribeToken(s.config.NEWSLETTER_SIGNING_KEY, ctx.Query("token"))
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	if kind == pkg.UnsubscribeSubscriber {
+		err = s.repo.newsletter.UnsubscribeSubscriber(ctx, id)
+	} else {
+		err = s.repo.u.UpdateUserSubscriptionStatus(ctx, id, false)
+	}
+
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "unsubscribed"})
+}
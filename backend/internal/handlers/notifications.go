@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *HttpServer) listUserNotifications(ctx *gin.Context) {
+	id, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	filter := repository.NotificationFilter{
+		UserID: id,
+		Unread: ctx.Query("unread") == "true",
+	}
+
+	limit, offset, err := parsePagination(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	filter.Limit = limit
+	filter.Offset = offset
+
+	notifications, err := s.repo.notif.ListUserNotifications(ctx, filter)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, notifications)
+}
+
+func (s *HttpServer) markNotificationRead(ctx *gin.Context) {
+	userId, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	id, err := getParam(ctx.Param("notificationId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	if err := s.repo.notif.MarkNotificationRead(ctx, userId, id); err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+func (s *HttpServer) markAllNotificationsRead(ctx *gin.Context) {
+	userId, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	if err := s.repo.notif.MarkAllNotificationsRead(ctx, userId); err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+type updateNotificationPreferencesRequest struct {
+	OrderEmail *bool `json:"order_email"`
+	OrderSMS   *bool `json:"order_sms"`
+}
+
+func (s *HttpServer) getNotificationPreferences(ctx *gin.Context) {
+	id, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	preferences, err := s.repo.notif.GetNotificationPreferences(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, preferences)
+}
+
+func (s *HttpServer) updateNotificationPreferences(ctx *gin.Context) {
+	id, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var req updateNotificationPreferencesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+
+		return
+	}
+
+	preferences, err := s.repo.notif.GetNotificationPreferences(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	if req.OrderEmail != nil {
+		preferences.OrderEmail = *req.OrderEmail
+	}
+
+	if req.OrderSMS != nil {
+		preferences.OrderSMS = *req.OrderSMS
+	}
+
+	if req.OrderSMS != nil && *req.OrderSMS {
+		user, err := s.repo.u.GetUserById(ctx, id)
+		if err != nil {
+			ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+			return
+		}
+
+		if user.PhoneNumber == "" {
+			err := pkg.Errorf(pkg.INVALID_ERROR, "add a phone number to your profile to get sms notifications")
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+			return
+		}
+	}
+
+	if err := s.repo.notif.UpdateNotificationPreferences(ctx, preferences); err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, preferences)
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+type oidcAuthorizeResponse struct {
+	AuthURL      string `json:"auth_url"`
+	State        string `json:"state"`
+	ExpiresAfter int64  `json:"expires_after"`
+}
+
+// oidcAuthorize starts a login with the OpenID Connect provider. The client
+// redirects the browser to auth_url and the provider sends it back to
+// OIDC_REDIRECT_URL with a code and the state, which are posted to oidcCallback.
+func (s *HttpServer) oidcAuthorize(ctx *gin.Context) {
+	if s.oidc == nil {
+		ctx.JSON(http.StatusNotImplemented, errorResponse(pkg.Errorf(pkg.NOT_IMPLEMENTED_ERROR, "%v", pkg.ErrOIDCNotConfigured)))
+
+		return
+	}
+
+	state, err := pkg.RandomURLToken(32)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "%v", err)))
+
+		return
+	}
+
+	nonce, err := pkg.RandomURLToken(32)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "%v", err)))
+
+		return
+	}
+
+	verifier, err := pkg.RandomURLToken(48)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "%v", err)))
+
+		return
+	}
+
+	err = s.repo.oauth.CreateOAuthState(ctx, &repository.OAuthState{
+		State:        state,
+		Provider:     s.oidc.Name,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(s.config.OIDC_STATE_DURATION),
+	})
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, oidcAuthorizeResponse{
+		AuthURL:      s.oidc.AuthCodeURL(state, nonce, verifier),
+		State:        state,
+		ExpiresAfter: int64(s.config.OIDC_STATE_DURATION.Seconds()),
+	})
+}
+
+type oidcCallbackRequest struct {
+	Code  string `binding:"required" json:"code"`
+	State string `binding:"required" json:"state"`
+}
+
+func (s *HttpServer) oidcCallback(ctx *gin.Context) {
+	if s.oidc == nil {
+		ctx.JSON(http.StatusNotImplemented, errorResponse(pkg.Errorf(pkg.NOT_IMPLEMENTED_ERROR, "%v", pkg.ErrOIDCNotConfigured)))
+
+		return
+	}
+
+	var req oidcCallbackRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+
+		return
+	}
+
+	state, err := s.repo.oauth.ConsumeOAuthState(ctx, req.State)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	if state.Provider != s.oidc.Name {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(pkg.Errorf(pkg.AUTHENTICATION_ERROR, "login state belongs to another provider")))
+
+		return
+	}
+
+	idToken, err := s.oidc.Exchange(ctx, req.Code, state.CodeVerifier)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(pkg.Errorf(pkg.AUTHENTICATION_ERROR, "%v", err)))
+
+		return
+	}
+
+	claims, err := s.oidc.VerifyIDToken(ctx, idToken, state.Nonce)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(pkg.Errorf(pkg.AUTHENTICATION_ERROR, "%v", err)))
+
+		return
+	}
+
+	user, err := s.oidcUser(ctx, claims)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	s.completeLogin(ctx, user)
+}
+
+// oidcUser finds the user behind the provider identity. Unknown identities are
+// linked to the user with the same email, or to a new user, but only when the
+// provider has verified that email.
+func (s *HttpServer) oidcUser(ctx *gin.Context, claims *pkg.IDTokenClaims) (*repository.User, error) {
+	identity, err := s.repo.oauth.GetUserIdentity(ctx, s.oidc.Name, claims.Subject)
+	if err == nil {
+		return s.repo.u.GetUserById(ctx, identity.UserID)
+	}
+
+	if pkg.ErrorCode(err) != pkg.NOT_FOUND_ERROR {
+		return nil, err
+	}
+
+	email := strings.ToLower(strings.TrimSpace(claims.Email))
+	if email == "" || !claims.EmailVerified {
+		return nil, pkg.Errorf(pkg.AUTHENTICATION_ERROR, "%s did not return a verified email", s.oidc.Name)
+	}
+
+	user, err := s.repo.u.GetUserByEmail(ctx, email)
+	if err != nil {
+		if pkg.ErrorCode(err) != pkg.NOT_FOUND_ERROR {
+			return nil, err
+		}
+
+		// the account can only be used through the provider until a password is reset
+		password, err := pkg.RandomURLToken(32)
+		if err != nil {
+			return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "%v", err)
+		}
+
+		user, err = s.repo.u.CreateUser(ctx, &repository.User{
+			Email:    email,
+			Password: password,
+			Role:     "USER",
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.repo.oauth.CreateUserIdentity(ctx, &repository.UserIdentity{
+		Provider: s.oidc.Name,
+		Subject:  claims.Subject,
+		UserID:   user.ID,
+		Email:    email,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logSecurityEvent(ctx, &repository.SecurityEvent{
+		Event:   repository.EventIdentityLinked,
+		UserID:  &user.ID,
+		Email:   email,
+		Details: fmt.Sprintf("%s identity %s linked", s.oidc.Name, claims.Subject),
+	})
+
+	return s.repo.u.GetUserById(ctx, user.ID)
+}
+
+func (s *HttpServer) listUserIdentities(ctx *gin.Context) {
+	id, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	identities, err := s.repo.oauth.ListUserIdentities(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, identities)
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/events"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/services"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+)
+
+const smsTimeout = 30 * time.Second
+
+// orderNotification is what the customer is told about an order event.
+type orderNotification struct {
+	template string
+	sms      string
+}
+
+func (s *HttpServer) orderNotification(event events.Event) (orderNotification, bool) {
+	link := pkg.JoinURL(s.config.PUBLIC_BASE_URL, fmt.Sprintf("/orders/%d", event.OrderID))
+
+	switch event.Type {
+	case events.OrderCreated:
+		return orderNotification{
+			template: services.EmailOrderConfirmation,
+			sms:      fmt.Sprintf("%s: we received your order #%d of %.2f. %s", s.config.FEED_TITLE, event.OrderID, event.Amount, link),
+		}, true
+	case events.OrderPaid:
+		return orderNotification{
+			template: services.EmailOrderPaid,
+			sms:      fmt.Sprintf("%s: payment of %.2f for order #%d received, thank you. %s", s.config.FEED_TITLE, event.Amount, event.OrderID, link),
+		}, true
+	case events.OrderStatusChanged:
+		switch event.Status {
+		case "PROCESSING":
+			return orderNotification{
+				template: services.EmailOrderProcessing,
+				sms:      fmt.Sprintf("%s: we are preparing your order #%d. %s", s.config.FEED_TITLE, event.OrderID, link),
+			}, true
+		case "SHIPPED":
+			return orderNotification{
+				template: services.EmailOrderShipped,
+				sms:      fmt.Sprintf("%s: your order #%d has shipped. %s", s.config.FEED_TITLE, event.OrderID, link),
+			}, true
+		case "DELIVERED":
+			return orderNotification{
+				template: services.EmailOrderDelivered,
+				sms:      fmt.Sprintf("%s: your order #%d has been delivered. %s", s.config.FEED_TITLE, event.OrderID, link),
+			}, true
+		}
+	}
+
+	return orderNotification{}, false
+}
+
+// notifyOrderEvent tells the customer about an order event on the channels they
+// picked. Emails go through the outbox, sms are sent once in the background and
+// failures are only logged.
+func (s *HttpServer) notifyOrderEvent(ctx context.Context, event events.Event) {
+	notification, ok := s.orderNotification(event)
+	if !ok {
+		return
+	}
+
+	preferences, err := s.repo.notif.GetNotificationPreferences(ctx, event.UserID)
+	if err != nil {
+		log.Printf("failed to get notification preferences of user %d: %v", event.UserID, err)
+
+		return
+	}
+
+	if preferences.OrderEmail {
+		order, err := s.repo.o.GetOrder(ctx, event.OrderID)
+		if err != nil {
+			log.Printf("failed to get order %d for %s notification: %v", event.OrderID, event.Type, err)
+		} else {
+			s.queueOrderEmail(ctx, order, notification.template)
+		}
+	}
+
+	if !preferences.OrderSMS {
+		return
+	}
+
+	user, err := s.repo.u.GetUserById(ctx, event.UserID)
+	if err != nil {
+		log.Printf("failed to get user %d for %s sms: %v", event.UserID, event.Type, err)
+
+		return
+	}
+
+	if user.PhoneNumber == "" {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), smsTimeout)
+		defer cancel()
+
+		if err := s.sms.SendSMS(ctx, user.PhoneNumber, notification.sms); err != nil {
+			log.Printf("failed to send %s sms for order %d: %v", event.Type, event.OrderID, err)
+		}
+	}()
+}
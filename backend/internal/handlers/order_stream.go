@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/events"
+	"github.com/gin-gonic/gin"
+)
+
+// a comment is sent this often so proxies do not close an idle stream
+const sseHeartbeatInterval = 25 * time.Second
+
+// publishOrderEvent hands an order event to the SSE streams of every replica.
+func (s *HttpServer) publishOrderEvent(ctx context.Context, event events.Event) {
+	if err := s.stream.Publish(ctx, event); err != nil {
+		log.Printf("failed to publish %s event of order %d: %v", event.Type, event.OrderID, err)
+	}
+}
+
+// streamUserOrderEvents streams status changes of the user's orders, or of a
+// single order with ?order_id=.
+func (s *HttpServer) streamUserOrderEvents(ctx *gin.Context) {
+	userId, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var orderId uint32
+	if id := ctx.Query("order_id"); id != "" {
+		orderId, err = getParam(id)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+			return
+		}
+	}
+
+	s.streamEvents(ctx, func(event events.Event) bool {
+		return event.Type == events.OrderStatusChanged &&
+			event.UserID == userId &&
+			(orderId == 0 || event.OrderID == orderId)
+	})
+}
+
+// streamOrderEvents streams new orders and payments for the admin dashboard.
+func (s *HttpServer) streamOrderEvents(ctx *gin.Context) {
+	s.streamEvents(ctx, func(event events.Event) bool {
+		return event.Type == events.OrderCreated || event.Type == events.OrderPaid
+	})
+}
+
+// streamEvents writes the events that pass keep as server sent events until the
+// client goes away or the server shuts down.
+func (s *HttpServer) streamEvents(ctx *gin.Context, keep func(events.Event) bool) {
+	sub := s.stream.Subscribe()
+	defer sub.Close()
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+
+	// open the stream straight away instead of on the first event
+	ctx.Status(http.StatusOK)
+	fmt.Fprint(ctx.Writer, ": connected\n\n")
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Request.Context().Done():
+			return false
+		case event, ok := <-sub.Events:
+			if !ok {
+				return false
+			}
+
+			if keep(event) {
+				ctx.SSEvent(event.Type, event)
+			}
+
+			return true
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+
+			return true
+		}
+	})
+}
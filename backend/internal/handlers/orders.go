@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/events"
 	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
 	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
 	"github.com/gin-gonic/gin"
@@ -21,6 +22,7 @@ type orderResponse struct {
 	Amount          float64             `json:"amount"`
 	ShippingAmount  float64             `json:"shipping_amount"`
 	ShippingAddress string              `json:"shipping_address"`
+	AddressID       *uint32             `json:"address_id"`
 	Status          string              `json:"status"`
 	Data            []orderItemResponse `json:"data"`
 	UpdatedBy       uint32              `json:"updated_by"`
@@ -38,9 +40,12 @@ type orderItemResponse struct {
 	Size               *string `json:"size"`
 }
 
+// createOrderRequest ships to address_id, or to shipping_address when it is
+// given as free text, falling back to the users default address.
 type createOrderRequest struct {
 	Amount          float64             `binding:"required"                    json:"amount"`
-	ShippingAddress string              `binding:"required"                    json:"shipping_address"`
+	AddressID       *uint32             `                                      json:"address_id"`
+	ShippingAddress string              `                                      json:"shipping_address"`
 	ShippingAmount  float64             `binding:"required"                    json:"shipping_amount"`
 	OrderItems      []orderItemsRequest `binding:"required"                    json:"order_items"`
 	PaymentMethod   string              `binding:"required,oneof=MPESA STRIPE" json:"payment_method"`
@@ -71,11 +76,6 @@ func (s *HttpServer) createOrder(ctx *gin.Context) {
 		return
 	}
 
-	if userId != payload.UserID {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(pkg.Errorf(pkg.AUTHENTICATION_ERROR, "cannot create another user order")))
-
-		return
-	}
 	// create order
 	var req createOrderRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -85,13 +85,26 @@ func (s *HttpServer) createOrder(ctx *gin.Context) {
 	}
 
 	order := &repository.Order{
-		UserID:          payload.UserID,
+		UserID:          userId,
 		Amount:          req.Amount,
 		ShippingAmount:  req.ShippingAmount,
 		ShippingAddress: req.ShippingAddress,
 		UpdatedBy:       payload.UserID,
 	}
 
+	if req.AddressID != nil || req.ShippingAddress == "" {
+		address, err := s.orderAddress(ctx, userId, req.AddressID)
+		if err != nil {
+			ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+			return
+		}
+
+		// snapshot the address so editing the address book later does not move the order
+		order.AddressID = &address.ID
+		order.ShippingAddress = address.Format()
+	}
+
 	orderItems := []*repository.OrderItem{}
 	for _, orderItem := range req.OrderItems {
 		orderItems = append(orderItems, &repository.OrderItem{
@@ -110,14 +123,39 @@ func (s *HttpServer) createOrder(ctx *gin.Context) {
 		return
 	}
 
+	s.events.Publish(ctx, events.Event{
+		Type:    events.OrderCreated,
+		OrderID: orderCreated.ID,
+		UserID:  orderCreated.UserID,
+		Status:  orderCreated.Status,
+		Amount:  orderCreated.Amount,
+	})
+
 	// send payment (MPESA or STRIPE)
 	log.Println("Sending stk or something")
 
 	ctx.JSON(http.StatusOK, orderCreated)
 }
 
+func (s *HttpServer) orderAddress(ctx *gin.Context, userID uint32, addressID *uint32) (*repository.Address, error) {
+	if addressID != nil {
+		return s.repo.addr.GetUserAddress(ctx, userID, *addressID)
+	}
+
+	address, err := s.repo.addr.GetDefaultAddress(ctx, userID)
+	if err != nil {
+		if pkg.ErrorCode(err) == pkg.NOT_FOUND_ERROR {
+			return nil, pkg.Errorf(pkg.INVALID_ERROR, "address_id or shipping_address is required")
+		}
+
+		return nil, err
+	}
+
+	return address, nil
+}
+
 func (s *HttpServer) getOrder(ctx *gin.Context) {
-	payload, err := getPayload(ctx)
+	userId, err := getParam(ctx.Param("id"))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 
@@ -138,8 +176,9 @@ func (s *HttpServer) getOrder(ctx *gin.Context) {
 		return
 	}
 
-	if order.UserID != payload.UserID && payload.Role != "ADMIN" {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(pkg.Errorf(pkg.AUTHENTICATION_ERROR, "unauthorized to view this order")))
+	// requireOwner only vouches for :id, the order itself must belong to that user
+	if order.UserID != userId {
+		ctx.JSON(http.StatusNotFound, errorResponse(pkg.Errorf(pkg.NOT_FOUND_ERROR, "no order found with id %d", orderId)))
 
 		return
 	}
@@ -155,19 +194,6 @@ func (s *HttpServer) getOrder(ctx *gin.Context) {
 }
 
 func (s *HttpServer) listOrders(ctx *gin.Context) {
-	payload, err := getPayload(ctx)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-
-		return
-	}
-
-	if isAdmin, err := isAdmin(payload); !isAdmin {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-
-		return
-	}
-
 	orders, err := s.repo.o.ListOrders(ctx)
 	if err != nil {
 		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
@@ -223,19 +249,6 @@ func (s *HttpServer) listUserOrders(ctx *gin.Context) {
 }
 
 func (s *HttpServer) listOrderWithStatus(ctx *gin.Context) {
-	payload, err := getPayload(ctx)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-
-		return
-	}
-
-	if isAdmin, err := isAdmin(payload); !isAdmin {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-
-		return
-	}
-
 	orderStatus := ctx.Query("type")
 	orderStatus = strings.ToUpper(orderStatus)
 
@@ -280,12 +293,6 @@ func (s *HttpServer) updateOrderStatus(ctx *gin.Context) {
 		return
 	}
 
-	if isAdmin, err := isAdmin(payload); !isAdmin {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-
-		return
-	}
-
 	id, err := getParam(ctx.Param("id"))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
@@ -319,29 +326,43 @@ func (s *HttpServer) updateOrderStatus(ctx *gin.Context) {
 		UpdatedBy: &payload.UserID,
 	}
 
-	if err := s.repo.o.UpdateOrder(ctx, updateOrder); err != nil {
+	before, err := s.repo.o.GetOrder(ctx, id)
+	if err != nil {
 		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
 
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
-}
+	if err := s.repo.o.UpdateOrder(ctx, updateOrder); err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
 
-func (s *HttpServer) deleteOrder(ctx *gin.Context) {
-	payload, err := getPayload(ctx)
+		return
+	}
+
+	after, err := s.repo.o.GetOrder(ctx, id)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
 
 		return
 	}
 
-	if isAdmin, err := isAdmin(payload); !isAdmin {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	s.audit(ctx, repository.AuditUpdate, repository.AuditEntityOrder, id, before, after)
 
-		return
+	if after.Status != before.Status {
+		s.events.Publish(ctx, events.Event{
+			Type:           events.OrderStatusChanged,
+			OrderID:        after.ID,
+			UserID:         after.UserID,
+			Status:         after.Status,
+			PreviousStatus: before.Status,
+			Amount:         after.Amount,
+		})
 	}
 
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func (s *HttpServer) deleteOrder(ctx *gin.Context) {
 	id, err := getParam(ctx.Param("id"))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
@@ -349,12 +370,21 @@ func (s *HttpServer) deleteOrder(ctx *gin.Context) {
 		return
 	}
 
+	before, err := s.repo.o.GetOrder(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
 	if err := s.repo.o.DeleteOrder(ctx, id); err != nil {
 		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
 
 		return
 	}
 
+	s.audit(ctx, repository.AuditDelete, repository.AuditEntityOrder, id, before, nil)
+
 	ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
 
@@ -365,6 +395,7 @@ func (s *HttpServer) structureOrderResponse(ctx context.Context, order *reposito
 		Amount:          order.Amount,
 		ShippingAmount:  order.ShippingAmount,
 		ShippingAddress: order.ShippingAddress,
+		AddressID:       order.AddressID,
 		Status:          order.Status,
 		UpdatedBy:       order.UpdatedBy,
 		UpdatedAt:       order.UpdatedAt,
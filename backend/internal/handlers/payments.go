@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/events"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+// recordPaymentRequest is the outcome of a payment as reported by MPESA or STRIPE.
+type recordPaymentRequest struct {
+	PaymentMethod     string          `binding:"required,oneof=MPESA STRIPE" json:"payment_method"`
+	Amount            float64         `binding:"required,gt=0"               json:"amount"`
+	Succeeded         bool            `                                      json:"succeeded"`
+	ResultDescription string          `                                      json:"result_description"`
+	PaymentDetails    json.RawMessage `                                      json:"payment_details"`
+}
+
+func (s *HttpServer) recordOrderPayment(ctx *gin.Context) {
+	id, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var req recordPaymentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+
+		return
+	}
+
+	payment, err := s.repo.o.RecordPayment(ctx, &repository.Payment{
+		OrderID:           id,
+		PaymentMethod:     req.PaymentMethod,
+		Amount:            req.Amount,
+		Succeeded:         req.Succeeded,
+		PaymentDetails:    req.PaymentDetails,
+		ResultDescription: req.ResultDescription,
+	})
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	s.audit(ctx, repository.AuditCreate, repository.AuditEntityPayment, payment.ID, nil, payment)
+
+	if payment.Succeeded {
+		s.events.Publish(ctx, events.Event{
+			Type:    events.OrderPaid,
+			OrderID: payment.OrderID,
+			UserID:  payment.UserID,
+			Amount:  payment.Amount,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, payment)
+}
+
+func (s *HttpServer) listOrderPayments(ctx *gin.Context) {
+	id, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	payments, err := s.repo.o.ListOrderPayments(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, payments)
+}
@@ -3,12 +3,18 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"sort"
 
 	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
 	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
 	"github.com/gin-gonic/gin"
 )
 
+type productResponse struct {
+	*repository.Product
+	ReviewSummary *repository.ReviewSummary `json:"review_summary"`
+}
+
 type createProductRequest struct {
 	Name            string   `binding:"required" json:"name"`
 	Description     string   `binding:"required" json:"description"`
@@ -31,12 +37,6 @@ func (s *HttpServer) createProduct(ctx *gin.Context) {
 		return
 	}
 
-	if isAdmin, err := isAdmin(payload); !isAdmin {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-
-		return
-	}
-
 	var req createProductRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
@@ -80,6 +80,8 @@ func (s *HttpServer) createProduct(ctx *gin.Context) {
 		return
 	}
 
+	s.audit(ctx, repository.AuditCreate, repository.AuditEntityProduct, product.ID, nil, product)
+
 	ctx.JSON(http.StatusCreated, product)
 }
 
@@ -91,12 +93,6 @@ func (s *HttpServer) updateProduct(ctx *gin.Context) {
 		return
 	}
 
-	if isAdmin, err := isAdmin(payload); !isAdmin {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-
-		return
-	}
-
 	var req createProductRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
@@ -130,6 +126,13 @@ func (s *HttpServer) updateProduct(ctx *gin.Context) {
 		return
 	}
 
+	before, err := s.repo.p.GetProduct(ctx, productId)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
 	err = s.repo.p.UpdateProduct(ctx, reqProduct)
 	if err != nil {
 		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
@@ -137,6 +140,15 @@ func (s *HttpServer) updateProduct(ctx *gin.Context) {
 		return
 	}
 
+	after, err := s.repo.p.GetProduct(ctx, productId)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	s.audit(ctx, repository.AuditUpdate, repository.AuditEntityProduct, productId, before, after)
+
 	ctx.JSON(http.StatusOK, reqProduct)
 }
 
@@ -145,47 +157,50 @@ type updateProductQuantityRequest struct {
 }
 
 func (s *HttpServer) updateProductQuantity(ctx *gin.Context) {
-	payload, err := getPayload(ctx)
+	body, err := ctx.GetRawData()
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
 
 		return
 	}
 
-	if isAdmin, err := isAdmin(payload); !isAdmin {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	var req updateProductQuantityRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
 
 		return
 	}
 
-	body, err := ctx.GetRawData()
+	id, err := getParam(ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 
 		return
 	}
 
-	var req updateProductQuantityRequest
-	if err := json.Unmarshal(body, &req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+	before, err := s.repo.p.GetProduct(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
 
 		return
 	}
 
-	id, err := getParam(ctx.Param("id"))
+	err = s.repo.p.UpdateProductQuantity(ctx, id, req.Quantity)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
 
 		return
 	}
 
-	err = s.repo.p.UpdateProductQuantity(ctx, id, req.Quantity)
+	after, err := s.repo.p.GetProduct(ctx, id)
 	if err != nil {
 		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
 
 		return
 	}
 
+	s.audit(ctx, repository.AuditUpdate, repository.AuditEntityProduct, id, before, after)
+
 	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
 }
 
@@ -204,7 +219,17 @@ func (s *HttpServer) getProduct(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, product)
+	summary, err := s.repo.r.GetProductReviewSummary(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, productResponse{
+		Product:       product,
+		ReviewSummary: summary,
+	})
 }
 
 func (s *HttpServer) listProducts(ctx *gin.Context) {
@@ -233,23 +258,72 @@ func (s *HttpServer) listProducts(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, result)
-}
+	sortBy := ctx.Query("sort")
+	if sortBy != "" && sortBy != "rating" {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "sort must be rating")))
 
-func (s *HttpServer) deleteProduct(ctx *gin.Context) {
-	payload, err := getPayload(ctx)
+		return
+	}
+
+	response, err := s.structureProductResponse(ctx, result, sortBy == "rating")
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
 
 		return
 	}
 
-	if isAdmin, err := isAdmin(payload); !isAdmin {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	ctx.JSON(http.StatusOK, response)
+}
+
+// structureProductResponse attaches the review summaries and, when byRating is
+// set, orders the products by their Bayesian weighted rating.
+func (s *HttpServer) structureProductResponse(ctx *gin.Context, products []*repository.Product, byRating bool) ([]productResponse, error) {
+	summaries, err := s.repo.r.ListProductReviewSummaries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ratingTotal, reviewCount uint32
 
-		return
+	byProduct := make(map[uint32]*repository.ReviewSummary, len(summaries))
+	for _, summary := range summaries {
+		byProduct[summary.ProductID] = summary
+		ratingTotal += summary.RatingTotal
+		reviewCount += summary.ReviewCount
+	}
+
+	result := []productResponse{}
+	for _, product := range products {
+		summary, ok := byProduct[product.ID]
+		if !ok {
+			summary = &repository.ReviewSummary{
+				ProductID:    product.ID,
+				Distribution: map[uint32]uint32{1: 0, 2: 0, 3: 0, 4: 0, 5: 0},
+			}
+		}
+
+		result = append(result, productResponse{
+			Product:       product,
+			ReviewSummary: summary,
+		})
+	}
+
+	if byRating {
+		var storeAverage float64
+		if reviewCount > 0 {
+			storeAverage = float64(ratingTotal) / float64(reviewCount)
+		}
+
+		sort.SliceStable(result, func(i, j int) bool {
+			return result[i].ReviewSummary.WeightedRating(s.config.REVIEW_RATING_PRIOR, storeAverage) >
+				result[j].ReviewSummary.WeightedRating(s.config.REVIEW_RATING_PRIOR, storeAverage)
+		})
 	}
 
+	return result, nil
+}
+
+func (s *HttpServer) deleteProduct(ctx *gin.Context) {
 	id, err := getParam(ctx.Param("id"))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
@@ -257,6 +331,13 @@ func (s *HttpServer) deleteProduct(ctx *gin.Context) {
 		return
 	}
 
+	before, err := s.repo.p.GetProduct(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
 	err = s.repo.p.DeleteProduct(ctx, id)
 	if err != nil {
 		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
@@ -264,5 +345,7 @@ func (s *HttpServer) deleteProduct(ctx *gin.Context) {
 		return
 	}
 
+	s.audit(ctx, repository.AuditDelete, repository.AuditEntityProduct, id, before, nil)
+
 	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
 }
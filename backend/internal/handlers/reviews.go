@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
@@ -9,19 +11,32 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+type reviewReplyResponse struct {
+	Reply     string    `json:"reply"`
+	RepliedAt time.Time `json:"replied_at"`
+}
+
 type reviewResponse struct {
-	ID          uint32    `json:"id"`
-	AuthorEmail string    `json:"author_email"`
-	Rating      uint32    `json:"rating"`
-	Review      string    `json:"review"`
-	CreatedAt   time.Time `json:"created_at"`
-	ProductName string    `json:"product_name"`
+	ID               uint32               `json:"id"`
+	AuthorEmail      string               `json:"author_email"`
+	Rating           uint32               `json:"rating"`
+	Review           string               `json:"review"`
+	ImgUrls          []string             `json:"img_urls"`
+	HelpfulCount     uint32               `json:"helpful_count"`
+	Reply            *reviewReplyResponse `json:"reply"`
+	VerifiedPurchase bool                 `json:"verified_purchase"`
+	Status           string               `json:"status"`
+	ModerationReason string               `json:"moderation_reason"`
+	UpdatedAt        time.Time            `json:"updated_at"`
+	CreatedAt        time.Time            `json:"created_at"`
+	ProductName      string               `json:"product_name"`
 }
 
 type createReviewRequest struct {
-	ProductID uint32 `binding:"required" json:"product_id"`
-	Review    string `binding:"required" json:"review"`
-	Rating    uint32 `binding:"required" json:"rating"`
+	ProductID uint32   `binding:"required"       json:"product_id"`
+	Review    string   `binding:"required"       json:"review"`
+	Rating    uint32   `binding:"required"       json:"rating"`
+	ImgUrls   []string `binding:"max=5,dive,url" json:"img_urls"`
 }
 
 func (s *HttpServer) createReview(ctx *gin.Context) {
@@ -39,12 +54,21 @@ func (s *HttpServer) createReview(ctx *gin.Context) {
 		return
 	}
 
-	review, err := s.repo.r.CreateReview(ctx, &repository.Review{
+	data := &repository.Review{
 		ProductID: req.ProductID,
 		UserID:    payload.UserID,
 		Rating:    req.Rating,
 		Review:    req.Review,
-	})
+	}
+
+	err = data.MarshalOptions(req.ImgUrls)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+
+		return
+	}
+
+	review, err := s.repo.r.CreateReview(ctx, data)
 	if err != nil {
 		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
 
@@ -87,7 +111,7 @@ func (s *HttpServer) listProductsReviews(ctx *gin.Context) {
 		return
 	}
 
-	reviews, err := s.repo.r.ListProductsReviews(ctx, id)
+	reviews, err := s.repo.r.ListProductsReviews(ctx, id, ctx.DefaultQuery("sort", repository.ReviewSortNewest))
 	if err != nil {
 		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
 
@@ -144,6 +168,14 @@ func (s *HttpServer) getReview(ctx *gin.Context) {
 		return
 	}
 
+	// reviews waiting for or refused by a moderator are not public
+	if review.Status != repository.ReviewApproved {
+		err = pkg.Errorf(pkg.NOT_FOUND_ERROR, "no review found with id %d", id)
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
 	result, err := s.structureReviewResponse([]*repository.Review{review}, ctx)
 	if err != nil {
 		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
@@ -155,15 +187,229 @@ func (s *HttpServer) getReview(ctx *gin.Context) {
 }
 
 func (s *HttpServer) deleteReview(ctx *gin.Context) {
+	id, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	before, err := s.repo.r.GetReview(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	err = s.repo.r.DeleteReview(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	s.audit(ctx, repository.AuditDelete, repository.AuditEntityReview, id, before, nil)
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+type updateReviewRequest struct {
+	Review  string   `binding:"required"       json:"review"`
+	Rating  uint32   `binding:"required,max=5" json:"rating"`
+	ImgUrls []string `binding:"max=5,dive,url" json:"img_urls"` // omit to keep the current photos
+}
+
+func (s *HttpServer) updateUserReview(ctx *gin.Context) {
+	userId, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	id, err := getParam(ctx.Param("reviewId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var req updateReviewRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+
+		return
+	}
+
+	data := &repository.Review{
+		ID:     id,
+		UserID: userId,
+		Rating: req.Rating,
+		Review: req.Review,
+	}
+
+	if req.ImgUrls != nil {
+		if err := data.MarshalOptions(req.ImgUrls); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+
+			return
+		}
+	}
+
+	review, err := s.repo.r.UpdateUserReview(ctx, data)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	result, err := s.structureReviewResponse([]*repository.Review{review}, ctx)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (s *HttpServer) deleteUserReview(ctx *gin.Context) {
+	userId, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	id, err := getParam(ctx.Param("reviewId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	err = s.repo.r.DeleteUserReview(ctx, userId, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+func (s *HttpServer) listReviewModerationQueue(ctx *gin.Context) {
+	status := repository.ReviewPending
+	if q := ctx.Query("status"); q != "" {
+		status = strings.ToUpper(q)
+	}
+
+	if status != repository.ReviewPending && status != repository.ReviewApproved && status != repository.ReviewRejected {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "status must be %s, %s or %s", repository.ReviewPending, repository.ReviewApproved, repository.ReviewRejected)))
+
+		return
+	}
+
+	limit, offset, err := parsePagination(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	reviews, err := s.repo.r.ListReviewsByStatus(ctx, status, limit, offset)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	result, err := s.structureReviewResponse(reviews, ctx)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	if result == nil {
+		result = []reviewResponse{}
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (s *HttpServer) approveReview(ctx *gin.Context) {
+	s.moderateReview(ctx, repository.ReviewApproved, "")
+}
+
+type rejectReviewRequest struct {
+	Reason string `binding:"required,max=255" json:"reason"`
+}
+
+func (s *HttpServer) rejectReview(ctx *gin.Context) {
+	var req rejectReviewRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+
+		return
+	}
+
+	s.moderateReview(ctx, repository.ReviewRejected, req.Reason)
+}
+
+func (s *HttpServer) moderateReview(ctx *gin.Context, status string, reason string) {
 	payload, err := getPayload(ctx)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	id, err := getParam(ctx.Param("id"))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 
 		return
 	}
 
-	if isAdmin, err := isAdmin(payload); !isAdmin {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	before, err := s.repo.r.GetReview(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	review, err := s.repo.r.ModerateReview(ctx, id, payload.UserID, status, reason)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	s.audit(ctx, repository.AuditUpdate, repository.AuditEntityReview, id, before, review)
+
+	result, err := s.structureReviewResponse([]*repository.Review{review}, ctx)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (s *HttpServer) voteReviewHelpful(ctx *gin.Context) {
+	s.updateReviewVote(ctx, s.repo.r.VoteReviewHelpful)
+}
+
+func (s *HttpServer) removeReviewVote(ctx *gin.Context) {
+	s.updateReviewVote(ctx, s.repo.r.RemoveReviewVote)
+}
+
+func (s *HttpServer) updateReviewVote(ctx *gin.Context, vote func(ctx context.Context, id uint32, userID uint32) (*repository.Review, error)) {
+	payload, err := getPayload(ctx)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
 
 		return
 	}
@@ -175,13 +421,92 @@ func (s *HttpServer) deleteReview(ctx *gin.Context) {
 		return
 	}
 
-	err = s.repo.r.DeleteReview(ctx, id)
+	review, err := vote(ctx, id, payload.UserID)
 	if err != nil {
 		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
 
 		return
 	}
 
+	ctx.JSON(http.StatusOK, gin.H{"id": review.ID, "helpful_count": review.HelpfulCount})
+}
+
+type replyToReviewRequest struct {
+	Reply string `binding:"required,max=2000" json:"reply"`
+}
+
+func (s *HttpServer) replyToReview(ctx *gin.Context) {
+	payload, err := getPayload(ctx)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	id, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var req replyToReviewRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+
+		return
+	}
+
+	before, err := s.repo.r.GetReview(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	review, err := s.repo.r.ReplyToReview(ctx, id, payload.UserID, req.Reply)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	s.audit(ctx, repository.AuditUpdate, repository.AuditEntityReview, id, before, review)
+
+	result, err := s.structureReviewResponse([]*repository.Review{review}, ctx)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (s *HttpServer) deleteReviewReply(ctx *gin.Context) {
+	id, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	before, err := s.repo.r.GetReview(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	review, err := s.repo.r.DeleteReviewReply(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	s.audit(ctx, repository.AuditUpdate, repository.AuditEntityReview, id, before, review)
+
 	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
 }
 
@@ -199,13 +524,33 @@ func (s *HttpServer) structureReviewResponse(reviews []*repository.Review, ctx *
 			return nil, err
 		}
 
+		imgUrls, err := review.UnmarshalOptions()
+		if err != nil {
+			return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "%v", err)
+		}
+
+		var reply *reviewReplyResponse
+		if review.Reply != nil && review.RepliedAt != nil {
+			reply = &reviewReplyResponse{
+				Reply:     *review.Reply,
+				RepliedAt: *review.RepliedAt,
+			}
+		}
+
 		result = append(result, reviewResponse{
-			ID:          review.ID,
-			AuthorEmail: email,
-			Rating:      review.Rating,
-			Review:      review.Review,
-			CreatedAt:   review.CreatedAt,
-			ProductName: productName,
+			ID:               review.ID,
+			AuthorEmail:      email,
+			Rating:           review.Rating,
+			Review:           review.Review,
+			ImgUrls:          imgUrls,
+			HelpfulCount:     review.HelpfulCount,
+			Reply:            reply,
+			VerifiedPurchase: review.VerifiedPurchase,
+			Status:           review.Status,
+			ModerationReason: review.ModerationReason,
+			UpdatedAt:        review.UpdatedAt,
+			CreatedAt:        review.CreatedAt,
+			ProductName:      productName,
 		})
 	}
 
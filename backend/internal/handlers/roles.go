@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+// permissions seeded by the roles_permissions migration
+const (
+	permUsersRead        = "users:read"
+	permUsersManage      = "users:manage"
+	permRolesManage      = "roles:manage"
+	permProductsWrite    = "products:write"
+	permCategoriesWrite  = "categories:write"
+	permOrdersRead       = "orders:read"
+	permOrdersFulfil     = "orders:fulfil"
+	permOrdersDelete     = "orders:delete"
+	permCartsRead        = "carts:read"
+	permReviewsModerate  = "reviews:moderate"
+	permBlogsPublish     = "blogs:publish"
+	permSecurityManage   = "security:manage"
+	permAuditRead        = "audit:read"
+	permAPIKeysManage    = "api_keys:manage"
+	permStockWrite       = "stock:write"
+	permCommentsModerate = "comments:moderate"
+	permNewsletterSend   = "newsletter:send"
+)
+
+type createRoleRequest struct {
+	Name        string   `binding:"required" json:"name"`
+	Description string   `binding:""         json:"description"`
+	Permissions []string `binding:""         json:"permissions"`
+}
+
+func (s *HttpServer) createRole(ctx *gin.Context) {
+	var req createRoleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+
+		return
+	}
+
+	role, err := s.repo.role.CreateRole(ctx, &repository.Role{
+		Name:        strings.ToUpper(req.Name),
+		Description: req.Description,
+		Permissions: req.Permissions,
+	})
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	s.audit(ctx, repository.AuditCreate, repository.AuditEntityRole, role.Name, nil, role)
+
+	ctx.JSON(http.StatusCreated, role)
+}
+
+func (s *HttpServer) listRoles(ctx *gin.Context) {
+	roles, err := s.repo.role.ListRoles(ctx)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, roles)
+}
+
+func (s *HttpServer) getRole(ctx *gin.Context) {
+	role, err := s.repo.role.GetRole(ctx, strings.ToUpper(ctx.Param("name")))
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, role)
+}
+
+func (s *HttpServer) listPermissions(ctx *gin.Context) {
+	permissions, err := s.repo.role.ListPermissions(ctx)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, permissions)
+}
+
+type updateRolePermissionsRequest struct {
+	Permissions []string `binding:"required" json:"permissions"`
+}
+
+func (s *HttpServer) updateRolePermissions(ctx *gin.Context) {
+	payload, err := getPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	name := strings.ToUpper(ctx.Param("name"))
+
+	var req updateRolePermissionsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+
+		return
+	}
+
+	// stop admins from locking themselves out of role management
+	if name == payload.Role && !containsString(req.Permissions, permRolesManage) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "cannot remove %s from your own role", permRolesManage)))
+
+		return
+	}
+
+	before, err := s.repo.role.GetRole(ctx, name)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	if err := s.repo.role.SetRolePermissions(ctx, name, req.Permissions); err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	role, err := s.repo.role.GetRole(ctx, name)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	s.audit(ctx, repository.AuditUpdate, repository.AuditEntityRole, name, before, role)
+
+	ctx.JSON(http.StatusOK, role)
+}
+
+func (s *HttpServer) deleteRole(ctx *gin.Context) {
+	name := strings.ToUpper(ctx.Param("name"))
+
+	before, err := s.repo.role.GetRole(ctx, name)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	if err := s.repo.role.DeleteRole(ctx, name); err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	s.audit(ctx, repository.AuditDelete, repository.AuditEntityRole, name, before, nil)
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
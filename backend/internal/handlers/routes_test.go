@@ -0,0 +1,721 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+	"github.com/gin-gonic/gin"
+	"github.com/rakyll/statik/fs"
+)
+
+// access is who a route lets through to its handler.
+type access int
+
+const (
+	accessPublic         access = iota // anyone, signed in or not
+	accessSignedIn                     // any signed in user
+	accessOwner                        // only the user in :id
+	accessOwnerOrAdmin                 // the user in :id or a role with the route permission
+	accessAdmin                        // a role with the route permission
+	accessOwnerPublisher               // the user in :id when their role can publish blogs
+)
+
+type routeAccess struct {
+	method string
+	path   string
+	access access
+}
+
+// routeAccessTable lists every route registered in setRoutes, a new route
+// fails TestRouteAccess until it is added here.
+var routeAccessTable = []routeAccess{
+	{http.MethodGet, "/api/v1/swagger/*filepath", accessPublic},
+	{http.MethodHead, "/api/v1/swagger/*filepath", accessPublic},
+	{http.MethodGet, "/health", accessPublic},
+	{http.MethodGet, "/.well-known/jwks.json", accessPublic},
+	{http.MethodGet, "/api/v1/users/", accessAdmin},
+	{http.MethodPost, "/api/v1/users/register", accessPublic},
+	{http.MethodGet, "/api/v1/users/:id", accessOwnerOrAdmin},
+	{http.MethodDelete, "/api/v1/users/:id", accessOwner},
+	{http.MethodGet, "/api/v1/users/:id/export", accessOwner},
+	{http.MethodPost, "/api/v1/users/login", accessPublic},
+	{http.MethodPost, "/api/v1/users/login/2fa", accessPublic},
+	{http.MethodGet, "/api/v1/users/oidc/authorize", accessPublic},
+	{http.MethodPost, "/api/v1/users/oidc/callback", accessPublic},
+	{http.MethodGet, "/api/v1/users/:id/refresh-token", accessPublic},
+	{http.MethodPost, "/api/v1/users/reset-password", accessPublic},
+	{http.MethodPost, "/api/v1/users/reset-password/confirm", accessPublic},
+	{http.MethodPost, "/api/v1/users/invite", accessAdmin},
+	{http.MethodPut, "/api/v1/users/:id/update-subscription", accessOwner},
+	{http.MethodPut, "/api/v1/users/:id/profile", accessOwner},
+	{http.MethodPut, "/api/v1/users/:id/update-role", accessAdmin},
+	{http.MethodGet, "/api/v1/users/:id/overview", accessAdmin},
+	{http.MethodPut, "/api/v1/users/:id/disable", accessAdmin},
+	{http.MethodPut, "/api/v1/users/:id/enable", accessAdmin},
+	{http.MethodPost, "/api/v1/users/:id/force-password-reset", accessAdmin},
+	{http.MethodPost, "/api/v1/users/:id/2fa/enrol", accessOwner},
+	{http.MethodPost, "/api/v1/users/:id/2fa/verify", accessOwner},
+	{http.MethodPost, "/api/v1/users/:id/2fa/recovery-codes", accessOwner},
+	{http.MethodDelete, "/api/v1/users/:id/2fa", accessOwner},
+	{http.MethodGet, "/api/v1/users/:id/identities", accessOwnerOrAdmin},
+	{http.MethodGet, "/api/v1/users/:id/addresses", accessOwnerOrAdmin},
+	{http.MethodPost, "/api/v1/users/:id/addresses", accessOwner},
+	{http.MethodGet, "/api/v1/users/:id/addresses/:addressId", accessOwnerOrAdmin},
+	{http.MethodPut, "/api/v1/users/:id/addresses/:addressId", accessOwner},
+	{http.MethodPut, "/api/v1/users/:id/addresses/:addressId/default", accessOwner},
+	{http.MethodDelete, "/api/v1/users/:id/addresses/:addressId", accessOwner},
+	{http.MethodGet, "/api/v1/users/:id/reviews", accessOwnerOrAdmin},
+	{http.MethodPut, "/api/v1/users/:id/reviews/:reviewId", accessOwner},
+	{http.MethodDelete, "/api/v1/users/:id/reviews/:reviewId", accessOwner},
+	{http.MethodGet, "/api/v1/users/:id/notifications", accessOwner},
+	{http.MethodPut, "/api/v1/users/:id/notifications/read", accessOwner},
+	{http.MethodPut, "/api/v1/users/:id/notifications/:notificationId/read", accessOwner},
+	{http.MethodGet, "/api/v1/users/:id/notification-preferences", accessOwner},
+	{http.MethodPut, "/api/v1/users/:id/notification-preferences", accessOwner},
+	{http.MethodPost, "/api/v1/users/:id/blogs", accessOwnerPublisher},
+	{http.MethodGet, "/api/v1/users/:id/blogs", accessPublic},
+	{http.MethodGet, "/api/v1/users/:id/blogs/editor", accessOwnerPublisher},
+	{http.MethodDelete, "/api/v1/users/:id/blogs/:blogId", accessOwnerPublisher},
+	{http.MethodPut, "/api/v1/users/:id/blogs/:blogId", accessOwnerPublisher},
+	{http.MethodGet, "/api/v1/users/:id/cart", accessOwnerOrAdmin},
+	{http.MethodPut, "/api/v1/users/:id/cart", accessOwner},
+	{http.MethodPost, "/api/v1/users/:id/cart", accessOwner},
+	{http.MethodDelete, "/api/v1/users/:id/cart", accessOwner},
+	{http.MethodGet, "/api/v1/users/:id/orders", accessOwnerOrAdmin},
+	{http.MethodPost, "/api/v1/users/:id/orders", accessOwner},
+	{http.MethodGet, "/api/v1/users/:id/orders/events", accessOwnerOrAdmin},
+	{http.MethodGet, "/api/v1/users/:id/orders/:orderId", accessOwnerOrAdmin},
+	{http.MethodGet, "/api/v1/products/", accessPublic},
+	{http.MethodPost, "/api/v1/products/create-product", accessAdmin},
+	{http.MethodGet, "/api/v1/products/new/feed.rss", accessPublic},
+	{http.MethodGet, "/api/v1/products/new/feed.atom", accessPublic},
+	{http.MethodGet, "/api/v1/products/:id", accessPublic},
+	{http.MethodPut, "/api/v1/products/:id", accessAdmin},
+	{http.MethodPut, "/api/v1/products/:id/stock", accessAdmin},
+	{http.MethodDelete, "/api/v1/products/:id", accessAdmin},
+	{http.MethodPost, "/api/v1/products/:id/reviews", accessSignedIn},
+	{http.MethodGet, "/api/v1/products/:id/reviews", accessPublic},
+	{http.MethodGet, "/api/v1/categories/", accessPublic},
+	{http.MethodPost, "/api/v1/categories/create-category", accessAdmin},
+	{http.MethodGet, "/api/v1/categories/:id", accessPublic},
+	{http.MethodPut, "/api/v1/categories/:id", accessAdmin},
+	{http.MethodDelete, "/api/v1/categories/:id", accessAdmin},
+	{http.MethodGet, "/api/v1/reviews/", accessPublic},
+	{http.MethodGet, "/api/v1/reviews/:id", accessPublic},
+	{http.MethodGet, "/api/v1/reviews/moderation", accessAdmin},
+	{http.MethodPut, "/api/v1/reviews/:id/approve", accessAdmin},
+	{http.MethodPut, "/api/v1/reviews/:id/reject", accessAdmin},
+	{http.MethodDelete, "/api/v1/reviews/:id", accessAdmin},
+	{http.MethodPost, "/api/v1/reviews/:id/helpful", accessSignedIn},
+	{http.MethodDelete, "/api/v1/reviews/:id/helpful", accessSignedIn},
+	{http.MethodPut, "/api/v1/reviews/:id/reply", accessAdmin},
+	{http.MethodDelete, "/api/v1/reviews/:id/reply", accessAdmin},
+	{http.MethodGet, "/api/v1/blogs/", accessPublic},
+	{http.MethodGet, "/api/v1/blogs/tags", accessPublic},
+	{http.MethodGet, "/api/v1/blogs/feed.rss", accessPublic},
+	{http.MethodGet, "/api/v1/blogs/feed.atom", accessPublic},
+	{http.MethodGet, "/api/v1/blogs/tags/:tag/feed.rss", accessPublic},
+	{http.MethodGet, "/api/v1/blogs/tags/:tag/feed.atom", accessPublic},
+	{http.MethodGet, "/api/v1/blogs/:blogId", accessPublic},
+	{http.MethodGet, "/api/v1/blogs/slug/:slug", accessPublic},
+	{http.MethodGet, "/api/v1/blogs/:blogId/comments", accessPublic},
+	{http.MethodPost, "/api/v1/blogs/:blogId/comments", accessSignedIn},
+	{http.MethodPut, "/api/v1/blogs/:blogId/comments/:commentId", accessSignedIn},
+	{http.MethodDelete, "/api/v1/blogs/:blogId/comments/:commentId", accessSignedIn},
+	{http.MethodGet, "/api/v1/comments/moderation", accessAdmin},
+	{http.MethodPut, "/api/v1/comments/:id/approve", accessAdmin},
+	{http.MethodPut, "/api/v1/comments/:id/hide", accessAdmin},
+	{http.MethodPost, "/api/v1/newsletter/subscribe", accessPublic},
+	{http.MethodGet, "/api/v1/newsletter/confirm", accessPublic},
+	{http.MethodGet, "/api/v1/newsletter/unsubscribe", accessPublic},
+	{http.MethodPost, "/api/v1/newsletter/unsubscribe", accessPublic},
+	{http.MethodGet, "/api/v1/newsletter/campaigns", accessAdmin},
+	{http.MethodPost, "/api/v1/newsletter/campaigns", accessAdmin},
+	{http.MethodGet, "/api/v1/newsletter/campaigns/:id", accessAdmin},
+	{http.MethodGet, "/api/v1/newsletter/campaigns/:id/stats", accessAdmin},
+	{http.MethodGet, "/api/v1/newsletter/campaigns/:id/deliveries", accessAdmin},
+	{http.MethodGet, "/api/v1/carts/", accessAdmin},
+	{http.MethodGet, "/api/v1/orders/", accessAdmin},
+	{http.MethodGet, "/api/v1/orders/status", accessAdmin},
+	{http.MethodGet, "/api/v1/orders/events", accessAdmin},
+	{http.MethodPut, "/api/v1/orders/:id", accessAdmin},
+	{http.MethodDelete, "/api/v1/orders/:id", accessAdmin},
+	{http.MethodGet, "/api/v1/orders/:id/payments", accessAdmin},
+	{http.MethodPost, "/api/v1/orders/:id/payments", accessAdmin},
+	{http.MethodGet, "/api/v1/roles/", accessAdmin},
+	{http.MethodPost, "/api/v1/roles/", accessAdmin},
+	{http.MethodGet, "/api/v1/roles/permissions", accessAdmin},
+	{http.MethodGet, "/api/v1/roles/:name", accessAdmin},
+	{http.MethodPut, "/api/v1/roles/:name/permissions", accessAdmin},
+	{http.MethodDelete, "/api/v1/roles/:name", accessAdmin},
+	{http.MethodGet, "/api/v1/security/lockouts", accessAdmin},
+	{http.MethodPost, "/api/v1/security/lockouts/unlock", accessAdmin},
+	{http.MethodGet, "/api/v1/security/events", accessAdmin},
+	{http.MethodGet, "/api/v1/audit/", accessAdmin},
+	{http.MethodGet, "/api/v1/api-keys/", accessAdmin},
+	{http.MethodPost, "/api/v1/api-keys/", accessAdmin},
+	{http.MethodGet, "/api/v1/api-keys/:id", accessAdmin},
+	{http.MethodDelete, "/api/v1/api-keys/:id", accessAdmin},
+}
+
+// the handlers of these routes turn everyone away in this test, most because
+// their feature is not configured and refresh-token because no caller holds
+// the stored refresh token
+var rejectedRouteStatus = map[string]int{
+	"GET /.well-known/jwks.json":          http.StatusNotFound,
+	"GET /api/v1/users/oidc/authorize":    http.StatusNotImplemented,
+	"POST /api/v1/users/oidc/callback":    http.StatusNotImplemented,
+	"GET /api/v1/users/:id/refresh-token": http.StatusUnauthorized,
+}
+
+type testCaller struct {
+	name   string
+	userID uint32
+	role   string
+}
+
+// the owner is a blogger so the blog editor routes have someone to let in
+var (
+	callerAnonymous = testCaller{name: "anonymous"}
+	callerOwner     = testCaller{name: "owner", userID: 1, role: "BLOGGER"}
+	callerOther     = testCaller{name: "other user", userID: 2, role: "USER"}
+	callerAdmin     = testCaller{name: "admin", userID: 3, role: "ADMIN"}
+)
+
+func (a access) allows(caller testCaller) bool {
+	switch a {
+	case accessPublic:
+		return true
+	case accessSignedIn:
+		return caller != callerAnonymous
+	case accessOwner, accessOwnerPublisher:
+		return caller == callerOwner
+	case accessOwnerOrAdmin:
+		return caller == callerOwner || caller == callerAdmin
+	case accessAdmin:
+		return caller == callerAdmin
+	}
+
+	return false
+}
+
+// errStubRepository answers the repository calls of the handlers, a handler
+// reached by TestRouteAccess fails on its first one.
+var errStubRepository = pkg.Errorf(pkg.INTERNAL_ERROR, "stub repository")
+
+// stubRoleRepository holds the seeded roles, see 000004_roles_permissions.
+type stubRoleRepository struct {
+	repository.RoleRepository
+}
+
+func (stubRoleRepository) RoleHasPermission(ctx context.Context, role string, permission string) (bool, error) {
+	switch role {
+	case "ADMIN":
+		return true, nil
+	case "BLOGGER":
+		return permission == permBlogsPublish, nil
+	}
+
+	return false, nil
+}
+
+func (stubRoleRepository) GetRole(context.Context, string) (*repository.Role, error) {
+	return nil, errStubRepository
+}
+
+func (stubRoleRepository) ListPermissions(context.Context) ([]*repository.Permission, error) {
+	return nil, errStubRepository
+}
+
+func (stubRoleRepository) ListRoles(context.Context) ([]*repository.Role, error) {
+	return nil, errStubRepository
+}
+
+// stubUserRepository finds every caller as an active account with its role.
+type stubUserRepository struct {
+	repository.UserRepository
+}
+
+func (stubUserRepository) GetUserById(ctx context.Context, id uint32) (*repository.User, error) {
+	for _, caller := range []testCaller{callerOwner, callerOther, callerAdmin} {
+		if caller.userID == id {
+			return &repository.User{ID: id, Role: caller.role}, nil
+		}
+	}
+
+	return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "no user found with id %d", id)
+}
+
+func (stubUserRepository) RequirePasswordReset(context.Context, *repository.PasswordToken) error {
+	return errStubRepository
+}
+
+func (stubUserRepository) SearchUsers(context.Context, repository.UserFilter) ([]*repository.User, error) {
+	return nil, errStubRepository
+}
+
+func (stubUserRepository) SetUserDisabled(context.Context, uint32, uint32, bool) error {
+	return errStubRepository
+}
+
+type stubProductRepository struct {
+	repository.ProductRepository
+}
+
+func (stubProductRepository) GetProduct(context.Context, uint32) (*repository.Product, error) {
+	return nil, errStubRepository
+}
+
+func (stubProductRepository) ListNewProducts(context.Context) ([]*repository.Product, error) {
+	return nil, errStubRepository
+}
+
+func (stubProductRepository) ListProducts(context.Context) ([]*repository.Product, error) {
+	return nil, errStubRepository
+}
+
+type stubCartRepository struct {
+	repository.CartRepository
+}
+
+func (stubCartRepository) DeleteCart(context.Context, uint32) error {
+	return errStubRepository
+}
+
+func (stubCartRepository) ListCarts(context.Context) ([]*repository.UserCart, error) {
+	return nil, errStubRepository
+}
+
+func (stubCartRepository) ListUserCarts(context.Context, uint32) ([]*repository.Cart, error) {
+	return nil, errStubRepository
+}
+
+type stubOrderRepository struct {
+	repository.OrderRepository
+}
+
+func (stubOrderRepository) GetOrder(context.Context, uint32) (*repository.Order, error) {
+	return nil, errStubRepository
+}
+
+func (stubOrderRepository) ListOrderPayments(context.Context, uint32) ([]*repository.Payment, error) {
+	return nil, errStubRepository
+}
+
+func (stubOrderRepository) ListOrders(context.Context) ([]*repository.Order, error) {
+	return nil, errStubRepository
+}
+
+func (stubOrderRepository) ListUserOrders(context.Context, uint32) ([]*repository.Order, error) {
+	return nil, errStubRepository
+}
+
+type stubCategoryRepository struct {
+	repository.CategoryRepository
+}
+
+func (stubCategoryRepository) GetCategory(context.Context, uint32) (*repository.Category, error) {
+	return nil, errStubRepository
+}
+
+func (stubCategoryRepository) ListCategories(context.Context) ([]*repository.Category, error) {
+	return nil, errStubRepository
+}
+
+type stubReviewRepository struct {
+	repository.ReviewRepository
+}
+
+func (stubReviewRepository) DeleteUserReview(context.Context, uint32, uint32) error {
+	return errStubRepository
+}
+
+func (stubReviewRepository) GetReview(context.Context, uint32) (*repository.Review, error) {
+	return nil, errStubRepository
+}
+
+func (stubReviewRepository) ListProductsReviews(context.Context, uint32, string) ([]*repository.Review, error) {
+	return nil, errStubRepository
+}
+
+func (stubReviewRepository) ListReviews(context.Context) ([]*repository.Review, error) {
+	return nil, errStubRepository
+}
+
+func (stubReviewRepository) ListReviewsByStatus(context.Context, string, int32, int32) ([]*repository.Review, error) {
+	return nil, errStubRepository
+}
+
+func (stubReviewRepository) ListUsersReviews(context.Context, uint32) ([]*repository.Review, error) {
+	return nil, errStubRepository
+}
+
+func (stubReviewRepository) RemoveReviewVote(context.Context, uint32, uint32) (*repository.Review, error) {
+	return nil, errStubRepository
+}
+
+func (stubReviewRepository) VoteReviewHelpful(context.Context, uint32, uint32) (*repository.Review, error) {
+	return nil, errStubRepository
+}
+
+type stubBlogRepository struct {
+	repository.BlogRepository
+}
+
+func (stubBlogRepository) GetBlog(context.Context, uint32) (*repository.Blog, error) {
+	return nil, errStubRepository
+}
+
+func (stubBlogRepository) GetBlogsByAuthor(context.Context, uint32) ([]*repository.Blog, error) {
+	return nil, errStubRepository
+}
+
+func (stubBlogRepository) GetPublishedBlog(context.Context, uint32) (*repository.Blog, error) {
+	return nil, errStubRepository
+}
+
+func (stubBlogRepository) GetPublishedBlogBySlug(context.Context, string) (*repository.Blog, error) {
+	return nil, errStubRepository
+}
+
+func (stubBlogRepository) ListAuthorBlogs(context.Context, uint32, *string) ([]*repository.Blog, error) {
+	return nil, errStubRepository
+}
+
+func (stubBlogRepository) ListTags(context.Context) ([]*repository.Tag, error) {
+	return nil, errStubRepository
+}
+
+func (stubBlogRepository) SearchBlogs(context.Context, repository.BlogFilter) ([]*repository.Blog, error) {
+	return nil, errStubRepository
+}
+
+type stubTwoFactorRepository struct {
+	repository.TwoFactorRepository
+}
+
+func (stubTwoFactorRepository) CreateTwoFactor(context.Context, uint32, string) error {
+	return errStubRepository
+}
+
+type stubSecurityRepository struct {
+	repository.SecurityRepository
+}
+
+func (stubSecurityRepository) ListLockedLogins(context.Context) ([]*repository.LoginAttempt, error) {
+	return nil, errStubRepository
+}
+
+func (stubSecurityRepository) ListSecurityEvents(context.Context, repository.SecurityEventFilter) ([]*repository.SecurityEvent, error) {
+	return nil, errStubRepository
+}
+
+type stubOAuthRepository struct {
+	repository.OAuthRepository
+}
+
+func (stubOAuthRepository) ListUserIdentities(context.Context, uint32) ([]*repository.UserIdentity, error) {
+	return nil, errStubRepository
+}
+
+type stubAddressRepository struct {
+	repository.AddressRepository
+}
+
+func (stubAddressRepository) DeleteAddress(context.Context, uint32, uint32) error {
+	return errStubRepository
+}
+
+func (stubAddressRepository) GetUserAddress(context.Context, uint32, uint32) (*repository.Address, error) {
+	return nil, errStubRepository
+}
+
+func (stubAddressRepository) ListUserAddresses(context.Context, uint32) ([]*repository.Address, error) {
+	return nil, errStubRepository
+}
+
+func (stubAddressRepository) SetDefaultAddress(context.Context, uint32, uint32) error {
+	return errStubRepository
+}
+
+type stubAuditRepository struct {
+	repository.AuditRepository
+}
+
+func (stubAuditRepository) ListAuditEntries(context.Context, repository.AuditFilter) ([]*repository.AuditEntry, error) {
+	return nil, errStubRepository
+}
+
+type stubAPIKeyRepository struct {
+	repository.APIKeyRepository
+}
+
+func (stubAPIKeyRepository) GetAPIKey(context.Context, uint32) (*repository.APIKey, error) {
+	return nil, errStubRepository
+}
+
+func (stubAPIKeyRepository) ListAPIKeys(context.Context) ([]*repository.APIKey, error) {
+	return nil, errStubRepository
+}
+
+type stubNotificationRepository struct {
+	repository.NotificationRepository
+}
+
+func (stubNotificationRepository) GetNotificationPreferences(context.Context, uint32) (*repository.NotificationPreferences, error) {
+	return nil, errStubRepository
+}
+
+func (stubNotificationRepository) ListUserNotifications(context.Context, repository.NotificationFilter) ([]*repository.Notification, error) {
+	return nil, errStubRepository
+}
+
+func (stubNotificationRepository) MarkAllNotificationsRead(context.Context, uint32) error {
+	return errStubRepository
+}
+
+func (stubNotificationRepository) MarkNotificationRead(context.Context, uint32, uint32) error {
+	return errStubRepository
+}
+
+type stubCommentRepository struct {
+	repository.CommentRepository
+}
+
+func (stubCommentRepository) GetComment(context.Context, uint32) (*repository.Comment, error) {
+	return nil, errStubRepository
+}
+
+func (stubCommentRepository) ListBlogComments(context.Context, uint32) ([]*repository.Comment, error) {
+	return nil, errStubRepository
+}
+
+func (stubCommentRepository) ListCommentsByStatus(context.Context, string, int32, int32) ([]*repository.Comment, error) {
+	return nil, errStubRepository
+}
+
+type stubNewsletterRepository struct {
+	repository.NewsletterRepository
+}
+
+func (stubNewsletterRepository) GetCampaign(context.Context, uint32) (*repository.Campaign, error) {
+	return nil, errStubRepository
+}
+
+func (stubNewsletterRepository) GetCampaignStats(context.Context, uint32) (*repository.CampaignStats, error) {
+	return nil, errStubRepository
+}
+
+func (stubNewsletterRepository) ListCampaignDeliveries(context.Context, uint32, string, int32, int32) ([]*repository.Delivery, error) {
+	return nil, errStubRepository
+}
+
+func (stubNewsletterRepository) ListCampaigns(context.Context, int32, int32) ([]*repository.Campaign, error) {
+	return nil, errStubRepository
+}
+
+type stubEmailOutboxRepository struct {
+	repository.EmailOutboxRepository
+}
+
+// stubRepositories stands in for the database. A handler only calls the
+// methods stubbed above for requests without a body, a new call panics and
+// needs a stub.
+func stubRepositories() MySQLRepository {
+	return MySQLRepository{
+		u:          stubUserRepository{},
+		p:          stubProductRepository{},
+		cart:       stubCartRepository{},
+		o:          stubOrderRepository{},
+		cate:       stubCategoryRepository{},
+		r:          stubReviewRepository{},
+		b:          stubBlogRepository{},
+		tf:         stubTwoFactorRepository{},
+		role:       stubRoleRepository{},
+		sec:        stubSecurityRepository{},
+		oauth:      stubOAuthRepository{},
+		addr:       stubAddressRepository{},
+		audit:      stubAuditRepository{},
+		apiKey:     stubAPIKeyRepository{},
+		notif:      stubNotificationRepository{},
+		comment:    stubCommentRepository{},
+		newsletter: stubNewsletterRepository{},
+		outbox:     stubEmailOutboxRepository{},
+	}
+}
+
+// recordReached notes whether a request got to its route handler. Only the
+// guards in middleware.go abort a request, a request that was not aborted ran
+// its whole chain, the handler included.
+func recordReached(reached *bool) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		*reached = !ctx.IsAborted()
+	}
+}
+
+// streamRecorder lets the SSE handlers open their stream, gin needs the
+// response writer to be a CloseNotifier.
+type streamRecorder struct {
+	*httptest.ResponseRecorder
+}
+
+func (streamRecorder) CloseNotify() <-chan bool {
+	return make(chan bool)
+}
+
+func newRouteTestServer(t *testing.T, reached *bool) *HttpServer {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+
+	// setRoutes serves the swagger files from statik, give it an empty site
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	if _, err := zw.Create("index.html"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	fs.Register(buf.String())
+
+	maker, err := pkg.NewPaseto(strings.Repeat("k", 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewHttpServer(maker, pkg.Config{})
+
+	// SSE handlers return straight away on a closed stream
+	if err := s.stream.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s.repo = stubRepositories()
+
+	s.router = gin.New()
+	s.router.Use(recordReached(reached))
+	s.setRoutes()
+
+	return s
+}
+
+func routeTestPath(path string) string {
+	replacer := strings.NewReplacer(
+		"*filepath", "",
+		":id", "1",
+		":addressId", "1",
+		":reviewId", "1",
+		":notificationId", "1",
+		":blogId", "1",
+		":commentId", "1",
+		":orderId", "1",
+		":name", "USER",
+		":tag", "crochet",
+		":slug", "crochet",
+	)
+
+	return replacer.Replace(path)
+}
+
+func TestRouteAccessTableCoversRoutes(t *testing.T) {
+	s := newRouteTestServer(t, new(bool))
+
+	listed := map[string]bool{}
+	for _, route := range routeAccessTable {
+		listed[route.method+" "+route.path] = true
+	}
+
+	registered := map[string]bool{}
+	for _, route := range s.router.Routes() {
+		key := route.Method + " " + route.Path
+		registered[key] = true
+
+		if !listed[key] {
+			t.Errorf("%s is not in routeAccessTable", key)
+		}
+	}
+
+	for key := range listed {
+		if !registered[key] {
+			t.Errorf("%s is in routeAccessTable but not registered", key)
+		}
+	}
+}
+
+func TestRouteAccess(t *testing.T) {
+	var reached bool
+	s := newRouteTestServer(t, &reached)
+
+	callers := []testCaller{callerAnonymous, callerOwner, callerOther, callerAdmin}
+
+	for _, route := range routeAccessTable {
+		for _, caller := range callers {
+			t.Run(route.method+" "+route.path+" as "+caller.name, func(t *testing.T) {
+				req := httptest.NewRequest(route.method, routeTestPath(route.path), nil)
+
+				if caller != callerAnonymous {
+					token, err := s.tokenMaker.CreateToken(caller.userID, caller.name+"@example.com", caller.role, pkg.TokenTypeAccess, time.Minute)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					req.Header.Set(authorizationHeaderKey, "Bearer "+token)
+				}
+
+				reached = false
+				rec := streamRecorder{httptest.NewRecorder()}
+				s.router.ServeHTTP(rec, req)
+
+				switch {
+				case !route.access.allows(caller) && caller == callerAnonymous:
+					if reached || rec.Code != http.StatusUnauthorized {
+						t.Fatalf("got %d and reached %v, want %d from a guard", rec.Code, reached, http.StatusUnauthorized)
+					}
+				case !route.access.allows(caller):
+					if reached || rec.Code != http.StatusForbidden {
+						t.Fatalf("got %d and reached %v, want %d from a guard", rec.Code, reached, http.StatusForbidden)
+					}
+				case !reached:
+					t.Fatalf("a guard turned the request away with %d: %s", rec.Code, rec.Body.String())
+				case rejectedRouteStatus[route.method+" "+route.path] != 0:
+					if want := rejectedRouteStatus[route.method+" "+route.path]; rec.Code != want {
+						t.Fatalf("got %d, want %d from the handler", rec.Code, want)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestRouteAccessUsesAccountRole(t *testing.T) {
+	s := newRouteTestServer(t, new(bool))
+
+	// a token minted before the account was demoted to USER
+	token, err := s.tokenMaker.CreateToken(callerOther.userID, "demoted@example.com", "ADMIN", pkg.TokenTypeAccess, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/", nil)
+	req.Header.Set(authorizationHeaderKey, "Bearer "+token)
+
+	rec := streamRecorder{httptest.NewRecorder()}
+	s.router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("got %d, want %d: %s", rec.Code, http.StatusForbidden, rec.Body.String())
+	}
+}
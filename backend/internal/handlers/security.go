@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+// returned for every failed login so callers cannot tell unknown emails from wrong passwords
+var errInvalidCredentials = pkg.Errorf(pkg.AUTHENTICATION_ERROR, "invalid email or password")
+
+var (
+	dummyPasswordHashOnce sync.Once
+	dummyPasswordHash     string
+)
+
+// comparePasswordForUnknownUser burns the same bcrypt time a real comparison
+// would so response times do not reveal whether an email is registered.
+func (s *HttpServer) comparePasswordForUnknownUser(password string) {
+	dummyPasswordHashOnce.Do(func() {
+		dummyPasswordHash, _ = pkg.GenerateHashPassword("not-a-real-password", s.config.PASSWORD_COST)
+	})
+
+	_ = pkg.ComparePasswordAndHash(dummyPasswordHash, password)
+}
+
+func (s *HttpServer) loginPolicy(scope string) repository.LoginPolicy {
+	policy := repository.LoginPolicy{
+		MaxAttempts: s.config.LOGIN_MAX_ATTEMPTS,
+		Window:      s.config.LOGIN_ATTEMPT_WINDOW,
+		Lockout:     s.config.LOGIN_LOCKOUT_DURATION,
+		MaxLockout:  s.config.LOGIN_MAX_LOCKOUT_DURATION,
+	}
+
+	if scope == repository.LoginScopeIP {
+		policy.MaxAttempts = s.config.LOGIN_MAX_IP_ATTEMPTS
+	}
+
+	return policy
+}
+
+func loginIdentifiers(email string, ip string) map[string]string {
+	return map[string]string{
+		repository.LoginScopeEmail: strings.ToLower(strings.TrimSpace(email)),
+		repository.LoginScopeIP:    ip,
+	}
+}
+
+// checkLoginAllowed aborts the request with 429 when either the email or the
+// client ip is locked out.
+func (s *HttpServer) checkLoginAllowed(ctx *gin.Context, email string) bool {
+	now := time.Now()
+
+	var lockedUntil time.Time
+
+	for scope, identifier := range loginIdentifiers(email, ctx.ClientIP()) {
+		attempt, err := s.repo.sec.GetLoginAttempt(ctx, scope, identifier)
+		if err != nil {
+			if pkg.ErrorCode(err) == pkg.NOT_FOUND_ERROR {
+				continue
+			}
+
+			ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+			return false
+		}
+
+		if attempt.Locked(now) && attempt.LockedUntil.After(lockedUntil) {
+			lockedUntil = *attempt.LockedUntil
+		}
+	}
+
+	if lockedUntil.IsZero() {
+		return true
+	}
+
+	s.logSecurityEvent(ctx, &repository.SecurityEvent{
+		Event:   repository.EventLoginBlocked,
+		Email:   strings.ToLower(strings.TrimSpace(email)),
+		Details: fmt.Sprintf("locked until %s", lockedUntil.UTC().Format(time.RFC3339)),
+	})
+
+	ctx.Header("Retry-After", strconv.Itoa(int(time.Until(lockedUntil).Seconds())+1))
+	ctx.JSON(http.StatusTooManyRequests, errorResponse(pkg.Errorf(pkg.TOO_MANY_REQUESTS_ERROR, "too many failed login attempts, try again later")))
+
+	return false
+}
+
+// loginFailed counts the failure against the email and the client ip, logs it
+// and responds with the uniform invalid credentials error.
+func (s *HttpServer) loginFailed(ctx *gin.Context, email string, userID *uint32, reason string) {
+	for scope, identifier := range loginIdentifiers(email, ctx.ClientIP()) {
+		attempt, err := s.repo.sec.RecordFailedLogin(ctx, scope, identifier, s.loginPolicy(scope))
+		if err != nil {
+			ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+			return
+		}
+
+		if attempt.Locked(time.Now()) {
+			s.logSecurityEvent(ctx, &repository.SecurityEvent{
+				Event:   repository.EventAccountLocked,
+				UserID:  userID,
+				Email:   strings.ToLower(strings.TrimSpace(email)),
+				Details: fmt.Sprintf("%s %s locked after %d failed attempts until %s", scope, identifier, attempt.FailedAttempts, attempt.LockedUntil.UTC().Format(time.RFC3339)),
+			})
+		}
+	}
+
+	s.logSecurityEvent(ctx, &repository.SecurityEvent{
+		Event:   repository.EventLoginFailed,
+		UserID:  userID,
+		Email:   strings.ToLower(strings.TrimSpace(email)),
+		Details: reason,
+	})
+
+	ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidCredentials))
+}
+
+// loginSucceeded resets the email counter once every factor has been checked.
+// The ip counter is left to expire so one valid account cannot reset it.
+func (s *HttpServer) loginSucceeded(ctx *gin.Context, user *repository.User) error {
+	email := strings.ToLower(strings.TrimSpace(user.Email))
+
+	if err := s.repo.sec.ClearLoginAttempts(ctx, repository.LoginScopeEmail, email); err != nil {
+		return err
+	}
+
+	s.logSecurityEvent(ctx, &repository.SecurityEvent{
+		Event:  repository.EventLoginSucceeded,
+		UserID: &user.ID,
+		Email:  email,
+	})
+
+	return nil
+}
+
+// logSecurityEvent never fails the request, a lost event is only logged.
+func (s *HttpServer) logSecurityEvent(ctx *gin.Context, event *repository.SecurityEvent) {
+	event.IPAddress = ctx.ClientIP()
+
+	if err := s.repo.sec.CreateSecurityEvent(ctx, event); err != nil {
+		log.Printf("failed to log security event %s: %v", event.Event, err)
+	}
+}
+
+func (s *HttpServer) listLockedLogins(ctx *gin.Context) {
+	attempts, err := s.repo.sec.ListLockedLogins(ctx)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, attempts)
+}
+
+type unlockLoginRequest struct {
+	Scope      string `binding:"required,oneof=EMAIL IP" json:"scope"`
+	Identifier string `binding:"required"                json:"identifier"`
+}
+
+func (s *HttpServer) unlockLogin(ctx *gin.Context) {
+	payload, err := getPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var req unlockLoginRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+
+		return
+	}
+
+	identifier := req.Identifier
+	if req.Scope == repository.LoginScopeEmail {
+		identifier = strings.ToLower(strings.TrimSpace(identifier))
+	}
+
+	if err := s.repo.sec.ClearLoginAttempts(ctx, req.Scope, identifier); err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	event := &repository.SecurityEvent{
+		Event:   repository.EventLoginUnlocked,
+		ActorID: &payload.UserID,
+		Details: fmt.Sprintf("%s %s unlocked", req.Scope, identifier),
+	}
+
+	if req.Scope == repository.LoginScopeEmail {
+		event.Email = identifier
+	}
+
+	s.logSecurityEvent(ctx, event)
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+func (s *HttpServer) listSecurityEvents(ctx *gin.Context) {
+	filter := repository.SecurityEventFilter{}
+
+	if event := ctx.Query("event"); event != "" {
+		filter.Event = pkg.StringPtr(strings.ToUpper(event))
+	}
+
+	if email := ctx.Query("email"); email != "" {
+		filter.Email = pkg.StringPtr(strings.ToLower(strings.TrimSpace(email)))
+	}
+
+	limit, offset, err := parsePagination(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	filter.Limit = limit
+	filter.Offset = offset
+
+	events, err := s.repo.sec.ListSecurityEvents(ctx, filter)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, events)
+}
@@ -2,14 +2,17 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"log"
 	"net"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/events"
 	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/mysql"
 	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/services"
 	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
 	"github.com/gin-gonic/gin"
 	"github.com/rakyll/statik/fs"
@@ -20,13 +23,24 @@ const (
 )
 
 type MySQLRepository struct {
-	u    repository.UserRepository
-	p    repository.ProductRepository
-	cart repository.CartRepository
-	o    repository.OrderRepository
-	cate repository.CategoryRepository
-	r    repository.ReviewRepository
-	b    repository.BlogRepository
+	u          repository.UserRepository
+	p          repository.ProductRepository
+	cart       repository.CartRepository
+	o          repository.OrderRepository
+	cate       repository.CategoryRepository
+	r          repository.ReviewRepository
+	b          repository.BlogRepository
+	tf         repository.TwoFactorRepository
+	role       repository.RoleRepository
+	sec        repository.SecurityRepository
+	oauth      repository.OAuthRepository
+	addr       repository.AddressRepository
+	audit      repository.AuditRepository
+	apiKey     repository.APIKeyRepository
+	notif      repository.NotificationRepository
+	comment    repository.CommentRepository
+	newsletter repository.NewsletterRepository
+	outbox     repository.EmailOutboxRepository
 }
 
 type HttpServer struct {
@@ -35,12 +49,44 @@ type HttpServer struct {
 	router     *gin.Engine
 	tokenMaker pkg.Maker
 	config     pkg.Config
+	oidc       *pkg.OIDCProvider // nil when sign in with a provider is not configured
+	templates  *services.Templates
+	sms        services.SMSSender
+	events     *events.Bus
+	stream     events.Stream // order events for the SSE endpoints
 
 	repo MySQLRepository
 }
 
 func NewHttpServer(maker pkg.Maker, config pkg.Config) *HttpServer {
 	router := gin.Default()
+	router.Use(requestIDMiddleware())
+
+	// ClientIP feeds the login lockout, the security events and the audit log, so
+	// X-Forwarded-For is only believed from the proxies we run
+	if err := router.SetTrustedProxies(pkg.SplitList(config.TRUSTED_PROXIES)); err != nil {
+		log.Fatalf("invalid TRUSTED_PROXIES: %v", err)
+	}
+
+	oidc, err := pkg.NewOIDCProvider(config)
+	if err != nil && !errors.Is(err, pkg.ErrOIDCNotConfigured) {
+		log.Fatalf("invalid oidc config: %v", err)
+	}
+
+	templates, err := services.NewTemplates(config.FEED_TITLE)
+	if err != nil {
+		log.Fatalf("invalid email templates: %v", err)
+	}
+
+	sms, err := services.NewSMSSender(config)
+	if err != nil {
+		log.Fatalf("invalid sms config: %v", err)
+	}
+
+	stream, err := events.NewStream(config)
+	if err != nil {
+		log.Fatalf("failed to create event stream: %v", err)
+	}
 
 	s := &HttpServer{
 		router: router,
@@ -51,6 +97,11 @@ func NewHttpServer(maker pkg.Maker, config pkg.Config) *HttpServer {
 		},
 		tokenMaker: maker,
 		config:     config,
+		oidc:       oidc,
+		templates:  templates,
+		sms:        sms,
+		events:     events.NewBus(),
+		stream:     stream,
 	}
 
 	s.setRoutes()
@@ -72,86 +123,197 @@ func (s *HttpServer) setRoutes() {
 
 	// routes groups
 	users := v1.Group("/users")
-	usersAuth := v1.Group("/users").Use(authMiddleware(s.tokenMaker))
+	usersAuth := v1.Group("/users").Use(s.authMiddleware())
 
 	products := v1.Group("/products")
-	productsAuth := v1.Group("/products").Use(authMiddleware(s.tokenMaker))
+	productsAuth := v1.Group("/products").Use(s.authMiddleware())
 
 	cart := v1.Group("/categories")
-	cartAuth := v1.Group("/categories").Use(authMiddleware(s.tokenMaker))
+	cartAuth := v1.Group("/categories").Use(s.authMiddleware())
 
 	reviews := v1.Group("/reviews")
-	reviewsAuth := v1.Group("/reviews").Use(authMiddleware(s.tokenMaker))
+	reviewsAuth := v1.Group("/reviews").Use(s.authMiddleware())
 
-	ordersAuth := v1.Group("/orders").Use(authMiddleware(s.tokenMaker))
+	ordersAuth := v1.Group("/orders").Use(s.authMiddleware())
 
 	blogs := v1.Group("/blogs")
+	blogsAuth := v1.Group("/blogs").Use(s.authMiddleware())
+
+	commentsAuth := v1.Group("/comments").Use(s.authMiddleware(), s.requirePermission(permCommentsModerate))
+
+	newsletter := v1.Group("/newsletter")
+	newsletterAuth := v1.Group("/newsletter").Use(s.authMiddleware(), s.requirePermission(permNewsletterSend))
+
+	cartsAuth := v1.Group("/carts").Use(s.authMiddleware())
 
-	cartsAuth := v1.Group("/carts").Use(authMiddleware(s.tokenMaker))
+	rolesAuth := v1.Group("/roles").Use(s.authMiddleware(), s.requirePermission(permRolesManage))
+
+	securityAuth := v1.Group("/security").Use(s.authMiddleware(), s.requirePermission(permSecurityManage))
+
+	auditAuth := v1.Group("/audit").Use(s.authMiddleware(), s.requirePermission(permAuditRead))
+
+	apiKeysAuth := v1.Group("/api-keys").Use(s.authMiddleware(), s.requirePermission(permAPIKeysManage))
 
 	s.router.GET("/health", s.healthCheckHandler)
+	s.router.GET("/.well-known/jwks.json", s.jwks)
 
 	// users routes
-	usersAuth.GET("/", s.listUsers)
+	usersAuth.GET("/", s.requirePermission(permUsersRead), s.listUsers)
 	users.POST("/register", s.createUser)
-	usersAuth.GET("/:id", s.getUser)
+	usersAuth.GET("/:id", s.requireOwner(permUsersRead), s.getUser)
+	usersAuth.DELETE("/:id", s.requireOwner(), s.deleteAccount)
+	usersAuth.GET("/:id/export", s.requireOwner(), s.exportUserData)
 	users.POST("/login", s.loginUser)
+	users.POST("/login/2fa", s.loginTwoFactor)
+	users.GET("/oidc/authorize", s.oidcAuthorize)
+	users.POST("/oidc/callback", s.oidcCallback)
 	users.GET("/:id/refresh-token", s.refreshToken)
 	users.POST("/reset-password", s.resetPassword)
-	usersAuth.PUT("/:id/update-subscription", s.updateUserSubscription)
-	usersAuth.PUT("/:id/update-role", s.updateUserRole)
-
-	usersAuth.GET("/:id/reviews", s.listUsersReviews)
-
-	usersAuth.POST("/:id/blogs", s.createBlog)
+	users.POST("/reset-password/confirm", s.confirmPasswordReset)
+	usersAuth.POST("/invite", s.requirePermission(permUsersManage), s.inviteUser)
+	usersAuth.PUT("/:id/update-subscription", s.requireOwner(), s.updateUserSubscription)
+	usersAuth.PUT("/:id/profile", s.requireOwner(), s.updateUserProfile)
+	usersAuth.PUT("/:id/update-role", s.requirePermission(permUsersManage), s.updateUserRole)
+	usersAuth.GET("/:id/overview", s.requirePermission(permUsersRead), s.getUserOverview)
+	usersAuth.PUT("/:id/disable", s.requirePermission(permUsersManage), s.disableUser)
+	usersAuth.PUT("/:id/enable", s.requirePermission(permUsersManage), s.enableUser)
+	usersAuth.POST("/:id/force-password-reset", s.requirePermission(permUsersManage), s.forcePasswordReset)
+
+	usersAuth.POST("/:id/2fa/enrol", s.requireOwner(), s.enrolTwoFactor)
+	usersAuth.POST("/:id/2fa/verify", s.requireOwner(), s.verifyTwoFactor)
+	usersAuth.POST("/:id/2fa/recovery-codes", s.requireOwner(), s.regenerateRecoveryCodes)
+	usersAuth.DELETE("/:id/2fa", s.requireOwner(), s.disableTwoFactor)
+
+	usersAuth.GET("/:id/identities", s.requireOwner(permUsersRead), s.listUserIdentities)
+
+	usersAuth.GET("/:id/addresses", s.requireOwner(permUsersRead), s.listUserAddresses)
+	usersAuth.POST("/:id/addresses", s.requireOwner(), s.createAddress)
+	usersAuth.GET("/:id/addresses/:addressId", s.requireOwner(permUsersRead), s.getAddress)
+	usersAuth.PUT("/:id/addresses/:addressId", s.requireOwner(), s.updateAddress)
+	usersAuth.PUT("/:id/addresses/:addressId/default", s.requireOwner(), s.setDefaultAddress)
+	usersAuth.DELETE("/:id/addresses/:addressId", s.requireOwner(), s.deleteAddress)
+
+	usersAuth.GET("/:id/reviews", s.requireOwner(permUsersRead), s.listUsersReviews)
+	usersAuth.PUT("/:id/reviews/:reviewId", s.requireOwner(), s.updateUserReview)
+	usersAuth.DELETE("/:id/reviews/:reviewId", s.requireOwner(), s.deleteUserReview)
+
+	usersAuth.GET("/:id/notifications", s.requireOwner(), s.listUserNotifications)
+	usersAuth.PUT("/:id/notifications/read", s.requireOwner(), s.markAllNotificationsRead)
+	usersAuth.PUT("/:id/notifications/:notificationId/read", s.requireOwner(), s.markNotificationRead)
+	usersAuth.GET("/:id/notification-preferences", s.requireOwner(), s.getNotificationPreferences)
+	usersAuth.PUT("/:id/notification-preferences", s.requireOwner(), s.updateNotificationPreferences)
+
+	usersAuth.POST("/:id/blogs", s.requireOwner(), s.requirePermission(permBlogsPublish), s.createBlog)
 	users.GET("/:id/blogs", s.getBlogsByAuthor)
-	usersAuth.DELETE("/:id/blogs/:blogId", s.deleteBlog)
-	usersAuth.PUT("/:id/blogs/:blogId", s.updateBlog)
+	usersAuth.GET("/:id/blogs/editor", s.requireOwner(), s.requirePermission(permBlogsPublish), s.listAuthorBlogs)
+	usersAuth.DELETE("/:id/blogs/:blogId", s.requireOwner(), s.requirePermission(permBlogsPublish), s.deleteBlog)
+	usersAuth.PUT("/:id/blogs/:blogId", s.requireOwner(), s.requirePermission(permBlogsPublish), s.updateBlog)
 
-	usersAuth.GET("/:id/cart", s.getCart)
-	usersAuth.PUT("/:id/cart", s.updateCart)
-	usersAuth.POST("/:id/cart", s.createCart)
-	usersAuth.DELETE("/:id/cart", s.deleteCart)
+	usersAuth.GET("/:id/cart", s.requireOwner(permCartsRead), s.getCart)
+	usersAuth.PUT("/:id/cart", s.requireOwner(), s.updateCart)
+	usersAuth.POST("/:id/cart", s.requireOwner(), s.createCart)
+	usersAuth.DELETE("/:id/cart", s.requireOwner(), s.deleteCart)
 
-	usersAuth.GET("/:id/orders", s.listUserOrders)
-	usersAuth.POST("/:id/orders", s.createOrder)
-	usersAuth.GET("/:id/orders/:orderId", s.getOrder)
+	usersAuth.GET("/:id/orders", s.requireOwner(permOrdersRead), s.listUserOrders)
+	usersAuth.POST("/:id/orders", s.requireOwner(), s.createOrder)
+	usersAuth.GET("/:id/orders/events", s.requireOwner(permOrdersRead), s.streamUserOrderEvents)
+	usersAuth.GET("/:id/orders/:orderId", s.requireOwner(permOrdersRead), s.getOrder)
 
 	// product routes
-	products.GET("/", s.listProducts) // use query params
-	productsAuth.POST("/create-product", s.createProduct)
+	products.GET("/", s.listProducts) // use query params, ?type=new|seasonal|featured|discounted&sort=rating
+	productsAuth.POST("/create-product", s.requirePermission(permProductsWrite), s.createProduct)
+	products.GET("/new/feed.rss", s.newProductsRSSFeed)
+	products.GET("/new/feed.atom", s.newProductsAtomFeed)
 	products.GET("/:id", s.getProduct)
-	productsAuth.PUT("/:id", s.updateProduct)
-	productsAuth.PUT("/:id/stock", s.updateProductQuantity)
-	productsAuth.DELETE("/:id", s.deleteProduct)
+	productsAuth.PUT("/:id", s.requirePermission(permProductsWrite), s.updateProduct)
+	productsAuth.PUT("/:id/stock", s.requirePermission(permStockWrite), s.updateProductQuantity)
+	productsAuth.DELETE("/:id", s.requirePermission(permProductsWrite), s.deleteProduct)
 
-	productsAuth.POST("/:id/reviews", s.createReview)
-	products.GET("/:id/reviews", s.listProductsReviews)
+	productsAuth.POST("/:id/reviews", denyAPIKeys(), s.createReview)
+	products.GET("/:id/reviews", s.listProductsReviews) // ?sort=newest|helpful
 
 	// categories routes
 	cart.GET("/", s.listCategories)
-	cartAuth.POST("/create-category", s.createCategory)
+	cartAuth.POST("/create-category", s.requirePermission(permCategoriesWrite), s.createCategory)
 	cart.GET("/:id", s.getCategory)
-	cartAuth.PUT("/:id", s.updateCategory)
-	cartAuth.DELETE("/:id", s.deleteCategory)
+	cartAuth.PUT("/:id", s.requirePermission(permCategoriesWrite), s.updateCategory)
+	cartAuth.DELETE("/:id", s.requirePermission(permCategoriesWrite), s.deleteCategory)
 
 	// reviews routes
 	reviews.GET("/", s.listReviews)
 	reviews.GET("/:id", s.getReview)
-	reviewsAuth.DELETE("/:id", s.deleteReview)
+	reviewsAuth.GET("/moderation", s.requirePermission(permReviewsModerate), s.listReviewModerationQueue)
+	reviewsAuth.PUT("/:id/approve", s.requirePermission(permReviewsModerate), s.approveReview)
+	reviewsAuth.PUT("/:id/reject", s.requirePermission(permReviewsModerate), s.rejectReview)
+	reviewsAuth.DELETE("/:id", s.requirePermission(permReviewsModerate), s.deleteReview)
+	reviewsAuth.POST("/:id/helpful", denyAPIKeys(), s.voteReviewHelpful)
+	reviewsAuth.DELETE("/:id/helpful", denyAPIKeys(), s.removeReviewVote)
+	reviewsAuth.PUT("/:id/reply", s.requirePermission(permReviewsModerate), s.replyToReview)
+	reviewsAuth.DELETE("/:id/reply", s.requirePermission(permReviewsModerate), s.deleteReviewReply)
 
 	// blogs route
 	blogs.GET("/", s.listBlogs)
+	blogs.GET("/tags", s.listBlogTags)
+	blogs.GET("/feed.rss", s.blogsRSSFeed)
+	blogs.GET("/feed.atom", s.blogsAtomFeed)
+	blogs.GET("/tags/:tag/feed.rss", s.blogTagRSSFeed)
+	blogs.GET("/tags/:tag/feed.atom", s.blogTagAtomFeed)
 	blogs.GET("/:blogId", s.getBlog)
+	blogs.GET("/slug/:slug", s.getBlogBySlug)
+	blogs.GET("/:blogId/comments", s.listBlogComments)
+	blogsAuth.POST("/:blogId/comments", denyAPIKeys(), s.createBlogComment)
+	blogsAuth.PUT("/:blogId/comments/:commentId", denyAPIKeys(), s.updateBlogComment)
+	blogsAuth.DELETE("/:blogId/comments/:commentId", denyAPIKeys(), s.deleteBlogComment)
+
+	// comments moderation
+	commentsAuth.GET("/moderation", s.listCommentModerationQueue)
+	commentsAuth.PUT("/:id/approve", s.approveComment)
+	commentsAuth.PUT("/:id/hide", s.hideComment)
+
+	// newsletter
+	newsletter.POST("/subscribe", s.subscribeNewsletter)
+	newsletter.GET("/confirm", s.confirmNewsletterSubscription)
+	newsletter.GET("/unsubscribe", s.unsubscribeNewsletter)
+	newsletter.POST("/unsubscribe", s.unsubscribeNewsletter)
+	newsletterAuth.GET("/campaigns", s.listCampaigns)
+	newsletterAuth.POST("/campaigns", s.createCampaign)
+	newsletterAuth.GET("/campaigns/:id", s.getCampaign)
+	newsletterAuth.GET("/campaigns/:id/stats", s.getCampaignStats)
+	newsletterAuth.GET("/campaigns/:id/deliveries", s.listCampaignDeliveries)
 
 	// carts route
-	cartsAuth.GET("/", s.listCarts)
+	cartsAuth.GET("/", s.requirePermission(permCartsRead), s.listCarts)
 
 	// orders
-	ordersAuth.GET("/", s.listOrders)
-	ordersAuth.GET("/status", s.listOrderWithStatus)
-	ordersAuth.PUT("/:id", s.updateOrderStatus) // put
-	ordersAuth.DELETE("/:id", s.deleteOrder)
+	ordersAuth.GET("/", s.requirePermission(permOrdersRead), s.listOrders)
+	ordersAuth.GET("/status", s.requirePermission(permOrdersRead), s.listOrderWithStatus)
+	ordersAuth.GET("/events", s.requirePermission(permOrdersRead), s.streamOrderEvents)
+	ordersAuth.PUT("/:id", s.requirePermission(permOrdersFulfil), s.updateOrderStatus) // put
+	ordersAuth.DELETE("/:id", s.requirePermission(permOrdersDelete), s.deleteOrder)
+	ordersAuth.GET("/:id/payments", s.requirePermission(permOrdersRead), s.listOrderPayments)
+	ordersAuth.POST("/:id/payments", s.requirePermission(permOrdersFulfil), s.recordOrderPayment)
+
+	// roles
+	rolesAuth.GET("/", s.listRoles)
+	rolesAuth.POST("/", s.createRole)
+	rolesAuth.GET("/permissions", s.listPermissions)
+	rolesAuth.GET("/:name", s.getRole)
+	rolesAuth.PUT("/:name/permissions", s.updateRolePermissions)
+	rolesAuth.DELETE("/:name", s.deleteRole)
+
+	// security
+	securityAuth.GET("/lockouts", s.listLockedLogins)
+	securityAuth.POST("/lockouts/unlock", s.unlockLogin)
+	securityAuth.GET("/events", s.listSecurityEvents)
+
+	// audit
+	auditAuth.GET("/", s.listAuditLog)
+
+	// api keys
+	apiKeysAuth.GET("/", s.listAPIKeys)
+	apiKeysAuth.POST("/", s.createAPIKey)
+	apiKeysAuth.GET("/:id", s.getAPIKey)
+	apiKeysAuth.DELETE("/:id", s.revokeAPIKey)
 }
 
 func (s *HttpServer) healthCheckHandler(c *gin.Context) {
@@ -160,6 +322,20 @@ func (s *HttpServer) healthCheckHandler(c *gin.Context) {
 	})
 }
 
+// jwks publishes the keys our tokens are signed with so other services can
+// verify them. Symmetric tokens have nothing to publish.
+func (s *HttpServer) jwks(c *gin.Context) {
+	publisher, ok := s.tokenMaker.(pkg.KeyPublisher)
+	if !ok {
+		c.JSON(http.StatusNotFound, errorResponse(pkg.Errorf(pkg.NOT_FOUND_ERROR, "tokens are not signed with public keys")))
+
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, publisher.JWKS())
+}
+
 func (s *HttpServer) Start() error {
 	var err error
 	if s.ln, err = net.Listen("tcp", s.config.HTTP_PORT); err != nil {
@@ -179,6 +355,11 @@ func (s *HttpServer) Start() error {
 func (s *HttpServer) Close() error {
 	log.Println("Shutting down http server...")
 
+	// SSE requests only return once their subscription ends
+	if err := s.stream.Close(); err != nil {
+		log.Printf("failed to close event stream: %v", err)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
 	defer cancel()
 
@@ -187,14 +368,28 @@ func (s *HttpServer) Close() error {
 
 func (s *HttpServer) SetDependencies(store *mysql.Store) {
 	s.repo = MySQLRepository{
-		u:    mysql.NewUserRepository(store),
-		p:    mysql.NewProductRepository(store),
-		cart: mysql.NewCartRepository(store),
-		o:    mysql.NewOrderRepository(store),
-		cate: mysql.NewCategoryRepository(store),
-		r:    mysql.NewReviewRepository(store),
-		b:    mysql.NewBlogRepository(store),
+		u:          mysql.NewUserRepository(store),
+		p:          mysql.NewProductRepository(store),
+		cart:       mysql.NewCartRepository(store),
+		o:          mysql.NewOrderRepository(store),
+		cate:       mysql.NewCategoryRepository(store),
+		r:          mysql.NewReviewRepository(store),
+		b:          mysql.NewBlogRepository(store),
+		tf:         mysql.NewTwoFactorRepository(store),
+		role:       mysql.NewRoleRepository(store),
+		sec:        mysql.NewSecurityRepository(store),
+		oauth:      mysql.NewOAuthRepository(store),
+		addr:       mysql.NewAddressRepository(store),
+		audit:      mysql.NewAuditRepository(store),
+		apiKey:     mysql.NewAPIKeyRepository(store),
+		notif:      mysql.NewNotificationRepository(store),
+		comment:    mysql.NewCommentRepository(store),
+		newsletter: mysql.NewNewsletterRepository(store),
+		outbox:     mysql.NewEmailOutboxRepository(store),
 	}
+
+	s.events.Subscribe(s.notifyOrderEvent)
+	s.events.Subscribe(s.publishOrderEvent)
 }
 
 func (s *HttpServer) Port() int {
@@ -241,10 +436,17 @@ func getPayload(ctx *gin.Context) (*pkg.Payload, error) {
 	return p, nil
 }
 
-func isAdmin(payload *pkg.Payload) (bool, error) {
-	if payload.Role != "ADMIN" {
-		return false, pkg.Errorf(pkg.INVALID_ERROR, "not enough role to perform this action")
+func (s *HttpServer) hasPermission(ctx *gin.Context, payload *pkg.Payload, permission string) (bool, error) {
+	if payload.Role == apiKeyRole {
+		caller, ok := ctx.Value(apiKeyKey).(*apiKeyCaller)
+		if !ok || !caller.key.HasScope(permission) {
+			return false, nil
+		}
+
+		// a key never grants more than its owner currently holds
+		return s.repo.role.RoleHasPermission(ctx, caller.ownerRole, permission)
 	}
 
-	return true, nil
+	// authMiddleware has replaced the role of the token with the current one
+	return s.repo.role.RoleHasPermission(ctx, payload.Role, permission)
 }
@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// role set on the short lived token returned by loginUser when a second factor is needed.
+	// authMiddleware rejects it so it can only be exchanged at /users/login/2fa.
+	twoFactorChallengeRole = "2FA_CHALLENGE"
+
+	recoveryCodesCount = 10
+)
+
+type enrolTwoFactorResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauth_uri"`
+}
+
+func (s *HttpServer) enrolTwoFactor(ctx *gin.Context) {
+	payload, err := getPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	id, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	secret, err := pkg.GenerateTOTPSecret()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "%v", err)))
+
+		return
+	}
+
+	if err := s.repo.tf.CreateTwoFactor(ctx, id, secret); err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, enrolTwoFactorResponse{
+		Secret:     secret,
+		OtpauthURI: pkg.TOTPAuthURI(s.config.TWO_FACTOR_ISSUER, payload.Email, secret),
+	})
+}
+
+type twoFactorCodeRequest struct {
+	Code string `binding:"required" json:"code"`
+}
+
+type recoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+func (s *HttpServer) verifyTwoFactor(ctx *gin.Context) {
+	id, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var req twoFactorCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+
+		return
+	}
+
+	recoveryCodes, err := pkg.GenerateRecoveryCodes(recoveryCodesCount)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "%v", err)))
+
+		return
+	}
+
+	if err := s.repo.tf.EnableTwoFactor(ctx, id, req.Code, recoveryCodes); err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	// recovery codes are only stored hashed so this is the only time they are shown
+	ctx.JSON(http.StatusOK, recoveryCodesResponse{RecoveryCodes: recoveryCodes})
+}
+
+func (s *HttpServer) regenerateRecoveryCodes(ctx *gin.Context) {
+	id, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var req twoFactorCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+
+		return
+	}
+
+	if err := s.repo.tf.VerifyTwoFactorCode(ctx, id, req.Code); err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	recoveryCodes, err := pkg.GenerateRecoveryCodes(recoveryCodesCount)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(pkg.Errorf(pkg.INTERNAL_ERROR, "%v", err)))
+
+		return
+	}
+
+	if err := s.repo.tf.UpdateRecoveryCodes(ctx, id, recoveryCodes); err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, recoveryCodesResponse{RecoveryCodes: recoveryCodes})
+}
+
+type disableTwoFactorRequest struct {
+	Password     string `binding:"required" json:"password"`
+	Code         string `binding:""         json:"code"`
+	RecoveryCode string `binding:""         json:"recovery_code"`
+}
+
+func (s *HttpServer) disableTwoFactor(ctx *gin.Context) {
+	id, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var req disableTwoFactorRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+
+		return
+	}
+
+	user, err := s.repo.u.GetUserById(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	if s.config.REQUIRE_ADMIN_TWO_FACTOR && user.Role == "ADMIN" {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "two factor is required for admin accounts")))
+
+		return
+	}
+
+	if err := pkg.ComparePasswordAndHash(user.Password, req.Password); err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(pkg.Errorf(pkg.AUTHENTICATION_ERROR, "invalid password")))
+
+		return
+	}
+
+	if err := s.verifySecondFactor(ctx, id, req.Code, req.RecoveryCode); err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	if err := s.repo.tf.DeleteTwoFactor(ctx, id); err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+type loginTwoFactorRequest struct {
+	ChallengeToken string `binding:"required" json:"challenge_token"`
+	Code           string `binding:""         json:"code"`
+	RecoveryCode   string `binding:""         json:"recovery_code"`
+}
+
+func (s *HttpServer) loginTwoFactor(ctx *gin.Context) {
+	var req loginTwoFactorRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+
+		return
+	}
+
+	challenge, err := s.tokenMaker.VerifyToken(req.ChallengeToken)
+	if err != nil || challenge.Role != twoFactorChallengeRole {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(pkg.Errorf(pkg.AUTHENTICATION_ERROR, "invalid or expired challenge, kindly login")))
+
+		return
+	}
+
+	// second factor guesses count against the same lockout as passwords
+	if !s.checkLoginAllowed(ctx, challenge.Email) {
+		return
+	}
+
+	if err := s.verifySecondFactor(ctx, challenge.UserID, req.Code, req.RecoveryCode); err != nil {
+		if pkg.ErrorCode(err) != pkg.AUTHENTICATION_ERROR {
+			ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+			return
+		}
+
+		s.loginFailed(ctx, challenge.Email, &challenge.UserID, "wrong second factor: "+pkg.ErrorMessage(err))
+
+		return
+	}
+
+	user, err := s.repo.u.GetUserById(ctx, challenge.UserID)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	if err := checkAccountActive(user); err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	if err := s.loginSucceeded(ctx, user); err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	s.issueLoginTokens(ctx, user, user.Role)
+}
+
+// verifySecondFactor accepts either a totp code or a recovery code.
+func (s *HttpServer) verifySecondFactor(ctx *gin.Context, userID uint32, code string, recoveryCode string) error {
+	switch {
+	case code != "":
+		return s.repo.tf.VerifyTwoFactorCode(ctx, userID, code)
+	case recoveryCode != "":
+		return s.repo.tf.UseRecoveryCode(ctx, userID, recoveryCode)
+	default:
+		return pkg.Errorf(pkg.INVALID_ERROR, "code or recovery_code is required")
+	}
+}
+
+// twoFactorState reports whether the user has a second factor enabled and
+// the role their tokens should carry. Admins that have not enrolled while
+// REQUIRE_ADMIN_TWO_FACTOR is set only get USER access until they do.
+func (s *HttpServer) twoFactorState(ctx *gin.Context, user *repository.User) (bool, string, error) {
+	twoFactor, err := s.repo.tf.GetTwoFactor(ctx, user.ID)
+	if err != nil && pkg.ErrorCode(err) != pkg.NOT_FOUND_ERROR {
+		return false, "", err
+	}
+
+	enabled := twoFactor != nil && twoFactor.Enabled
+
+	if s.config.REQUIRE_ADMIN_TWO_FACTOR && user.Role == "ADMIN" && !enabled {
+		return false, "USER", nil
+	}
+
+	return enabled, user.Role, nil
+}
+
+// currentRole is the role the user acts with right now, the role of their
+// account downgraded as in twoFactorState. The two factor lookup is skipped
+// when it cannot change the role.
+func (s *HttpServer) currentRole(ctx *gin.Context, user *repository.User) (string, error) {
+	if !s.config.REQUIRE_ADMIN_TWO_FACTOR || user.Role != "ADMIN" {
+		return user.Role, nil
+	}
+
+	_, role, err := s.twoFactorState(ctx, user)
+
+	return role, err
+}
@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"crypto/subtle"
 	"encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/services"
 	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
 	"github.com/gin-gonic/gin"
 )
@@ -12,6 +15,8 @@ import (
 type userResponse struct {
 	ID           uint32 `json:"id"`
 	Email        string `json:"email"`
+	FullName     string `json:"full_name"`
+	PhoneNumber  string `json:"phone_number"`
 	Role         string `json:"role"`
 	Subscription bool   `json:"subscription"`
 }
@@ -54,7 +59,7 @@ func (s *HttpServer) createUser(ctx *gin.Context) {
 }
 
 type updateUserRoleRequest struct {
-	Role string `binding:"required,oneof=USER ADMIN" json:"role"`
+	Role string `binding:"required" json:"role"`
 }
 
 func (s *HttpServer) updateUserRole(ctx *gin.Context) {
@@ -65,12 +70,6 @@ func (s *HttpServer) updateUserRole(ctx *gin.Context) {
 		return
 	}
 
-	if isAdmin, err := isAdmin(payload); !isAdmin {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-
-		return
-	}
-
 	userId, err := getParam(ctx.Param("id"))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
@@ -92,12 +91,35 @@ func (s *HttpServer) updateUserRole(ctx *gin.Context) {
 		return
 	}
 
+	before, err := s.auditedUser(ctx, userId)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
 	if err := s.repo.u.UpdateUserRole(ctx, payload.UserID, userId, req.Role); err != nil {
 		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
 
 		return
 	}
 
+	after, err := s.auditedUser(ctx, userId)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	s.audit(ctx, repository.AuditUpdate, repository.AuditEntityUser, userId, before, after)
+
+	s.logSecurityEvent(ctx, &repository.SecurityEvent{
+		Event:   repository.EventRoleChanged,
+		UserID:  &userId,
+		ActorID: &payload.UserID,
+		Details: "role set to " + req.Role,
+	})
+
 	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
 }
 
@@ -119,6 +141,8 @@ func (s *HttpServer) getUser(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, userResponse{
 		ID:           user.ID,
 		Email:        user.Email,
+		FullName:     user.FullName,
+		PhoneNumber:  user.PhoneNumber,
 		Role:         user.Role,
 		Subscription: user.Subscription,
 	})
@@ -130,11 +154,19 @@ type loginUserRequest struct {
 }
 
 type loginUserResponse struct {
-	ID                       uint32 `json:"id"`
-	AccessToken              string `json:"access_token"`
-	RefreshToken             string `json:"refresh_token"`
-	AccessTokenExpiresAfter  int64  `json:"access_token_expires_after"`
-	RefreshTokenExpiresAfter int64  `json:"refresh_token_expires_after"`
+	ID                         uint32 `json:"id"`
+	AccessToken                string `json:"access_token"`
+	RefreshToken               string `json:"refresh_token"`
+	AccessTokenExpiresAfter    int64  `json:"access_token_expires_after"`
+	RefreshTokenExpiresAfter   int64  `json:"refresh_token_expires_after"`
+	TwoFactorEnrolmentRequired bool   `json:"two_factor_enrolment_required,omitempty"`
+}
+
+type twoFactorChallengeResponse struct {
+	ID                         uint32 `json:"id"`
+	TwoFactorRequired          bool   `json:"two_factor_required"`
+	ChallengeToken             string `json:"challenge_token"`
+	ChallengeTokenExpiresAfter int64  `json:"challenge_token_expires_after"`
 }
 
 func (s *HttpServer) loginUser(ctx *gin.Context) {
@@ -145,19 +177,78 @@ func (s *HttpServer) loginUser(ctx *gin.Context) {
 		return
 	}
 
+	if !s.checkLoginAllowed(ctx, req.Email) {
+		return
+	}
+
 	user, err := s.repo.u.GetUserByEmail(ctx, req.Email)
 	if err != nil {
-		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+		if pkg.ErrorCode(err) != pkg.NOT_FOUND_ERROR {
+			ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+			return
+		}
+
+		s.comparePasswordForUnknownUser(req.Password)
+		s.loginFailed(ctx, req.Email, nil, "unknown email")
 
 		return
 	}
 
 	if err := pkg.ComparePasswordAndHash(user.Password, req.Password); err != nil {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		s.loginFailed(ctx, req.Email, &user.ID, "wrong password")
 
 		return
 	}
 
+	s.completeLogin(ctx, user)
+}
+
+// completeLogin runs once the user has proven their first factor. It hands out
+// a two factor challenge when one is enabled, otherwise the login tokens.
+func (s *HttpServer) completeLogin(ctx *gin.Context, user *repository.User) {
+	if err := checkAccountActive(user); err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	twoFactorEnabled, role, err := s.twoFactorState(ctx, user)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	if twoFactorEnabled {
+		challengeToken, err := s.tokenMaker.CreateToken(user.ID, user.Email, twoFactorChallengeRole, pkg.TokenTypeAccess, s.config.TWO_FACTOR_CHALLENGE_DURATION)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, twoFactorChallengeResponse{
+			ID:                         user.ID,
+			TwoFactorRequired:          true,
+			ChallengeToken:             challengeToken,
+			ChallengeTokenExpiresAfter: int64(s.config.TWO_FACTOR_CHALLENGE_DURATION.Seconds()),
+		})
+
+		return
+	}
+
+	if err := s.loginSucceeded(ctx, user); err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	s.issueLoginTokens(ctx, user, role)
+}
+
+// issueLoginTokens rotates the users refresh token and responds with a new token pair.
+func (s *HttpServer) issueLoginTokens(ctx *gin.Context, user *repository.User, role string) {
 	refreshToken, err := s.repo.u.UpdateRefreshToken(ctx, user.ID)
 	if err != nil {
 		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
@@ -165,7 +256,7 @@ func (s *HttpServer) loginUser(ctx *gin.Context) {
 		return
 	}
 
-	accesstoken, err := s.tokenMaker.CreateToken(user.ID, user.Email, user.Role, s.config.TOKEN_DURATION)
+	accesstoken, err := s.tokenMaker.CreateToken(user.ID, user.Email, role, pkg.TokenTypeAccess, s.config.TOKEN_DURATION)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 
@@ -173,11 +264,12 @@ func (s *HttpServer) loginUser(ctx *gin.Context) {
 	}
 
 	ctx.JSON(http.StatusOK, loginUserResponse{
-		ID:                       user.ID,
-		AccessToken:              accesstoken,
-		RefreshToken:             refreshToken,
-		AccessTokenExpiresAfter:  int64(s.config.TOKEN_DURATION.Seconds()),
-		RefreshTokenExpiresAfter: int64(s.config.REFRESH_TOKEN_DURATION.Seconds()),
+		ID:                         user.ID,
+		AccessToken:                accesstoken,
+		RefreshToken:               refreshToken,
+		AccessTokenExpiresAfter:    int64(s.config.TOKEN_DURATION.Seconds()),
+		RefreshTokenExpiresAfter:   int64(s.config.REFRESH_TOKEN_DURATION.Seconds()),
+		TwoFactorEnrolmentRequired: role != user.Role,
 	})
 }
 
@@ -196,14 +288,36 @@ func (s *HttpServer) refreshToken(ctx *gin.Context) {
 		return
 	}
 
-	// check if the refresh token has expired
-	if _, err := s.tokenMaker.VerifyToken(user.RefreshToken); err != nil {
+	// the caller must hold the current refresh token, otherwise the user id
+	// alone would be enough to skip the password and second factor
+	fields := strings.Fields(ctx.GetHeader(authorizationHeaderKey))
+	if len(fields) != 2 || subtle.ConstantTimeCompare([]byte(fields[1]), []byte(user.RefreshToken)) != 1 {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(pkg.Errorf(pkg.AUTHENTICATION_ERROR, "invalid refresh token, kindly login")))
+
+		return
+	}
+
+	// check the refresh token has not expired or been revoked
+	if payload, err := s.tokenMaker.VerifyToken(user.RefreshToken); err != nil || payload.Type != pkg.TokenTypeRefresh || user.TokenRevoked(payload.CreatedAt) {
 		ctx.JSON(http.StatusUnauthorized, errorResponse(pkg.Errorf(pkg.AUTHENTICATION_ERROR, "refresh token expired, kindly login")))
 
 		return
 	}
 
-	accesstoken, err := s.tokenMaker.CreateToken(user.ID, user.Email, user.Role, s.config.TOKEN_DURATION)
+	if err := checkAccountActive(user); err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	_, role, err := s.twoFactorState(ctx, user)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	accesstoken, err := s.tokenMaker.CreateToken(user.ID, user.Email, role, pkg.TokenTypeAccess, s.config.TOKEN_DURATION)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 
@@ -211,11 +325,12 @@ func (s *HttpServer) refreshToken(ctx *gin.Context) {
 	}
 
 	ctx.JSON(http.StatusOK, loginUserResponse{
-		ID:                       user.ID,
-		AccessToken:              accesstoken,
-		RefreshToken:             user.RefreshToken,
-		AccessTokenExpiresAfter:  int64(s.config.TOKEN_DURATION.Seconds()),
-		RefreshTokenExpiresAfter: int64(s.config.REFRESH_TOKEN_DURATION.Seconds()),
+		ID:                         user.ID,
+		AccessToken:                accesstoken,
+		RefreshToken:               user.RefreshToken,
+		AccessTokenExpiresAfter:    int64(s.config.TOKEN_DURATION.Seconds()),
+		RefreshTokenExpiresAfter:   int64(s.config.REFRESH_TOKEN_DURATION.Seconds()),
+		TwoFactorEnrolmentRequired: role != user.Role,
 	})
 }
 
@@ -223,6 +338,8 @@ type resetPasswordRequest struct {
 	Email string `binding:"required" json:"email"`
 }
 
+// resetPassword emails a reset link, redeemed with confirmPasswordReset. The
+// response is the same for unknown emails so it does not reveal accounts.
 func (s *HttpServer) resetPassword(ctx *gin.Context) {
 	var req resetPasswordRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -231,50 +348,60 @@ func (s *HttpServer) resetPassword(ctx *gin.Context) {
 		return
 	}
 
-	// send password reset email
-	// email will have a token that will enable one to send the data
-	ctx.JSON(http.StatusNotImplemented, gin.H{})
-}
-
-func (s *HttpServer) listUsers(ctx *gin.Context) {
-	payload, err := getPayload(ctx)
+	user, err := s.repo.u.GetUserByEmail(ctx, strings.ToLower(strings.TrimSpace(req.Email)))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		if pkg.ErrorCode(err) == pkg.NOT_FOUND_ERROR {
+			ctx.JSON(http.StatusOK, gin.H{"status": "success"})
+
+			return
+		}
+
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
 
 		return
 	}
 
-	if isAdmin, err := isAdmin(payload); !isAdmin {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	if user.DeletedAt != nil || user.DisabledAt != nil {
+		ctx.JSON(http.StatusOK, gin.H{"status": "success"})
 
 		return
 	}
 
-	users, err := s.repo.u.ListUsers(ctx)
+	token, record, err := newPasswordToken(repository.PasswordTokenReset, user.ID, s.config.PASSWORD_RESET_DURATION)
 	if err != nil {
 		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
 
 		return
 	}
 
-	response := []userResponse{}
-	for _, user := range users {
-		response = append(response, userResponse{
-			ID:           user.ID,
-			Email:        user.Email,
-			Role:         user.Role,
-			Subscription: user.Subscription,
-		})
+	record.UserID = user.ID
+
+	if err := s.repo.u.CreatePasswordToken(ctx, record); err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
 	}
 
-	ctx.JSON(http.StatusOK, response)
+	s.queueEmail(ctx, user.Email, services.EmailPasswordReset, services.PasswordResetEmailData{
+		Link:      s.passwordTokenLink(token),
+		ExpiresIn: s.config.PASSWORD_RESET_DURATION,
+	})
+
+	s.logSecurityEvent(ctx, &repository.SecurityEvent{
+		Event:  repository.EventResetRequested,
+		UserID: &user.ID,
+		Email:  user.Email,
+	})
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
 }
 
-type updateUserSubscriptionRequestBody struct {
-	Subscription bool `binding:"required" json:"subscription"`
+type updateUserProfileRequest struct {
+	FullName    *string `binding:"omitempty,max=255" json:"full_name"`
+	PhoneNumber *string `binding:"omitempty,max=50"  json:"phone_number"`
 }
 
-func (s *HttpServer) updateUserSubscription(ctx *gin.Context) {
+func (s *HttpServer) updateUserProfile(ctx *gin.Context) {
 	payload, err := getPayload(ctx)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
@@ -289,8 +416,50 @@ func (s *HttpServer) updateUserSubscription(ctx *gin.Context) {
 		return
 	}
 
-	if id != payload.UserID {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(pkg.Errorf(pkg.AUTHENTICATION_ERROR, "unauthorized to this action")))
+	var req updateUserProfileRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(pkg.Errorf(pkg.INVALID_ERROR, "%v", err)))
+
+		return
+	}
+
+	err = s.repo.u.UpdateUserProfile(ctx, &repository.UpdateUserProfile{
+		ID:          id,
+		FullName:    req.FullName,
+		PhoneNumber: req.PhoneNumber,
+		UpdatedBy:   payload.UserID,
+	})
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	user, err := s.repo.u.GetUserById(ctx, id)
+	if err != nil {
+		ctx.JSON(pkg.PkgErrorToHttpError(err), errorResponse(err))
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, userResponse{
+		ID:           user.ID,
+		Email:        user.Email,
+		FullName:     user.FullName,
+		PhoneNumber:  user.PhoneNumber,
+		Role:         user.Role,
+		Subscription: user.Subscription,
+	})
+}
+
+type updateUserSubscriptionRequestBody struct {
+	Subscription bool `binding:"required" json:"subscription"`
+}
+
+func (s *HttpServer) updateUserSubscription(ctx *gin.Context) {
+	id, err := getParam(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 
 		return
 	}
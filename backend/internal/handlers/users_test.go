@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// loginUserRepository holds the one account the login tests sign in with.
+type loginUserRepository struct {
+	stubUserRepository
+	maker pkg.Maker
+	user  *repository.User
+}
+
+func (r *loginUserRepository) GetUserByEmail(ctx context.Context, email string) (*repository.User, error) {
+	if email != r.user.Email {
+		return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "no user found with email %s", email)
+	}
+
+	user := *r.user
+
+	return &user, nil
+}
+
+func (r *loginUserRepository) GetUserById(ctx context.Context, id uint32) (*repository.User, error) {
+	if id != r.user.ID {
+		return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "no user found with id %d", id)
+	}
+
+	user := *r.user
+
+	return &user, nil
+}
+
+// UpdateRefreshToken mints the refresh token like the mysql repository does,
+// with the role stored on the account.
+func (r *loginUserRepository) UpdateRefreshToken(ctx context.Context, id uint32) (string, error) {
+	token, err := r.maker.CreateToken(r.user.ID, r.user.Email, r.user.Role, pkg.TokenTypeRefresh, time.Hour)
+	if err != nil {
+		return "", err
+	}
+
+	r.user.RefreshToken = token
+
+	return token, nil
+}
+
+// loginSecurityRepository has no failed attempts on record.
+type loginSecurityRepository struct {
+	stubSecurityRepository
+}
+
+func (loginSecurityRepository) GetLoginAttempt(ctx context.Context, scope string, identifier string) (*repository.LoginAttempt, error) {
+	return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "no login attempts")
+}
+
+func (loginSecurityRepository) ClearLoginAttempts(ctx context.Context, scope string, identifier string) error {
+	return nil
+}
+
+func (loginSecurityRepository) CreateSecurityEvent(ctx context.Context, event *repository.SecurityEvent) error {
+	return nil
+}
+
+// loginTwoFactorRepository has nobody enrolled in two factor.
+type loginTwoFactorRepository struct {
+	stubTwoFactorRepository
+}
+
+func (loginTwoFactorRepository) GetTwoFactor(ctx context.Context, userID uint32) (*repository.TwoFactor, error) {
+	return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "two factor not enrolled")
+}
+
+func TestLoginUnenrolledAdminRefreshTokenIsNotABearerToken(t *testing.T) {
+	s := newRouteTestServer(t, new(bool))
+	s.config.REQUIRE_ADMIN_TWO_FACTOR = true
+	s.config.TOKEN_DURATION = time.Minute
+	s.config.REFRESH_TOKEN_DURATION = time.Hour
+
+	password, err := pkg.GenerateHashPassword("crochet-password", bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.repo.u = &loginUserRepository{
+		maker: s.tokenMaker,
+		user: &repository.User{
+			ID:       callerAdmin.userID,
+			Email:    "admin@example.com",
+			Password: password,
+			Role:     "ADMIN",
+		},
+	}
+	s.repo.sec = loginSecurityRepository{}
+	s.repo.tf = loginTwoFactorRepository{}
+
+	body, err := json.Marshal(loginUserRequest{Email: "admin@example.com", Password: "crochet-password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/users/login", bytes.NewReader(body)))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("login got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var login loginUserResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &login); err != nil {
+		t.Fatal(err)
+	}
+
+	if !login.TwoFactorEnrolmentRequired {
+		t.Fatal("login did not ask the admin to enrol in two factor")
+	}
+
+	tests := []struct {
+		name  string
+		path  string
+		token string
+		want  int
+	}{
+		{"access token on an admin route", "/api/v1/users/", login.AccessToken, http.StatusForbidden},
+		{"refresh token on an admin route", "/api/v1/users/", login.RefreshToken, http.StatusUnauthorized},
+		{"refresh token on the owner routes", "/api/v1/users/3", login.RefreshToken, http.StatusUnauthorized},
+		{"refresh token on the refresh route", "/api/v1/users/3/refresh-token", login.RefreshToken, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set(authorizationHeaderKey, "Bearer "+tt.token)
+
+			rec := httptest.NewRecorder()
+			s.router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("got %d, want %d: %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestTokensIssuedBeforeAResetAreRefused(t *testing.T) {
+	s := newRouteTestServer(t, new(bool))
+	s.config.TOKEN_DURATION = time.Minute
+
+	user := &repository.User{
+		ID:    callerOther.userID,
+		Email: "reset@example.com",
+		Role:  callerOther.role,
+	}
+	users := &loginUserRepository{maker: s.tokenMaker, user: user}
+	s.repo.u = users
+	s.repo.tf = loginTwoFactorRepository{}
+
+	issue := func() (string, string) {
+		t.Helper()
+
+		access, err := s.tokenMaker.CreateToken(user.ID, user.Email, user.Role, pkg.TokenTypeAccess, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		refresh, err := users.UpdateRefreshToken(context.Background(), user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return access, refresh
+	}
+
+	get := func(path, token string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(authorizationHeaderKey, "Bearer "+token)
+
+		rec := httptest.NewRecorder()
+		s.router.ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+
+	// the reset lands in a later second than the tokens were issued in
+	access, refresh := issue()
+	resetAt := time.Now().Add(time.Second).Truncate(time.Second)
+	user.TokensValidAfter = &resetAt
+
+	if code := get("/api/v1/users/2", access); code != http.StatusUnauthorized {
+		t.Fatalf("access token from before the reset got %d, want %d", code, http.StatusUnauthorized)
+	}
+
+	if code := get("/api/v1/users/2/refresh-token", refresh); code != http.StatusUnauthorized {
+		t.Fatalf("refresh token from before the reset got %d, want %d", code, http.StatusUnauthorized)
+	}
+
+	// tokens from a login after the reset work again
+	resetAt = time.Now().Truncate(time.Second)
+	access, refresh = issue()
+
+	if code := get("/api/v1/users/2", access); code != http.StatusOK {
+		t.Fatalf("access token from after the reset got %d, want %d", code, http.StatusOK)
+	}
+
+	if code := get("/api/v1/users/2/refresh-token", refresh); code != http.StatusOK {
+		t.Fatalf("refresh token from after the reset got %d, want %d", code, http.StatusOK)
+	}
+}
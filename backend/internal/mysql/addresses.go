@@ -0,0 +1,236 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/mysql/generated"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+)
+
+var _ repository.AddressRepository = (*AddressRepository)(nil)
+
+type AddressRepository struct {
+	db      *Store
+	queries generated.Querier
+}
+
+func NewAddressRepository(db *Store) *AddressRepository {
+	q := generated.New(db.db)
+
+	return &AddressRepository{
+		db:      db,
+		queries: q,
+	}
+}
+
+func (a *AddressRepository) CreateAddress(ctx context.Context, address *repository.Address) (*repository.Address, error) {
+	if err := address.Validate(); err != nil {
+		return nil, err
+	}
+
+	err := a.db.execTx(ctx, func(q *generated.Queries) error {
+		count, err := q.CountUserAddresses(ctx, address.UserID)
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to count addresses: %v", err)
+		}
+
+		if count == 0 {
+			address.IsDefault = true
+		}
+
+		if address.IsDefault {
+			if err := q.ClearDefaultAddress(ctx, address.UserID); err != nil {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to clear default address: %v", err)
+			}
+		}
+
+		result, err := q.CreateAddress(ctx, generated.CreateAddressParams{
+			UserID:        address.UserID,
+			RecipientName: address.RecipientName,
+			PhoneNumber:   address.PhoneNumber,
+			County:        address.County,
+			City:          address.City,
+			Street:        address.Street,
+			PostalCode:    address.PostalCode,
+			IsDefault:     address.IsDefault,
+		})
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create address: %v", err)
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get last inserted id: %v", err)
+		}
+
+		address.ID = uint32(id)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return a.GetUserAddress(ctx, address.UserID, address.ID)
+}
+
+func (a *AddressRepository) GetUserAddress(ctx context.Context, userID uint32, id uint32) (*repository.Address, error) {
+	address, err := a.queries.GetUserAddress(ctx, generated.GetUserAddressParams{
+		ID:     id,
+		UserID: userID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "no address found with id %d", id)
+		}
+
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get address: %v", err)
+	}
+
+	return addressFromRow(address), nil
+}
+
+func (a *AddressRepository) GetDefaultAddress(ctx context.Context, userID uint32) (*repository.Address, error) {
+	address, err := a.queries.GetDefaultAddress(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "user %d has no default address", userID)
+		}
+
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get default address: %v", err)
+	}
+
+	return addressFromRow(address), nil
+}
+
+func (a *AddressRepository) ListUserAddresses(ctx context.Context, userID uint32) ([]*repository.Address, error) {
+	addresses, err := a.queries.ListUserAddresses(ctx, userID)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list addresses: %v", err)
+	}
+
+	result := []*repository.Address{}
+	for _, address := range addresses {
+		result = append(result, addressFromRow(address))
+	}
+
+	return result, nil
+}
+
+func (a *AddressRepository) UpdateAddress(ctx context.Context, address *repository.UpdateAddress) (*repository.Address, error) {
+	if _, err := a.GetUserAddress(ctx, address.UserID, address.ID); err != nil {
+		return nil, err
+	}
+
+	err := a.queries.UpdateAddress(ctx, generated.UpdateAddressParams{
+		RecipientName: nullString(address.RecipientName),
+		PhoneNumber:   nullString(address.PhoneNumber),
+		County:        nullString(address.County),
+		City:          nullString(address.City),
+		Street:        nullString(address.Street),
+		PostalCode:    nullString(address.PostalCode),
+		ID:            address.ID,
+		UserID:        address.UserID,
+	})
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update address: %v", err)
+	}
+
+	return a.GetUserAddress(ctx, address.UserID, address.ID)
+}
+
+func (a *AddressRepository) SetDefaultAddress(ctx context.Context, userID uint32, id uint32) error {
+	return a.db.execTx(ctx, func(q *generated.Queries) error {
+		if _, err := q.GetUserAddress(ctx, generated.GetUserAddressParams{
+			ID:     id,
+			UserID: userID,
+		}); err != nil {
+			if err == sql.ErrNoRows {
+				return pkg.Errorf(pkg.NOT_FOUND_ERROR, "no address found with id %d", id)
+			}
+
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get address: %v", err)
+		}
+
+		if err := q.ClearDefaultAddress(ctx, userID); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to clear default address: %v", err)
+		}
+
+		if err := q.SetDefaultAddress(ctx, generated.SetDefaultAddressParams{
+			ID:     id,
+			UserID: userID,
+		}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to set default address: %v", err)
+		}
+
+		return nil
+	})
+}
+
+func (a *AddressRepository) DeleteAddress(ctx context.Context, userID uint32, id uint32) error {
+	address, err := a.GetUserAddress(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	return a.db.execTx(ctx, func(q *generated.Queries) error {
+		if err := q.DeleteAddress(ctx, generated.DeleteAddressParams{
+			ID:     id,
+			UserID: userID,
+		}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to delete address: %v", err)
+		}
+
+		if !address.IsDefault {
+			return nil
+		}
+
+		// hand the default over to the most recent remaining address
+		remaining, err := q.ListUserAddresses(ctx, userID)
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list addresses: %v", err)
+		}
+
+		if len(remaining) == 0 {
+			return nil
+		}
+
+		if err := q.SetDefaultAddress(ctx, generated.SetDefaultAddressParams{
+			ID:     remaining[0].ID,
+			UserID: userID,
+		}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to set default address: %v", err)
+		}
+
+		return nil
+	})
+}
+
+func addressFromRow(address generated.Address) *repository.Address {
+	return &repository.Address{
+		ID:            address.ID,
+		UserID:        address.UserID,
+		RecipientName: address.RecipientName,
+		PhoneNumber:   address.PhoneNumber,
+		County:        address.County,
+		City:          address.City,
+		Street:        address.Street,
+		PostalCode:    address.PostalCode,
+		IsDefault:     address.IsDefault,
+		UpdatedAt:     address.UpdatedAt,
+		CreatedAt:     address.CreatedAt,
+	}
+}
+
+func nullString(v *string) sql.NullString {
+	if v == nil {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{
+		Valid:  true,
+		String: *v,
+	}
+}
@@ -0,0 +1,200 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/mysql/generated"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+)
+
+var _ repository.APIKeyRepository = (*APIKeyRepository)(nil)
+
+type APIKeyRepository struct {
+	db      *Store
+	queries generated.Querier
+}
+
+func NewAPIKeyRepository(db *Store) *APIKeyRepository {
+	q := generated.New(db.db)
+
+	return &APIKeyRepository{
+		db:      db,
+		queries: q,
+	}
+}
+
+func (a *APIKeyRepository) CreateAPIKey(ctx context.Context, key *repository.APIKey) (*repository.APIKey, error) {
+	if err := key.Validate(); err != nil {
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "%v", err)
+	}
+
+	scopes, err := json.Marshal(key.Scopes)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to marshal scopes: %v", err)
+	}
+
+	req := generated.CreateAPIKeyParams{
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		KeyHash:   key.KeyHash,
+		Scopes:    scopes,
+		CreatedBy: key.CreatedBy,
+	}
+
+	if key.ExpiresAt != nil {
+		req.ExpiresAt = sql.NullTime{
+			Valid: true,
+			Time:  *key.ExpiresAt,
+		}
+	}
+
+	result, err := a.queries.CreateAPIKey(ctx, req)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create api key: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get last inserted id: %v", err)
+	}
+
+	return a.GetAPIKey(ctx, uint32(id))
+}
+
+func (a *APIKeyRepository) GetAPIKey(ctx context.Context, id uint32) (*repository.APIKey, error) {
+	key, err := a.queries.GetAPIKey(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "no api key found with id %d", id)
+		}
+
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get api key: %v", err)
+	}
+
+	return apiKeyFromRow(key)
+}
+
+func (a *APIKeyRepository) AuthenticateAPIKey(ctx context.Context, keyHash string) (*repository.APIKey, *repository.User, error) {
+	row, err := a.queries.GetActiveAPIKeyByHash(ctx, generated.GetActiveAPIKeyByHashParams{
+		KeyHash: keyHash,
+		Now: sql.NullTime{
+			Valid: true,
+			Time:  time.Now().UTC(),
+		},
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "api key not found")
+		}
+
+		return nil, nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get api key: %v", err)
+	}
+
+	key, err := apiKeyFromRow(generated.ApiKey{
+		ID:         row.ID,
+		Name:       row.Name,
+		Prefix:     row.Prefix,
+		Scopes:     row.Scopes,
+		CreatedBy:  row.CreatedBy,
+		ExpiresAt:  row.ExpiresAt,
+		LastUsedAt: row.LastUsedAt,
+		CreatedAt:  row.CreatedAt,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return key, &repository.User{
+		ID:    row.CreatedBy,
+		Email: row.Email,
+		Role:  row.Role,
+	}, nil
+}
+
+func (a *APIKeyRepository) ListAPIKeys(ctx context.Context) ([]*repository.APIKey, error) {
+	keys, err := a.queries.ListAPIKeys(ctx)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list api keys: %v", err)
+	}
+
+	result := []*repository.APIKey{}
+	for _, key := range keys {
+		k, err := apiKeyFromRow(key)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, k)
+	}
+
+	return result, nil
+}
+
+func (a *APIKeyRepository) RevokeAPIKey(ctx context.Context, id uint32) error {
+	key, err := a.GetAPIKey(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if key.RevokedAt != nil {
+		return pkg.Errorf(pkg.INVALID_ERROR, "api key %d is already revoked", id)
+	}
+
+	_, err = a.queries.RevokeAPIKey(ctx, generated.RevokeAPIKeyParams{
+		RevokedAt: sql.NullTime{
+			Valid: true,
+			Time:  time.Now().UTC(),
+		},
+		ID: id,
+	})
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to revoke api key: %v", err)
+	}
+
+	return nil
+}
+
+func (a *APIKeyRepository) TouchAPIKey(ctx context.Context, id uint32, interval time.Duration) error {
+	now := time.Now().UTC()
+
+	err := a.queries.TouchAPIKey(ctx, generated.TouchAPIKeyParams{
+		Now: sql.NullTime{
+			Valid: true,
+			Time:  now,
+		},
+		ID: id,
+		UsedBefore: sql.NullTime{
+			Valid: true,
+			Time:  now.Add(-interval),
+		},
+	})
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to record api key use: %v", err)
+	}
+
+	return nil
+}
+
+func apiKeyFromRow(key generated.ApiKey) (*repository.APIKey, error) {
+	result := &repository.APIKey{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		KeyHash:    key.KeyHash,
+		CreatedBy:  key.CreatedBy,
+		ExpiresAt:  timePtr(key.ExpiresAt),
+		LastUsedAt: timePtr(key.LastUsedAt),
+		RevokedAt:  timePtr(key.RevokedAt),
+		CreatedAt:  key.CreatedAt,
+	}
+
+	if err := json.Unmarshal(key.Scopes, &result.Scopes); err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to unmarshal api key scopes: %v", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,93 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/mysql/generated"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+)
+
+var _ repository.AuditRepository = (*AuditRepository)(nil)
+
+type AuditRepository struct {
+	db      *Store
+	queries generated.Querier
+}
+
+func NewAuditRepository(db *Store) *AuditRepository {
+	q := generated.New(db.db)
+
+	return &AuditRepository{
+		db:      db,
+		queries: q,
+	}
+}
+
+func (a *AuditRepository) CreateAuditEntry(ctx context.Context, entry *repository.AuditEntry) error {
+	err := a.queries.CreateAuditEntry(ctx, generated.CreateAuditEntryParams{
+		ActorID:    nullUint32(entry.ActorID),
+		Action:     entry.Action,
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		Before:     entry.Before,
+		After:      entry.After,
+		IpAddress:  entry.IPAddress,
+		RequestID:  entry.RequestID,
+	})
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create audit entry: %v", err)
+	}
+
+	return nil
+}
+
+func (a *AuditRepository) ListAuditEntries(ctx context.Context, filter repository.AuditFilter) ([]*repository.AuditEntry, error) {
+	req := generated.ListAuditEntriesParams{
+		ActorID:    nullUint32(filter.ActorID),
+		Action:     nullString(filter.Action),
+		EntityType: nullString(filter.EntityType),
+		EntityID:   nullString(filter.EntityID),
+		RequestID:  nullString(filter.RequestID),
+		Limit:      filter.Limit,
+		Offset:     filter.Offset,
+	}
+
+	if filter.CreatedAfter != nil {
+		req.CreatedAfter = sql.NullTime{
+			Valid: true,
+			Time:  *filter.CreatedAfter,
+		}
+	}
+
+	if filter.CreatedBefore != nil {
+		req.CreatedBefore = sql.NullTime{
+			Valid: true,
+			Time:  *filter.CreatedBefore,
+		}
+	}
+
+	entries, err := a.queries.ListAuditEntries(ctx, req)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list audit entries: %v", err)
+	}
+
+	result := []*repository.AuditEntry{}
+	for _, entry := range entries {
+		result = append(result, &repository.AuditEntry{
+			ID:         entry.ID,
+			ActorID:    uint32Ptr(entry.ActorID),
+			Action:     entry.Action,
+			EntityType: entry.EntityType,
+			EntityID:   entry.EntityID,
+			Before:     entry.Before,
+			After:      entry.After,
+			IPAddress:  entry.IpAddress,
+			RequestID:  entry.RequestID,
+			CreatedAt:  entry.CreatedAt,
+		})
+	}
+
+	return result, nil
+}
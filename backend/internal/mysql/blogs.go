@@ -3,10 +3,23 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/mysql/generated"
 	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
 	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+	"github.com/go-sql-driver/mysql"
+)
+
+const (
+	// how many numbered variants of a generated slug are tried before giving up
+	maxSlugAttempts = 20
+	// excerpts fit a listing card or a meta description
+	excerptLength = 160
+	// tags are stored slugified so they can be used in urls as they are
+	maxTagLength = 50
 )
 
 var _ repository.BlogRepository = (*BlogRepository)(nil)
@@ -26,30 +39,86 @@ func NewBlogRepository(db *Store) *BlogRepository {
 }
 
 func (b *BlogRepository) CreateBlog(ctx context.Context, blog *repository.Blog) (*repository.Blog, error) {
+	if blog.Status == "" {
+		blog.Status = repository.BlogDraft
+	}
+
 	if err := blog.Validate(); err != nil {
 		return nil, pkg.Errorf(pkg.INVALID_ERROR, "%v", err)
 	}
 
-	result, err := b.queries.CreateBlog(ctx, generated.CreateBlogParams{
-		Author:  blog.Author,
-		Title:   blog.Title,
-		Content: blog.Content,
-		ImgUrls: blog.ImgUrls,
-	})
-	if err != nil {
-		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create blog: %v", err)
+	if blog.Status == repository.BlogPublished && blog.PublishAt == nil {
+		blog.PublishAt = pkg.TimePtr(time.Now())
 	}
 
-	id, err := result.LastInsertId()
-	if err != nil {
-		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get last inserted id: %v", err)
+	blog.ContentHTML, blog.Excerpt, blog.ReadingMinutes = renderContent(blog.Content)
+
+	// a slug chosen by the author must be free, one made from the title gets a number
+	explicit := blog.Slug != ""
+
+	base := pkg.Slugify(blog.Title)
+	if explicit {
+		base = pkg.Slugify(blog.Slug)
+	}
+
+	if base == "" {
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "slug must contain letters or numbers")
 	}
 
-	blog.ID = uint32(id)
+	for attempt := 1; attempt <= maxSlugAttempts; attempt++ {
+		blog.Slug = base
+		if attempt > 1 {
+			blog.Slug = fmt.Sprintf("%s-%d", base, attempt)
+		}
+
+		var id int64
+
+		err := b.db.execTx(ctx, func(q *generated.Queries) error {
+			result, err := q.CreateBlog(ctx, generated.CreateBlogParams{
+				Author:         blog.Author,
+				Title:          blog.Title,
+				Content:        blog.Content,
+				ContentHtml:    blog.ContentHTML,
+				Excerpt:        blog.Excerpt,
+				ReadingMinutes: blog.ReadingMinutes,
+				ImgUrls:        blog.ImgUrls,
+				Status:         blog.Status,
+				Slug:           blog.Slug,
+				PublishAt:      nullTime(blog.PublishAt),
+			})
+			if err != nil {
+				if mysqlErr, ok := err.(*mysql.MySQLError); ok && mysqlErr.Number == 1062 {
+					return pkg.Errorf(pkg.ALREADY_EXISTS_ERROR, "slug %s is already used by another blog", blog.Slug)
+				}
+
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create blog: %v", err)
+			}
+
+			id, err = result.LastInsertId()
+			if err != nil {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get last inserted id: %v", err)
+			}
+
+			if err := setBlogTags(ctx, q, uint32(id), blog.Tags); err != nil {
+				return err
+			}
+
+			return setBlogProducts(ctx, q, uint32(id), blog.ProductIDs)
+		})
+		if err != nil {
+			if pkg.ErrorCode(err) == pkg.ALREADY_EXISTS_ERROR && !explicit {
+				continue
+			}
+
+			return nil, err
+		}
+
+		// send email to subscribed users
 
-	// send email to subscribed users
+		return b.GetBlog(ctx, uint32(id))
+	}
 
-	return blog, err
+	return nil, pkg.Errorf(pkg.ALREADY_EXISTS_ERROR, "could not find a free slug for %s, choose one", base)
 }
 
 func (b *BlogRepository) GetBlog(ctx context.Context, id uint32) (*repository.Blog, error) {
@@ -62,14 +131,33 @@ func (b *BlogRepository) GetBlog(ctx context.Context, id uint32) (*repository.Bl
 		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get blog: %v", err)
 	}
 
-	return &repository.Blog{
-		ID:        blog.ID,
-		Author:    blog.Author,
-		Title:     blog.Title,
-		Content:   blog.Content,
-		ImgUrls:   blog.ImgUrls,
-		CreatedAt: blog.CreatedAt,
-	}, nil
+	return b.blogWithLinks(ctx, blog)
+}
+
+func (b *BlogRepository) GetPublishedBlog(ctx context.Context, id uint32) (*repository.Blog, error) {
+	blog, err := b.queries.GetPublishedBlog(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "no blog found with id %d", id)
+		}
+
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get blog: %v", err)
+	}
+
+	return b.blogWithLinks(ctx, blog)
+}
+
+func (b *BlogRepository) GetPublishedBlogBySlug(ctx context.Context, slug string) (*repository.Blog, error) {
+	blog, err := b.queries.GetPublishedBlogBySlug(ctx, slug)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "no blog found with slug %s", slug)
+		}
+
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get blog: %v", err)
+	}
+
+	return b.blogWithLinks(ctx, blog)
 }
 
 func (b *BlogRepository) GetBlogsByAuthor(ctx context.Context, author uint32) ([]*repository.Blog, error) {
@@ -82,20 +170,19 @@ func (b *BlogRepository) GetBlogsByAuthor(ctx context.Context, author uint32) ([
 		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get blogs: %v", err)
 	}
 
-	result := []*repository.Blog{}
+	return b.blogsWithTags(ctx, blogs)
+}
 
-	for _, blog := range blogs {
-		result = append(result, &repository.Blog{
-			ID:        blog.ID,
-			Author:    blog.Author,
-			Title:     blog.Title,
-			Content:   blog.Content,
-			ImgUrls:   blog.ImgUrls,
-			CreatedAt: blog.CreatedAt,
-		})
+func (b *BlogRepository) ListAuthorBlogs(ctx context.Context, author uint32, status *string) ([]*repository.Blog, error) {
+	blogs, err := b.queries.ListAuthorBlogs(ctx, generated.ListAuthorBlogsParams{
+		Author: author,
+		Status: nullString(status),
+	})
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get blogs: %v", err)
 	}
 
-	return result, nil
+	return b.blogsWithTags(ctx, blogs)
 }
 
 func (b *BlogRepository) ListBlogs(ctx context.Context) ([]*repository.Blog, error) {
@@ -104,16 +191,39 @@ func (b *BlogRepository) ListBlogs(ctx context.Context) ([]*repository.Blog, err
 		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get blogs: %v", err)
 	}
 
-	result := []*repository.Blog{}
+	return b.blogsWithTags(ctx, blogs)
+}
+
+func (b *BlogRepository) SearchBlogs(ctx context.Context, filter repository.BlogFilter) ([]*repository.Blog, error) {
+	if filter.Tag != nil {
+		filter.Tag = pkg.StringPtr(normalizeTag(*filter.Tag))
+	}
+
+	blogs, err := b.queries.SearchBlogs(ctx, generated.SearchBlogsParams{
+		Tag:    nullString(filter.Tag),
+		Query:  nullString(filter.Query),
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	})
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to search blogs: %v", err)
+	}
+
+	return b.blogsWithTags(ctx, blogs)
+}
+
+func (b *BlogRepository) ListTags(ctx context.Context) ([]*repository.Tag, error) {
+	tags, err := b.queries.ListTags(ctx)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list tags: %v", err)
+	}
+
+	result := []*repository.Tag{}
 
-	for _, blog := range blogs {
-		result = append(result, &repository.Blog{
-			ID:        blog.ID,
-			Author:    blog.Author,
-			Title:     blog.Title,
-			Content:   blog.Content,
-			ImgUrls:   blog.ImgUrls,
-			CreatedAt: blog.CreatedAt,
+	for _, tag := range tags {
+		result = append(result, &repository.Tag{
+			Name:      tag.Name,
+			BlogCount: tag.BlogCount,
 		})
 	}
 
@@ -121,12 +231,15 @@ func (b *BlogRepository) ListBlogs(ctx context.Context) ([]*repository.Blog, err
 }
 
 func (b *BlogRepository) UpdateBlog(ctx context.Context, blog *repository.UpdateBlog) error {
-	// check if the blog exists
-	_, err := b.GetBlog(ctx, blog.ID)
+	existing, err := b.checkAuthor(ctx, blog.ID, blog.Author)
 	if err != nil {
 		return err
 	}
 
+	if err := blog.Validate(); err != nil {
+		return pkg.Errorf(pkg.INVALID_ERROR, "%v", err)
+	}
+
 	var req generated.UpdateBlogParams
 
 	req.ID = blog.ID
@@ -143,31 +256,249 @@ func (b *BlogRepository) UpdateBlog(ctx context.Context, blog *repository.Update
 			Valid:  true,
 			String: *blog.Content,
 		}
+
+		contentHTML, excerpt, minutes := renderContent(*blog.Content)
+
+		req.ContentHtml = sql.NullString{Valid: true, String: contentHTML}
+		req.Excerpt = sql.NullString{Valid: true, String: excerpt}
+		req.ReadingMinutes = sql.NullInt32{Valid: true, Int32: int32(minutes)}
 	}
 
 	if blog.ImgUrls != nil {
 		req.ImgUrls = *blog.ImgUrls
 	}
 
-	err = b.queries.UpdateBlog(ctx, req)
+	if blog.Status != nil {
+		req.Status = nullString(blog.Status)
+
+		// publishing a draft without a schedule makes it public straight away
+		if *blog.Status == repository.BlogPublished && blog.PublishAt == nil && existing.PublishAt == nil {
+			blog.PublishAt = pkg.TimePtr(time.Now())
+		}
+	}
+
+	if blog.Slug != nil {
+		slug := pkg.Slugify(*blog.Slug)
+		if slug == "" {
+			return pkg.Errorf(pkg.INVALID_ERROR, "slug must contain letters or numbers")
+		}
+
+		req.Slug = nullString(&slug)
+	}
+
+	req.PublishAt = nullTime(blog.PublishAt)
+
+	return b.db.execTx(ctx, func(q *generated.Queries) error {
+		err := q.UpdateBlog(ctx, req)
+		if err != nil {
+			if mysqlErr, ok := err.(*mysql.MySQLError); ok && mysqlErr.Number == 1062 {
+				return pkg.Errorf(pkg.ALREADY_EXISTS_ERROR, "slug %s is already used by another blog", req.Slug.String)
+			}
+
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update blog: %v", err)
+		}
+
+		if blog.Tags != nil {
+			if err := setBlogTags(ctx, q, blog.ID, *blog.Tags); err != nil {
+				return err
+			}
+		}
+
+		if blog.ProductIDs != nil {
+			if err := setBlogProducts(ctx, q, blog.ID, *blog.ProductIDs); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (b *BlogRepository) DeleteBlog(ctx context.Context, id uint32, author uint32) error {
+	if _, err := b.checkAuthor(ctx, id, author); err != nil {
+		return err
+	}
+
+	err := b.queries.DeleteBlog(ctx, id)
 	if err != nil {
-		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update blog: %v", err)
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to delete blog: %v", err)
 	}
 
 	return nil
 }
 
-func (b *BlogRepository) DeleteBlog(ctx context.Context, id uint32) error {
-	// check if the blog exists
-	_, err := b.GetBlog(ctx, id)
+// checkAuthor makes sure the blog exists and was written by author.
+func (b *BlogRepository) checkAuthor(ctx context.Context, id uint32, author uint32) (*repository.Blog, error) {
+	blog, err := b.GetBlog(ctx, id)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	err = b.queries.DeleteBlog(ctx, id)
+	if blog.Author != author {
+		return nil, pkg.Errorf(pkg.FORBIDDEN_ERROR, "blog %d belongs to another author", id)
+	}
+
+	return blog, nil
+}
+
+// blogWithLinks is used when a single blog is read, it adds the tags and the
+// linked products as they are in the catalogue now.
+func (b *BlogRepository) blogWithLinks(ctx context.Context, row generated.Blog) (*repository.Blog, error) {
+	blog := blogFromRow(row)
+
+	tags, err := b.queries.ListBlogTags(ctx, blog.ID)
 	if err != nil {
-		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to delete blog: %v", err)
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get blog tags: %v", err)
+	}
+
+	products, err := b.queries.ListBlogProducts(ctx, blog.ID)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get blog products: %v", err)
+	}
+
+	blog.Tags = tags
+	if blog.Tags == nil {
+		blog.Tags = []string{}
+	}
+
+	for _, product := range products {
+		blog.ProductIDs = append(blog.ProductIDs, product.ID)
+		blog.Products = append(blog.Products, toRepositoryProduct(product))
+	}
+
+	return blog, nil
+}
+
+func (b *BlogRepository) blogsWithTags(ctx context.Context, rows []generated.Blog) ([]*repository.Blog, error) {
+	result := []*repository.Blog{}
+
+	for _, row := range rows {
+		blog := blogFromRow(row)
+
+		tags, err := b.queries.ListBlogTags(ctx, blog.ID)
+		if err != nil {
+			return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get blog tags: %v", err)
+		}
+
+		blog.Tags = tags
+		if blog.Tags == nil {
+			blog.Tags = []string{}
+		}
+
+		result = append(result, blog)
+	}
+
+	return result, nil
+}
+
+// setBlogTags replaces the tags of a blog, creating the ones that are new.
+func setBlogTags(ctx context.Context, q *generated.Queries, blogID uint32, tags []string) error {
+	if err := q.DeleteBlogTags(ctx, blogID); err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to clear blog tags: %v", err)
+	}
+
+	seen := make(map[string]bool)
+
+	for _, tag := range tags {
+		name := normalizeTag(tag)
+		if name == "" {
+			return pkg.Errorf(pkg.INVALID_ERROR, "tag %q must contain letters or numbers", tag)
+		}
+
+		if seen[name] {
+			continue
+		}
+
+		seen[name] = true
+
+		result, err := q.UpsertTag(ctx, name)
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to save tag: %v", err)
+		}
+
+		// LAST_INSERT_ID(id) in the upsert makes this the existing id too
+		tagID, err := result.LastInsertId()
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get tag id: %v", err)
+		}
+
+		if err := q.AddBlogTag(ctx, generated.AddBlogTagParams{
+			BlogID: blogID,
+			TagID:  uint32(tagID),
+		}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to tag blog: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// setBlogProducts replaces the products linked to a blog keeping their order.
+func setBlogProducts(ctx context.Context, q *generated.Queries, blogID uint32, productIDs []uint32) error {
+	if err := q.DeleteBlogProducts(ctx, blogID); err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to clear blog products: %v", err)
+	}
+
+	seen := make(map[uint32]bool)
+
+	for _, productID := range productIDs {
+		if seen[productID] {
+			continue
+		}
+
+		seen[productID] = true
+
+		err := q.AddBlogProduct(ctx, generated.AddBlogProductParams{
+			BlogID:    blogID,
+			ProductID: productID,
+			Position:  uint32(len(seen) - 1),
+		})
+		if err != nil {
+			if mysqlErr, ok := err.(*mysql.MySQLError); ok && mysqlErr.Number == 1452 {
+				return pkg.Errorf(pkg.NOT_FOUND_ERROR, "no product found with id %d", productID)
+			}
+
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to link product: %v", err)
+		}
 	}
 
 	return nil
 }
+
+// normalizeTag is how tags are stored and looked up.
+func normalizeTag(tag string) string {
+	name := []rune(pkg.Slugify(tag))
+	if len(name) > maxTagLength {
+		name = name[:maxTagLength]
+	}
+
+	return strings.Trim(string(name), "-")
+}
+
+func blogFromRow(blog generated.Blog) *repository.Blog {
+	return &repository.Blog{
+		ID:             blog.ID,
+		Author:         blog.Author,
+		Title:          blog.Title,
+		Slug:           blog.Slug,
+		Content:        blog.Content,
+		ContentHTML:    blog.ContentHtml,
+		Excerpt:        blog.Excerpt,
+		ReadingMinutes: blog.ReadingMinutes,
+		ImgUrls:        blog.ImgUrls,
+		Status:         blog.Status,
+		CommentCount:   blog.CommentCount,
+		PublishAt:      timePtr(blog.PublishAt),
+		UpdatedAt:      blog.UpdatedAt,
+		CreatedAt:      blog.CreatedAt,
+	}
+}
+
+// renderContent turns the markdown of a blog into the sanitized html, excerpt
+// and reading time stored next to it.
+func renderContent(content string) (string, string, uint32) {
+	contentHTML := pkg.RenderMarkdown(content)
+	text := pkg.HTMLText(contentHTML)
+
+	return contentHTML, pkg.Excerpt(text, excerptLength), pkg.ReadingMinutes(text)
+}
@@ -0,0 +1,335 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/mysql/generated"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+)
+
+var _ repository.CommentRepository = (*CommentRepository)(nil)
+
+type CommentRepository struct {
+	db      *Store
+	queries generated.Querier
+	filter  *pkg.ContentFilter
+}
+
+func NewCommentRepository(db *Store) *CommentRepository {
+	q := generated.New(db.db)
+
+	return &CommentRepository{
+		db:      db,
+		queries: q,
+		filter:  pkg.NewContentFilter(db.config.REVIEW_BLOCKED_WORDS),
+	}
+}
+
+// moderationStatus works like the one for reviews, with the filter off every
+// comment waits for a moderator.
+func (c *CommentRepository) moderationStatus(text string) (string, string) {
+	if !c.db.config.COMMENT_FILTER_ENABLED {
+		return repository.CommentPending, ""
+	}
+
+	if reason := c.filter.Check(text); reason != "" {
+		return repository.CommentPending, reason
+	}
+
+	return repository.CommentApproved, ""
+}
+
+func (c *CommentRepository) CreateComment(ctx context.Context, comment *repository.Comment) (*repository.Comment, error) {
+	if comment.Content == "" {
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "content is required")
+	}
+
+	if limit := c.db.config.COMMENT_RATE_LIMIT; limit > 0 {
+		count, err := c.queries.CountUserCommentsSince(ctx, generated.CountUserCommentsSinceParams{
+			UserID:    comment.UserID,
+			CreatedAt: time.Now().Add(-c.db.config.COMMENT_RATE_WINDOW),
+		})
+		if err != nil {
+			return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to count comments: %v", err)
+		}
+
+		if count >= int64(limit) {
+			return nil, pkg.Errorf(pkg.TOO_MANY_REQUESTS_ERROR, "you can post %d comments every %s, try again later", limit, c.db.config.COMMENT_RATE_WINDOW)
+		}
+	}
+
+	comment.Status, comment.ModerationReason = c.moderationStatus(comment.Content)
+
+	var id int64
+
+	err := c.db.execTx(ctx, func(q *generated.Queries) error {
+		if _, err := q.GetPublishedBlog(ctx, comment.BlogID); err != nil {
+			if err == sql.ErrNoRows {
+				return pkg.Errorf(pkg.NOT_FOUND_ERROR, "no blog found with id %d", comment.BlogID)
+			}
+
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get blog: %v", err)
+		}
+
+		if comment.ParentID != nil {
+			parent, err := q.GetComment(ctx, *comment.ParentID)
+			if err != nil && err != sql.ErrNoRows {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get comment: %v", err)
+			}
+
+			if err == sql.ErrNoRows || parent.BlogComment.BlogID != comment.BlogID || !commentCounted(parent.BlogComment) {
+				return pkg.Errorf(pkg.INVALID_ERROR, "comment %d cannot be replied to", *comment.ParentID)
+			}
+		}
+
+		result, err := q.CreateComment(ctx, generated.CreateCommentParams{
+			BlogID:           comment.BlogID,
+			UserID:           comment.UserID,
+			ParentID:         nullUint32(comment.ParentID),
+			Content:          comment.Content,
+			Status:           comment.Status,
+			ModerationReason: comment.ModerationReason,
+		})
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create comment: %v", err)
+		}
+
+		id, err = result.LastInsertId()
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get last inserted id: %v", err)
+		}
+
+		if comment.Status == repository.CommentApproved {
+			if err := q.IncrementBlogCommentCount(ctx, comment.BlogID); err != nil {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update comment count: %v", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return c.GetComment(ctx, uint32(id))
+}
+
+func (c *CommentRepository) GetComment(ctx context.Context, id uint32) (*repository.Comment, error) {
+	row, err := c.queries.GetComment(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "no comment found with id %d", id)
+		}
+
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get comment: %v", err)
+	}
+
+	return commentFromRow(row.BlogComment, row.AuthorName), nil
+}
+
+func (c *CommentRepository) ListBlogComments(ctx context.Context, blogID uint32) ([]*repository.Comment, error) {
+	if _, err := c.queries.GetPublishedBlog(ctx, blogID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "no blog found with id %d", blogID)
+		}
+
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get blog: %v", err)
+	}
+
+	rows, err := c.queries.ListBlogComments(ctx, blogID)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list comments: %v", err)
+	}
+
+	result := []*repository.Comment{}
+
+	for _, row := range rows {
+		result = append(result, commentFromRow(row.BlogComment, row.AuthorName))
+	}
+
+	return result, nil
+}
+
+func (c *CommentRepository) ListCommentsByStatus(ctx context.Context, status string, limit int32, offset int32) ([]*repository.Comment, error) {
+	rows, err := c.queries.ListCommentsByStatus(ctx, generated.ListCommentsByStatusParams{
+		Status: status,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list comments: %v", err)
+	}
+
+	result := []*repository.Comment{}
+
+	for _, row := range rows {
+		result = append(result, commentFromRow(row.BlogComment, row.AuthorName))
+	}
+
+	return result, nil
+}
+
+func (c *CommentRepository) ListUserComments(ctx context.Context, userID uint32) ([]*repository.Comment, error) {
+	rows, err := c.queries.ListUserComments(ctx, userID)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list comments: %v", err)
+	}
+
+	result := []*repository.Comment{}
+
+	for _, row := range rows {
+		result = append(result, commentFromRow(row.BlogComment, row.AuthorName))
+	}
+
+	return result, nil
+}
+
+func (c *CommentRepository) UpdateComment(ctx context.Context, id uint32, userID uint32, content string) (*repository.Comment, error) {
+	if content == "" {
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "content is required")
+	}
+
+	comment, err := c.checkAuthor(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// a moderator hid it, editing must not bring it back
+	if comment.Status == repository.CommentHidden {
+		return nil, pkg.Errorf(pkg.FORBIDDEN_ERROR, "comment %d was hidden by a moderator", id)
+	}
+
+	status, reason := c.moderationStatus(content)
+
+	err = c.db.execTx(ctx, func(q *generated.Queries) error {
+		if err := q.UpdateComment(ctx, generated.UpdateCommentParams{
+			Content:          content,
+			Status:           status,
+			ModerationReason: reason,
+			ID:               id,
+		}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update comment: %v", err)
+		}
+
+		return adjustCommentCount(ctx, q, comment.BlogID, comment.Status == repository.CommentApproved, status == repository.CommentApproved)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return c.GetComment(ctx, id)
+}
+
+func (c *CommentRepository) DeleteComment(ctx context.Context, id uint32, userID uint32) error {
+	comment, err := c.checkAuthor(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	return c.db.execTx(ctx, func(q *generated.Queries) error {
+		if err := q.DeleteComment(ctx, id); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to delete comment: %v", err)
+		}
+
+		return adjustCommentCount(ctx, q, comment.BlogID, comment.Status == repository.CommentApproved, false)
+	})
+}
+
+func (c *CommentRepository) ModerateComment(ctx context.Context, id uint32, moderatorID uint32, status string, reason string) (*repository.Comment, error) {
+	if status != repository.CommentApproved && status != repository.CommentHidden {
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "status must be %s or %s", repository.CommentApproved, repository.CommentHidden)
+	}
+
+	if status == repository.CommentHidden && reason == "" {
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "reason is required when hiding a comment")
+	}
+
+	comment, err := c.GetComment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if comment.DeletedAt != nil {
+		return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "no comment found with id %d", id)
+	}
+
+	err = c.db.execTx(ctx, func(q *generated.Queries) error {
+		if err := q.ModerateComment(ctx, generated.ModerateCommentParams{
+			Status:           status,
+			ModerationReason: reason,
+			ModeratedBy:      nullUint32(&moderatorID),
+			ID:               id,
+		}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to moderate comment: %v", err)
+		}
+
+		return adjustCommentCount(ctx, q, comment.BlogID, comment.Status == repository.CommentApproved, status == repository.CommentApproved)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return c.GetComment(ctx, id)
+}
+
+// checkAuthor makes sure the comment exists, is not deleted and was written by userID.
+func (c *CommentRepository) checkAuthor(ctx context.Context, id uint32, userID uint32) (*repository.Comment, error) {
+	comment, err := c.GetComment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if comment.DeletedAt != nil {
+		return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "no comment found with id %d", id)
+	}
+
+	if comment.UserID != userID {
+		return nil, pkg.Errorf(pkg.FORBIDDEN_ERROR, "comment %d belongs to another user", id)
+	}
+
+	return comment, nil
+}
+
+// adjustCommentCount keeps blogs.comment_count in line when a comment starts
+// or stops being shown.
+func adjustCommentCount(ctx context.Context, q *generated.Queries, blogID uint32, wasCounted bool, isCounted bool) error {
+	var err error
+
+	switch {
+	case !wasCounted && isCounted:
+		err = q.IncrementBlogCommentCount(ctx, blogID)
+	case wasCounted && !isCounted:
+		err = q.DecrementBlogCommentCount(ctx, blogID)
+	}
+
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update comment count: %v", err)
+	}
+
+	return nil
+}
+
+func commentCounted(comment generated.BlogComment) bool {
+	return comment.Status == repository.CommentApproved && !comment.DeletedAt.Valid
+}
+
+func commentFromRow(comment generated.BlogComment, authorName string) *repository.Comment {
+	return &repository.Comment{
+		ID:               comment.ID,
+		BlogID:           comment.BlogID,
+		UserID:           comment.UserID,
+		AuthorName:       authorName,
+		ParentID:         uint32Ptr(comment.ParentID),
+		Content:          comment.Content,
+		Status:           comment.Status,
+		ModerationReason: comment.ModerationReason,
+		ModeratedBy:      uint32Ptr(comment.ModeratedBy),
+		ModeratedAt:      timePtr(comment.ModeratedAt),
+		DeletedAt:        timePtr(comment.DeletedAt),
+		UpdatedAt:        comment.UpdatedAt,
+		CreatedAt:        comment.CreatedAt,
+	}
+}
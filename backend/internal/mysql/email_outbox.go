@@ -0,0 +1,182 @@
+package mysql
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/mysql/generated"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+)
+
+// longest send error kept on an outbox email, the column is a varchar(255)
+const maxEmailErrorLength = 255
+
+var _ repository.EmailOutboxRepository = (*EmailOutboxRepository)(nil)
+
+type EmailOutboxRepository struct {
+	db      *Store
+	queries generated.Querier
+}
+
+func NewEmailOutboxRepository(db *Store) *EmailOutboxRepository {
+	q := generated.New(db.db)
+
+	return &EmailOutboxRepository{
+		db:      db,
+		queries: q,
+	}
+}
+
+func (e *EmailOutboxRepository) EnqueueEmail(ctx context.Context, email *repository.OutboxEmail) error {
+	if email.To == "" {
+		return pkg.Errorf(pkg.INVALID_ERROR, "recipient is required")
+	}
+
+	if email.Subject == "" {
+		return pkg.Errorf(pkg.INVALID_ERROR, "subject is required")
+	}
+
+	var headers json.RawMessage
+
+	if len(email.Headers) > 0 {
+		var err error
+
+		headers, err = json.Marshal(email.Headers)
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to marshal headers: %v", err)
+		}
+	}
+
+	result, err := e.queries.CreateOutboxEmail(ctx, generated.CreateOutboxEmailParams{
+		Recipient: email.To,
+		Subject:   email.Subject,
+		Template:  email.Template,
+		TextBody:  email.Text,
+		HtmlBody:  email.HTML,
+		Headers:   headers,
+	})
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to enqueue email: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get last inserted id: %v", err)
+	}
+
+	email.ID = uint32(id)
+
+	return nil
+}
+
+func (e *EmailOutboxRepository) ClaimDueEmails(ctx context.Context, limit int32, lease time.Duration) ([]*repository.OutboxEmail, error) {
+	result := []*repository.OutboxEmail{}
+
+	// rows another worker is claiming are skipped, and the claimed ones are
+	// not due again until the lease runs out
+	err := e.db.execTx(ctx, func(q *generated.Queries) error {
+		emails, err := q.ListDueOutboxEmails(ctx, limit)
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list outbox emails: %v", err)
+		}
+
+		claimedUntil := time.Now().Add(lease)
+
+		for _, email := range emails {
+			if err := q.ClaimOutboxEmail(ctx, generated.ClaimOutboxEmailParams{
+				NextAttemptAt: claimedUntil,
+				ID:            email.ID,
+			}); err != nil {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to claim outbox email: %v", err)
+			}
+
+			outboxEmail, err := outboxEmailFromRow(email)
+			if err != nil {
+				return err
+			}
+
+			result = append(result, outboxEmail)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (e *EmailOutboxRepository) ListRecipientEmails(ctx context.Context, recipient string) ([]*repository.OutboxEmail, error) {
+	emails, err := e.queries.ListRecipientOutboxEmails(ctx, recipient)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list outbox emails: %v", err)
+	}
+
+	result := []*repository.OutboxEmail{}
+
+	for _, email := range emails {
+		outboxEmail, err := outboxEmailFromRow(email)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, outboxEmail)
+	}
+
+	return result, nil
+}
+
+func (e *EmailOutboxRepository) MarkEmailSent(ctx context.Context, id uint32) error {
+	if err := e.queries.MarkOutboxEmailSent(ctx, id); err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update outbox email: %v", err)
+	}
+
+	return nil
+}
+
+func (e *EmailOutboxRepository) MarkEmailFailed(ctx context.Context, id uint32, reason string, retryAt time.Time) error {
+	if len(reason) > maxEmailErrorLength {
+		reason = reason[:maxEmailErrorLength]
+	}
+
+	if err := e.queries.MarkOutboxEmailFailed(ctx, generated.MarkOutboxEmailFailedParams{
+		LastError:     reason,
+		MaxAttempts:   e.db.config.EMAIL_MAX_ATTEMPTS,
+		NextAttemptAt: retryAt,
+		ID:            id,
+	}); err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update outbox email: %v", err)
+	}
+
+	return nil
+}
+
+func outboxEmailFromRow(email generated.EmailOutbox) (*repository.OutboxEmail, error) {
+	var headers map[string]string
+
+	if len(email.Headers) > 0 {
+		if err := json.Unmarshal(email.Headers, &headers); err != nil {
+			return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to unmarshal headers: %v", err)
+		}
+	}
+
+	return &repository.OutboxEmail{
+		ID:            email.ID,
+		To:            email.Recipient,
+		Subject:       email.Subject,
+		Template:      email.Template,
+		Text:          email.TextBody,
+		HTML:          email.HtmlBody,
+		Headers:       headers,
+		Status:        email.Status,
+		Attempts:      email.Attempts,
+		LastError:     email.LastError,
+		NextAttemptAt: email.NextAttemptAt,
+		SentAt:        timePtr(email.SentAt),
+		UpdatedAt:     email.UpdatedAt,
+		CreatedAt:     email.CreatedAt,
+	}, nil
+}
@@ -13,17 +13,23 @@ import (
 
 const createBlog = `-- name: CreateBlog :execresult
 INSERT INTO blogs (
-  author, title, content, img_urls
+  author, title, content, content_html, excerpt, reading_minutes, img_urls, status, slug, publish_at
 ) VALUES (
-  ?, ?, ?, ?
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 )
 `
 
 type CreateBlogParams struct {
-	Author  uint32          `json:"author"`
-	Title   string          `json:"title"`
-	Content string          `json:"content"`
-	ImgUrls json.RawMessage `json:"img_urls"`
+	Author         uint32          `json:"author"`
+	Title          string          `json:"title"`
+	Content        string          `json:"content"`
+	ContentHtml    string          `json:"content_html"`
+	Excerpt        string          `json:"excerpt"`
+	ReadingMinutes uint32          `json:"reading_minutes"`
+	ImgUrls        json.RawMessage `json:"img_urls"`
+	Status         string          `json:"status"`
+	Slug           string          `json:"slug"`
+	PublishAt      sql.NullTime    `json:"publish_at"`
 }
 
 func (q *Queries) CreateBlog(ctx context.Context, arg CreateBlogParams) (sql.Result, error) {
@@ -31,10 +37,27 @@ func (q *Queries) CreateBlog(ctx context.Context, arg CreateBlogParams) (sql.Res
 		arg.Author,
 		arg.Title,
 		arg.Content,
+		arg.ContentHtml,
+		arg.Excerpt,
+		arg.ReadingMinutes,
 		arg.ImgUrls,
+		arg.Status,
+		arg.Slug,
+		arg.PublishAt,
 	)
 }
 
+const decrementBlogCommentCount = `-- name: DecrementBlogCommentCount :exec
+UPDATE blogs
+  set comment_count = comment_count - 1
+WHERE id = ? AND comment_count > 0
+`
+
+func (q *Queries) DecrementBlogCommentCount(ctx context.Context, id uint32) error {
+	_, err := q.db.ExecContext(ctx, decrementBlogCommentCount, id)
+	return err
+}
+
 const deleteBlog = `-- name: DeleteBlog :exec
 DELETE FROM blogs
 WHERE id = ?
@@ -46,7 +69,7 @@ func (q *Queries) DeleteBlog(ctx context.Context, id uint32) error {
 }
 
 const getBlog = `-- name: GetBlog :one
-SELECT id, author, title, content, img_urls, created_at FROM blogs
+SELECT id, author, title, content, img_urls, created_at, status, slug, publish_at, updated_at, content_html, excerpt, reading_minutes, comment_count, announced FROM blogs
 WHERE id = ? LIMIT 1
 `
 
@@ -60,13 +83,23 @@ func (q *Queries) GetBlog(ctx context.Context, id uint32) (Blog, error) {
 		&i.Content,
 		&i.ImgUrls,
 		&i.CreatedAt,
+		&i.Status,
+		&i.Slug,
+		&i.PublishAt,
+		&i.UpdatedAt,
+		&i.ContentHtml,
+		&i.Excerpt,
+		&i.ReadingMinutes,
+		&i.CommentCount,
+		&i.Announced,
 	)
 	return i, err
 }
 
 const getBlogsByAuthor = `-- name: GetBlogsByAuthor :many
-SELECT id, author, title, content, img_urls, created_at FROM blogs
-WHERE author = ?
+SELECT id, author, title, content, img_urls, created_at, status, slug, publish_at, updated_at, content_html, excerpt, reading_minutes, comment_count, announced FROM blogs
+WHERE author = ? AND status = 'PUBLISHED' AND publish_at <= CURRENT_TIMESTAMP
+ORDER BY publish_at DESC
 `
 
 func (q *Queries) GetBlogsByAuthor(ctx context.Context, author uint32) ([]Blog, error) {
@@ -85,6 +118,133 @@ func (q *Queries) GetBlogsByAuthor(ctx context.Context, author uint32) ([]Blog,
 			&i.Content,
 			&i.ImgUrls,
 			&i.CreatedAt,
+			&i.Status,
+			&i.Slug,
+			&i.PublishAt,
+			&i.UpdatedAt,
+			&i.ContentHtml,
+			&i.Excerpt,
+			&i.ReadingMinutes,
+			&i.CommentCount,
+			&i.Announced,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPublishedBlog = `-- name: GetPublishedBlog :one
+SELECT id, author, title, content, img_urls, created_at, status, slug, publish_at, updated_at, content_html, excerpt, reading_minutes, comment_count, announced FROM blogs
+WHERE id = ? AND status = 'PUBLISHED' AND publish_at <= CURRENT_TIMESTAMP LIMIT 1
+`
+
+func (q *Queries) GetPublishedBlog(ctx context.Context, id uint32) (Blog, error) {
+	row := q.db.QueryRowContext(ctx, getPublishedBlog, id)
+	var i Blog
+	err := row.Scan(
+		&i.ID,
+		&i.Author,
+		&i.Title,
+		&i.Content,
+		&i.ImgUrls,
+		&i.CreatedAt,
+		&i.Status,
+		&i.Slug,
+		&i.PublishAt,
+		&i.UpdatedAt,
+		&i.ContentHtml,
+		&i.Excerpt,
+		&i.ReadingMinutes,
+		&i.CommentCount,
+		&i.Announced,
+	)
+	return i, err
+}
+
+const getPublishedBlogBySlug = `-- name: GetPublishedBlogBySlug :one
+SELECT id, author, title, content, img_urls, created_at, status, slug, publish_at, updated_at, content_html, excerpt, reading_minutes, comment_count, announced FROM blogs
+WHERE slug = ? AND status = 'PUBLISHED' AND publish_at <= CURRENT_TIMESTAMP LIMIT 1
+`
+
+func (q *Queries) GetPublishedBlogBySlug(ctx context.Context, slug string) (Blog, error) {
+	row := q.db.QueryRowContext(ctx, getPublishedBlogBySlug, slug)
+	var i Blog
+	err := row.Scan(
+		&i.ID,
+		&i.Author,
+		&i.Title,
+		&i.Content,
+		&i.ImgUrls,
+		&i.CreatedAt,
+		&i.Status,
+		&i.Slug,
+		&i.PublishAt,
+		&i.UpdatedAt,
+		&i.ContentHtml,
+		&i.Excerpt,
+		&i.ReadingMinutes,
+		&i.CommentCount,
+		&i.Announced,
+	)
+	return i, err
+}
+
+const incrementBlogCommentCount = `-- name: IncrementBlogCommentCount :exec
+UPDATE blogs
+  set comment_count = comment_count + 1
+WHERE id = ?
+`
+
+func (q *Queries) IncrementBlogCommentCount(ctx context.Context, id uint32) error {
+	_, err := q.db.ExecContext(ctx, incrementBlogCommentCount, id)
+	return err
+}
+
+const listAuthorBlogs = `-- name: ListAuthorBlogs :many
+SELECT id, author, title, content, img_urls, created_at, status, slug, publish_at, updated_at, content_html, excerpt, reading_minutes, comment_count, announced FROM blogs
+WHERE author = ?
+  AND (? IS NULL OR status = ?)
+ORDER BY updated_at DESC
+`
+
+type ListAuthorBlogsParams struct {
+	Author uint32         `json:"author"`
+	Status sql.NullString `json:"status"`
+}
+
+func (q *Queries) ListAuthorBlogs(ctx context.Context, arg ListAuthorBlogsParams) ([]Blog, error) {
+	rows, err := q.db.QueryContext(ctx, listAuthorBlogs, arg.Author, arg.Status, arg.Status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Blog
+	for rows.Next() {
+		var i Blog
+		if err := rows.Scan(
+			&i.ID,
+			&i.Author,
+			&i.Title,
+			&i.Content,
+			&i.ImgUrls,
+			&i.CreatedAt,
+			&i.Status,
+			&i.Slug,
+			&i.PublishAt,
+			&i.UpdatedAt,
+			&i.ContentHtml,
+			&i.Excerpt,
+			&i.ReadingMinutes,
+			&i.CommentCount,
+			&i.Announced,
 		); err != nil {
 			return nil, err
 		}
@@ -100,8 +260,9 @@ func (q *Queries) GetBlogsByAuthor(ctx context.Context, author uint32) ([]Blog,
 }
 
 const listBlogs = `-- name: ListBlogs :many
-SELECT id, author, title, content, img_urls, created_at FROM blogs
-ORDER BY created_at DESC
+SELECT id, author, title, content, img_urls, created_at, status, slug, publish_at, updated_at, content_html, excerpt, reading_minutes, comment_count, announced FROM blogs
+WHERE status = 'PUBLISHED' AND publish_at <= CURRENT_TIMESTAMP
+ORDER BY publish_at DESC
 `
 
 func (q *Queries) ListBlogs(ctx context.Context) ([]Blog, error) {
@@ -120,6 +281,138 @@ func (q *Queries) ListBlogs(ctx context.Context) ([]Blog, error) {
 			&i.Content,
 			&i.ImgUrls,
 			&i.CreatedAt,
+			&i.Status,
+			&i.Slug,
+			&i.PublishAt,
+			&i.UpdatedAt,
+			&i.ContentHtml,
+			&i.Excerpt,
+			&i.ReadingMinutes,
+			&i.CommentCount,
+			&i.Announced,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnannouncedBlogs = `-- name: ListUnannouncedBlogs :many
+SELECT id, author, title, content, img_urls, created_at, status, slug, publish_at, updated_at, content_html, excerpt, reading_minutes, comment_count, announced FROM blogs
+WHERE status = 'PUBLISHED' AND announced = false AND publish_at <= CURRENT_TIMESTAMP
+ORDER BY publish_at
+`
+
+func (q *Queries) ListUnannouncedBlogs(ctx context.Context) ([]Blog, error) {
+	rows, err := q.db.QueryContext(ctx, listUnannouncedBlogs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Blog
+	for rows.Next() {
+		var i Blog
+		if err := rows.Scan(
+			&i.ID,
+			&i.Author,
+			&i.Title,
+			&i.Content,
+			&i.ImgUrls,
+			&i.CreatedAt,
+			&i.Status,
+			&i.Slug,
+			&i.PublishAt,
+			&i.UpdatedAt,
+			&i.ContentHtml,
+			&i.Excerpt,
+			&i.ReadingMinutes,
+			&i.CommentCount,
+			&i.Announced,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markBlogAnnounced = `-- name: MarkBlogAnnounced :exec
+UPDATE blogs
+  set announced = true
+WHERE id = ?
+`
+
+func (q *Queries) MarkBlogAnnounced(ctx context.Context, id uint32) error {
+	_, err := q.db.ExecContext(ctx, markBlogAnnounced, id)
+	return err
+}
+
+const searchBlogs = `-- name: SearchBlogs :many
+SELECT id, author, title, content, img_urls, created_at, status, slug, publish_at, updated_at, content_html, excerpt, reading_minutes, comment_count, announced FROM blogs
+WHERE status = 'PUBLISHED' AND publish_at <= CURRENT_TIMESTAMP
+  AND (? IS NULL OR EXISTS (
+    SELECT 1 FROM blog_tags
+    JOIN tags ON tags.id = blog_tags.tag_id
+    WHERE blog_tags.blog_id = blogs.id AND tags.name = ?
+  ))
+  AND (? IS NULL OR MATCH(title, content) AGAINST (? IN NATURAL LANGUAGE MODE))
+ORDER BY MATCH(title, content) AGAINST (COALESCE(?, '') IN NATURAL LANGUAGE MODE) DESC, publish_at DESC, id DESC
+LIMIT ? OFFSET ?
+`
+
+type SearchBlogsParams struct {
+	Tag    sql.NullString `json:"tag"`
+	Query  sql.NullString `json:"query"`
+	Limit  int32          `json:"limit"`
+	Offset int32          `json:"offset"`
+}
+
+func (q *Queries) SearchBlogs(ctx context.Context, arg SearchBlogsParams) ([]Blog, error) {
+	rows, err := q.db.QueryContext(ctx, searchBlogs,
+		arg.Tag,
+		arg.Tag,
+		arg.Query,
+		arg.Query,
+		arg.Query,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Blog
+	for rows.Next() {
+		var i Blog
+		if err := rows.Scan(
+			&i.ID,
+			&i.Author,
+			&i.Title,
+			&i.Content,
+			&i.ImgUrls,
+			&i.CreatedAt,
+			&i.Status,
+			&i.Slug,
+			&i.PublishAt,
+			&i.UpdatedAt,
+			&i.ContentHtml,
+			&i.Excerpt,
+			&i.ReadingMinutes,
+			&i.CommentCount,
+			&i.Announced,
 		); err != nil {
 			return nil, err
 		}
@@ -138,22 +431,41 @@ const updateBlog = `-- name: UpdateBlog :exec
 UPDATE blogs
   set title = coalesce(?, title),
   content = coalesce(?, content),
-  img_urls = coalesce(?, img_urls)
+  content_html = coalesce(?, content_html),
+  excerpt = coalesce(?, excerpt),
+  reading_minutes = coalesce(?, reading_minutes),
+  img_urls = coalesce(?, img_urls),
+  status = coalesce(?, status),
+  slug = coalesce(?, slug),
+  publish_at = coalesce(?, publish_at),
+  updated_at = CURRENT_TIMESTAMP
 WHERE id = ?
 `
 
 type UpdateBlogParams struct {
-	Title   sql.NullString  `json:"title"`
-	Content sql.NullString  `json:"content"`
-	ImgUrls json.RawMessage `json:"img_urls"`
-	ID      uint32          `json:"id"`
+	Title          sql.NullString  `json:"title"`
+	Content        sql.NullString  `json:"content"`
+	ContentHtml    sql.NullString  `json:"content_html"`
+	Excerpt        sql.NullString  `json:"excerpt"`
+	ReadingMinutes sql.NullInt32   `json:"reading_minutes"`
+	ImgUrls        json.RawMessage `json:"img_urls"`
+	Status         sql.NullString  `json:"status"`
+	Slug           sql.NullString  `json:"slug"`
+	PublishAt      sql.NullTime    `json:"publish_at"`
+	ID             uint32          `json:"id"`
 }
 
 func (q *Queries) UpdateBlog(ctx context.Context, arg UpdateBlogParams) error {
 	_, err := q.db.ExecContext(ctx, updateBlog,
 		arg.Title,
 		arg.Content,
+		arg.ContentHtml,
+		arg.Excerpt,
+		arg.ReadingMinutes,
 		arg.ImgUrls,
+		arg.Status,
+		arg.Slug,
+		arg.PublishAt,
 		arg.ID,
 	)
 	return err
@@ -10,13 +10,110 @@ import (
 	"time"
 )
 
+type Address struct {
+	ID            uint32 `json:"id"`
+	UserID        uint32 `json:"user_id"`
+	RecipientName string `json:"recipient_name"`
+	PhoneNumber   string `json:"phone_number"`
+	County        string `json:"county"`
+	City          string `json:"city"`
+	// street, building and house number
+	Street     string `json:"street"`
+	PostalCode string `json:"postal_code"`
+	// used when an order does not name an address
+	IsDefault bool      `json:"is_default"`
+	UpdatedAt time.Time `json:"updated_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ApiKey struct {
+	ID uint32 `json:"id"`
+	// what the key is used for e.g warehouse sync
+	Name string `json:"name"`
+	// start of the key, shown so keys can be told apart
+	Prefix string `json:"prefix"`
+	// sha256 of the key, the key itself is only shown once
+	KeyHash string `json:"key_hash"`
+	// permissions the key grants e.g ["orders:read"]
+	Scopes json.RawMessage `json:"scopes"`
+	// admin that created the key, requests act on their behalf
+	CreatedBy  uint32       `json:"created_by"`
+	ExpiresAt  sql.NullTime `json:"expires_at"`
+	LastUsedAt sql.NullTime `json:"last_used_at"`
+	RevokedAt  sql.NullTime `json:"revoked_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+type AuditLog struct {
+	ID      uint32        `json:"id"`
+	ActorID sql.NullInt32 `json:"actor_id"`
+	// CREATE, UPDATE or DELETE
+	Action     string `json:"action"`
+	EntityType string `json:"entity_type"`
+	EntityID   string `json:"entity_id"`
+	// previous values of the fields that changed
+	Before json.RawMessage `json:"before"`
+	// new values of the fields that changed
+	After     json.RawMessage `json:"after"`
+	IpAddress string          `json:"ip_address"`
+	RequestID string          `json:"request_id"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
 type Blog struct {
-	ID        uint32          `json:"id"`
-	Author    uint32          `json:"author"`
-	Title     string          `json:"title"`
+	ID     uint32 `json:"id"`
+	Author uint32 `json:"author"`
+	Title  string `json:"title"`
+	// markdown source written by the author
 	Content   string          `json:"content"`
 	ImgUrls   json.RawMessage `json:"img_urls"`
 	CreatedAt time.Time       `json:"created_at"`
+	// DRAFT, PUBLISHED or ARCHIVED
+	Status string `json:"status"`
+	// url friendly title, GET /blogs/slug/:slug
+	Slug string `json:"slug"`
+	// published blogs are public from this time
+	PublishAt sql.NullTime `json:"publish_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+	// sanitized html rendered from content
+	ContentHtml    string `json:"content_html"`
+	Excerpt        string `json:"excerpt"`
+	ReadingMinutes uint32 `json:"reading_minutes"`
+	// approved comments that are not deleted
+	CommentCount uint32 `json:"comment_count"`
+	// the blog was sent to newsletter subscribers
+	Announced bool `json:"announced"`
+}
+
+type BlogComment struct {
+	ID     uint32 `json:"id"`
+	BlogID uint32 `json:"blog_id"`
+	UserID uint32 `json:"user_id"`
+	// the comment this one replies to
+	ParentID sql.NullInt32 `json:"parent_id"`
+	Content  string        `json:"content"`
+	// PENDING, APPROVED or HIDDEN, only approved comments are shown and counted
+	Status string `json:"status"`
+	// why the comment was hidden or held by the filter
+	ModerationReason string        `json:"moderation_reason"`
+	ModeratedBy      sql.NullInt32 `json:"moderated_by"`
+	ModeratedAt      sql.NullTime  `json:"moderated_at"`
+	// deleted by the author, kept so replies stay in their thread
+	DeletedAt sql.NullTime `json:"deleted_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type BlogProduct struct {
+	BlogID    uint32 `json:"blog_id"`
+	ProductID uint32 `json:"product_id"`
+	// order the products appear in the post
+	Position uint32 `json:"position"`
+}
+
+type BlogTag struct {
+	BlogID uint32 `json:"blog_id"`
+	TagID  uint32 `json:"tag_id"`
 }
 
 type Cart struct {
@@ -33,6 +130,125 @@ type Category struct {
 	Description string `json:"description"`
 }
 
+type EmailOutbox struct {
+	ID        uint32 `json:"id"`
+	Recipient string `json:"recipient"`
+	Subject   string `json:"subject"`
+	// template the email was rendered from
+	Template string `json:"template"`
+	// emptied once sent, the body can hold one time links
+	TextBody string `json:"text_body"`
+	// emptied once sent, the body can hold one time links
+	HtmlBody string `json:"html_body"`
+	// extra message headers
+	Headers json.RawMessage `json:"headers"`
+	// PENDING, SENT or FAILED once EMAIL_MAX_ATTEMPTS is reached
+	Status        string       `json:"status"`
+	Attempts      uint32       `json:"attempts"`
+	LastError     string       `json:"last_error"`
+	NextAttemptAt time.Time    `json:"next_attempt_at"`
+	SentAt        sql.NullTime `json:"sent_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+	CreatedAt     time.Time    `json:"created_at"`
+}
+
+type LoginAttempt struct {
+	// EMAIL or IP
+	Scope string `json:"scope"`
+	// lower cased email or client ip
+	Identifier string `json:"identifier"`
+	// consecutive failures inside the attempt window
+	FailedAttempts uint32 `json:"failed_attempts"`
+	// logins are refused until this time
+	LockedUntil  sql.NullTime `json:"locked_until"`
+	LastFailedAt time.Time    `json:"last_failed_at"`
+}
+
+type NewsletterCampaign struct {
+	ID      uint32 `json:"id"`
+	Subject string `json:"subject"`
+	// markdown source written by the sender
+	Content string `json:"content"`
+	// sanitized html rendered from content
+	ContentHtml string `json:"content_html"`
+	// set on campaigns announcing a published blog
+	BlogID sql.NullInt32 `json:"blog_id"`
+	// SENDING until no delivery is pending, then SENT
+	Status         string `json:"status"`
+	RecipientCount uint32 `json:"recipient_count"`
+	// null on blog announcements
+	CreatedBy sql.NullInt32 `json:"created_by"`
+	SentAt    sql.NullTime  `json:"sent_at"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+type NewsletterDelivery struct {
+	ID         uint32 `json:"id"`
+	CampaignID uint32 `json:"campaign_id"`
+	// set on deliveries to registered users
+	UserID sql.NullInt32 `json:"user_id"`
+	// address at the time the campaign was queued
+	Email string `json:"email"`
+	// PENDING, SENT, FAILED or SKIPPED when the user unsubscribed before the send
+	Status    string       `json:"status"`
+	Attempts  uint32       `json:"attempts"`
+	LastError string       `json:"last_error"`
+	SentAt    sql.NullTime `json:"sent_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+	CreatedAt time.Time    `json:"created_at"`
+	// set on deliveries to subscribers without an account
+	SubscriberID sql.NullInt32 `json:"subscriber_id"`
+	// other workers skip the delivery until then
+	ClaimedUntil sql.NullTime `json:"claimed_until"`
+}
+
+type NewsletterSubscriber struct {
+	ID    uint32 `json:"id"`
+	Email string `json:"email"`
+	// PENDING until the address is confirmed, CONFIRMED or UNSUBSCRIBED
+	Status string `json:"status"`
+	// set once someone registers with the email, users.subscription decides from then on
+	UserID sql.NullInt32 `json:"user_id"`
+	// sha256 of the confirmation token, the token itself is only emailed
+	ConfirmTokenHash sql.NullString `json:"confirm_token_hash"`
+	ConfirmExpiresAt sql.NullTime   `json:"confirm_expires_at"`
+	ConfirmedAt      sql.NullTime   `json:"confirmed_at"`
+	UnsubscribedAt   sql.NullTime   `json:"unsubscribed_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	CreatedAt        time.Time      `json:"created_at"`
+}
+
+type Notification struct {
+	ID     uint32 `json:"id"`
+	UserID uint32 `json:"user_id"`
+	// REVIEW_APPROVED, REVIEW_REJECTED, ...
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Message   string       `json:"message"`
+	ReadAt    sql.NullTime `json:"read_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type NotificationPreference struct {
+	UserID uint32 `json:"user_id"`
+	// email when an order is placed, paid or changes status
+	OrderEmail bool `json:"order_email"`
+	// sms to users.phone_number for the same order updates
+	OrderSms  bool      `json:"order_sms"`
+	UpdatedAt time.Time `json:"updated_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type OauthState struct {
+	State    string `json:"state"`
+	Provider string `json:"provider"`
+	// pkce verifier, never sent to the browser
+	CodeVerifier string    `json:"code_verifier"`
+	Nonce        string    `json:"nonce"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
 type Order struct {
 	ID     uint32 `json:"id"`
 	UserID uint32 `json:"user_id"`
@@ -46,6 +262,8 @@ type Order struct {
 	UpdatedBy       uint32    `json:"updated_by"`
 	UpdatedAt       time.Time `json:"updated_at"`
 	CreatedAt       time.Time `json:"created_at"`
+	// address the order was shipped to, shipping_address keeps the snapshot
+	AddressID sql.NullInt32 `json:"address_id"`
 }
 
 type OrderItem struct {
@@ -57,6 +275,24 @@ type OrderItem struct {
 	Size      string  `json:"size"`
 }
 
+type PasswordToken struct {
+	// sha256 of the token, the token itself is only handed out once
+	TokenHash string `json:"token_hash"`
+	UserID    uint32 `json:"user_id"`
+	// RESET or INVITE
+	Purpose   string    `json:"purpose"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// admin that forced the reset or sent the invite
+	CreatedBy sql.NullInt32 `json:"created_by"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+type Permission struct {
+	// resource:action e.g products:write
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
 type Product struct {
 	ID              uint32          `json:"id"`
 	Name            string          `json:"name"`
@@ -77,6 +313,22 @@ type Product struct {
 	CreatedAt time.Time       `json:"created_at"`
 }
 
+type ProductReviewSummary struct {
+	ProductID uint32 `json:"product_id"`
+	// approved reviews only
+	ReviewCount int32 `json:"review_count"`
+	// sum of the ratings, average is rating_total / review_count
+	RatingTotal int32 `json:"rating_total"`
+	Star1       int32 `json:"star_1"`
+	Star2       int32 `json:"star_2"`
+	Star3       int32 `json:"star_3"`
+	Star4       int32 `json:"star_4"`
+	Star5       int32 `json:"star_5"`
+	// reviews rated 4 or 5
+	RecommendCount int32     `json:"recommend_count"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
 type Review struct {
 	ID        uint32    `json:"id"`
 	UserID    uint32    `json:"user_id"`
@@ -84,6 +336,61 @@ type Review struct {
 	Rating    uint32    `json:"rating"`
 	Review    string    `json:"review"`
 	CreatedAt time.Time `json:"created_at"`
+	// the author had a delivered order with the product when reviewing
+	VerifiedPurchase bool      `json:"verified_purchase"`
+	UpdatedAt        time.Time `json:"updated_at"`
+	// PENDING, APPROVED or REJECTED, only approved reviews are shown and rated
+	Status string `json:"status"`
+	// why the review was rejected or held by the filter
+	ModerationReason string        `json:"moderation_reason"`
+	ModeratedBy      sql.NullInt32 `json:"moderated_by"`
+	ModeratedAt      sql.NullTime  `json:"moderated_at"`
+	// photos of the item the customer received
+	ImgUrls json.RawMessage `json:"img_urls"`
+	// will be updated anytime a helpful vote is added or removed
+	HelpfulCount uint32 `json:"helpful_count"`
+	// public answer from the shop
+	Reply     sql.NullString `json:"reply"`
+	RepliedBy sql.NullInt32  `json:"replied_by"`
+	RepliedAt sql.NullTime   `json:"replied_at"`
+}
+
+type ReviewVote struct {
+	ReviewID  uint32    `json:"review_id"`
+	UserID    uint32    `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Role struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type RolePermission struct {
+	Role       string `json:"role"`
+	Permission string `json:"permission"`
+}
+
+type SecurityEvent struct {
+	ID uint32 `json:"id"`
+	// LOGIN_FAILED, LOGIN_BLOCKED, ACCOUNT_LOCKED, ...
+	Event string `json:"event"`
+	// account the event is about, null for unknown emails
+	UserID sql.NullInt32 `json:"user_id"`
+	// user that performed the action when not the account owner
+	ActorID   sql.NullInt32 `json:"actor_id"`
+	Email     string        `json:"email"`
+	IpAddress string        `json:"ip_address"`
+	Details   string        `json:"details"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+type Tag struct {
+	ID uint32 `json:"id"`
+	// lower case and url friendly, GET /blogs?tag=
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type Transaction struct {
@@ -106,10 +413,44 @@ type User struct {
 	Password string `json:"password"`
 	// subscription to our blog posts
 	Subscription bool `json:"subscription"`
-	// USER or ADMIN
+	// name of a row in roles
 	Role         string        `json:"role"`
 	RefreshToken string        `json:"refresh_token"`
 	UpdatedBy    sql.NullInt32 `json:"updated_by"`
 	UpdatedAt    time.Time     `json:"updated_at"`
 	CreatedAt    time.Time     `json:"created_at"`
+	FullName     string        `json:"full_name"`
+	PhoneNumber  string        `json:"phone_number"`
+	// set when the account was anonymised, the row stays for orders and transactions
+	DeletedAt sql.NullTime `json:"deleted_at"`
+	// set while an admin has disabled the account
+	DisabledAt sql.NullTime `json:"disabled_at"`
+	// login is refused until the password is reset
+	PasswordResetRequired bool `json:"password_reset_required"`
+	// tokens issued before this are refused
+	TokensValidAfter sql.NullTime `json:"tokens_valid_after"`
+}
+
+type UserIdentity struct {
+	Provider string `json:"provider"`
+	// sub claim of the provider id token
+	Subject string `json:"subject"`
+	UserID  uint32 `json:"user_id"`
+	// email the provider reported when linked
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type UserTwoFactor struct {
+	UserID uint32 `json:"user_id"`
+	// base32 totp secret
+	Secret string `json:"secret"`
+	// false until the first code is verified
+	Enabled bool `json:"enabled"`
+	// sha256 hashes of unused recovery codes
+	RecoveryCodes json.RawMessage `json:"recovery_codes"`
+	// last accepted totp step, prevents code reuse
+	LastUsedStep uint64    `json:"last_used_step"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	CreatedAt    time.Time `json:"created_at"`
 }
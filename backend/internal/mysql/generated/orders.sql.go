@@ -10,20 +10,33 @@ import (
 	"database/sql"
 )
 
+const countUserOpenOrders = `-- name: CountUserOpenOrders :one
+SELECT COUNT(*) FROM orders
+WHERE user_id = ? AND status != 'DELIVERED'
+`
+
+func (q *Queries) CountUserOpenOrders(ctx context.Context, userID uint32) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUserOpenOrders, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createOrder = `-- name: CreateOrder :execresult
 INSERT INTO orders (
-  user_id, amount, shipping_address, shipping_amount, updated_by
+  user_id, amount, shipping_address, shipping_amount, updated_by, address_id
 ) VALUES (
-  ?, ?, ?, ?, ?
+  ?, ?, ?, ?, ?, ?
 )
 `
 
 type CreateOrderParams struct {
-	UserID          uint32  `json:"user_id"`
-	Amount          float64 `json:"amount"`
-	ShippingAddress string  `json:"shipping_address"`
-	ShippingAmount  float64 `json:"shipping_amount"`
-	UpdatedBy       uint32  `json:"updated_by"`
+	UserID          uint32        `json:"user_id"`
+	Amount          float64       `json:"amount"`
+	ShippingAddress string        `json:"shipping_address"`
+	ShippingAmount  float64       `json:"shipping_amount"`
+	UpdatedBy       uint32        `json:"updated_by"`
+	AddressID       sql.NullInt32 `json:"address_id"`
 }
 
 func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (sql.Result, error) {
@@ -33,6 +46,7 @@ func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (sql.R
 		arg.ShippingAddress,
 		arg.ShippingAmount,
 		arg.UpdatedBy,
+		arg.AddressID,
 	)
 }
 
@@ -47,7 +61,7 @@ func (q *Queries) DeleteOrder(ctx context.Context, id uint32) error {
 }
 
 const getOrder = `-- name: GetOrder :one
-SELECT id, user_id, amount, shipping_amount, status, shipping_address, updated_by, updated_at, created_at FROM orders
+SELECT id, user_id, amount, shipping_amount, status, shipping_address, updated_by, updated_at, created_at, address_id FROM orders
 WHERE id = ?
 `
 
@@ -64,12 +78,13 @@ func (q *Queries) GetOrder(ctx context.Context, id uint32) (Order, error) {
 		&i.UpdatedBy,
 		&i.UpdatedAt,
 		&i.CreatedAt,
+		&i.AddressID,
 	)
 	return i, err
 }
 
 const listOrderWithStatus = `-- name: ListOrderWithStatus :many
-SELECT id, user_id, amount, shipping_amount, status, shipping_address, updated_by, updated_at, created_at FROM orders
+SELECT id, user_id, amount, shipping_amount, status, shipping_address, updated_by, updated_at, created_at, address_id FROM orders
 WHERE status = ?
 ORDER BY created_at DESC
 `
@@ -93,6 +108,7 @@ func (q *Queries) ListOrderWithStatus(ctx context.Context, status string) ([]Ord
 			&i.UpdatedBy,
 			&i.UpdatedAt,
 			&i.CreatedAt,
+			&i.AddressID,
 		); err != nil {
 			return nil, err
 		}
@@ -108,7 +124,7 @@ func (q *Queries) ListOrderWithStatus(ctx context.Context, status string) ([]Ord
 }
 
 const listOrders = `-- name: ListOrders :many
-SELECT id, user_id, amount, shipping_amount, status, shipping_address, updated_by, updated_at, created_at FROM orders
+SELECT id, user_id, amount, shipping_amount, status, shipping_address, updated_by, updated_at, created_at, address_id FROM orders
 ORDER BY created_at DESC
 `
 
@@ -131,6 +147,7 @@ func (q *Queries) ListOrders(ctx context.Context) ([]Order, error) {
 			&i.UpdatedBy,
 			&i.UpdatedAt,
 			&i.CreatedAt,
+			&i.AddressID,
 		); err != nil {
 			return nil, err
 		}
@@ -146,7 +163,7 @@ func (q *Queries) ListOrders(ctx context.Context) ([]Order, error) {
 }
 
 const listUserOrders = `-- name: ListUserOrders :many
-SELECT id, user_id, amount, shipping_amount, status, shipping_address, updated_by, updated_at, created_at FROM orders
+SELECT id, user_id, amount, shipping_amount, status, shipping_address, updated_by, updated_at, created_at, address_id FROM orders
 WHERE user_id = ?
 ORDER BY created_at DESC
 `
@@ -170,6 +187,7 @@ func (q *Queries) ListUserOrders(ctx context.Context, userID uint32) ([]Order, e
 			&i.UpdatedBy,
 			&i.UpdatedAt,
 			&i.CreatedAt,
+			&i.AddressID,
 		); err != nil {
 			return nil, err
 		}
@@ -184,6 +202,23 @@ func (q *Queries) ListUserOrders(ctx context.Context, userID uint32) ([]Order, e
 	return items, nil
 }
 
+const redactUserOrders = `-- name: RedactUserOrders :exec
+UPDATE orders
+  set shipping_address = ?,
+  address_id = NULL
+WHERE user_id = ?
+`
+
+type RedactUserOrdersParams struct {
+	ShippingAddress string `json:"shipping_address"`
+	UserID          uint32 `json:"user_id"`
+}
+
+func (q *Queries) RedactUserOrders(ctx context.Context, arg RedactUserOrdersParams) error {
+	_, err := q.db.ExecContext(ctx, redactUserOrders, arg.ShippingAddress, arg.UserID)
+	return err
+}
+
 const updateOrderStatus = `-- name: UpdateOrderStatus :exec
 UPDATE orders
   set status = ?,
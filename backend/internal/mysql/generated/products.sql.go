@@ -476,11 +476,11 @@ func (q *Queries) UpdateProductQuantity(ctx context.Context, arg UpdateProductQu
 
 const updateRating = `-- name: UpdateRating :exec
 UPDATE products
-SET rating = (
-    SELECT AVG(rating) 
-    FROM reviews
-    WHERE reviews.product_id = products.id
-)
+SET rating = COALESCE((
+    SELECT rating_total / review_count
+    FROM product_review_summaries
+    WHERE product_review_summaries.product_id = products.id AND review_count > 0
+), 0)
 WHERE products.id = ?
 `
 
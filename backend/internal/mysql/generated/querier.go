@@ -11,70 +11,214 @@ import (
 )
 
 type Querier interface {
+	AddBlogProduct(ctx context.Context, arg AddBlogProductParams) error
+	AddBlogTag(ctx context.Context, arg AddBlogTagParams) error
+	AdjustReviewSummary(ctx context.Context, arg AdjustReviewSummaryParams) error
+	AnonymiseUser(ctx context.Context, arg AnonymiseUserParams) error
+	CheckRolePermission(ctx context.Context, arg CheckRolePermissionParams) (int64, error)
 	CheckUsersCartExists(ctx context.Context, arg CheckUsersCartExistsParams) (Cart, error)
+	ClaimDelivery(ctx context.Context, arg ClaimDeliveryParams) error
+	ClaimOutboxEmail(ctx context.Context, arg ClaimOutboxEmailParams) error
+	ClearDefaultAddress(ctx context.Context, userID uint32) error
+	ConfirmSubscriber(ctx context.Context, id uint32) error
+	CountUserAddresses(ctx context.Context, userID uint32) (int64, error)
+	CountUserCommentsSince(ctx context.Context, arg CountUserCommentsSinceParams) (int64, error)
+	CountUserOpenOrders(ctx context.Context, userID uint32) (int64, error)
+	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (sql.Result, error)
+	CreateAddress(ctx context.Context, arg CreateAddressParams) (sql.Result, error)
+	CreateAuditEntry(ctx context.Context, arg CreateAuditEntryParams) error
 	CreateBlog(ctx context.Context, arg CreateBlogParams) (sql.Result, error)
+	CreateCampaign(ctx context.Context, arg CreateCampaignParams) (sql.Result, error)
 	CreateCart(ctx context.Context, arg CreateCartParams) (sql.Result, error)
 	CreateCategory(ctx context.Context, arg CreateCategoryParams) (sql.Result, error)
+	CreateComment(ctx context.Context, arg CreateCommentParams) (sql.Result, error)
+	CreateNotification(ctx context.Context, arg CreateNotificationParams) (sql.Result, error)
+	CreateOAuthState(ctx context.Context, arg CreateOAuthStateParams) error
 	CreateOrder(ctx context.Context, arg CreateOrderParams) (sql.Result, error)
 	CreateOrderItem(ctx context.Context, arg CreateOrderItemParams) (sql.Result, error)
+	CreateOutboxEmail(ctx context.Context, arg CreateOutboxEmailParams) (sql.Result, error)
+	CreatePasswordToken(ctx context.Context, arg CreatePasswordTokenParams) error
 	CreateProduct(ctx context.Context, arg CreateProductParams) (sql.Result, error)
 	CreateReview(ctx context.Context, arg CreateReviewParams) (sql.Result, error)
+	CreateReviewVote(ctx context.Context, arg CreateReviewVoteParams) error
+	CreateRole(ctx context.Context, arg CreateRoleParams) error
+	CreateRolePermission(ctx context.Context, arg CreateRolePermissionParams) error
+	CreateSecurityEvent(ctx context.Context, arg CreateSecurityEventParams) error
+	CreateSubscriber(ctx context.Context, arg CreateSubscriberParams) (sql.Result, error)
+	CreateTransaction(ctx context.Context, arg CreateTransactionParams) (sql.Result, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (sql.Result, error)
+	CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) error
+	CreateUserTwoFactor(ctx context.Context, arg CreateUserTwoFactorParams) error
+	DecrementBlogCommentCount(ctx context.Context, id uint32) error
+	DeleteAddress(ctx context.Context, arg DeleteAddressParams) error
 	DeleteBlog(ctx context.Context, id uint32) error
+	DeleteBlogProducts(ctx context.Context, blogID uint32) error
+	DeleteBlogTags(ctx context.Context, blogID uint32) error
 	DeleteCategory(ctx context.Context, id uint32) error
+	DeleteComment(ctx context.Context, id uint32) error
+	DeleteExpiredOAuthStates(ctx context.Context, expiresAt time.Time) error
+	DeleteLoginAttempt(ctx context.Context, arg DeleteLoginAttemptParams) error
+	DeleteNotificationPreferences(ctx context.Context, userID uint32) error
+	DeleteOAuthState(ctx context.Context, state string) error
 	DeleteOrder(ctx context.Context, id uint32) error
 	DeleteOrderOrderItems(ctx context.Context, orderID uint32) error
 	DeleteProduct(ctx context.Context, id uint32) error
+	DeleteRecipientOutboxEmails(ctx context.Context, recipient string) error
 	DeleteReview(ctx context.Context, id uint32) error
-	DeleteUser(ctx context.Context, id uint32) error
+	DeleteReviewVote(ctx context.Context, arg DeleteReviewVoteParams) (sql.Result, error)
+	DeleteRole(ctx context.Context, name string) error
+	DeleteRolePermissions(ctx context.Context, role string) error
+	DeleteUserAPIKeys(ctx context.Context, createdBy uint32) error
+	DeleteUserAddresses(ctx context.Context, userID uint32) error
 	DeleteUserCart(ctx context.Context, userID uint32) error
+	DeleteUserIdentities(ctx context.Context, userID uint32) error
+	DeleteUserNotifications(ctx context.Context, userID uint32) error
+	DeleteUserPasswordTokens(ctx context.Context, userID uint32) error
+	DeleteUserReviewVotes(ctx context.Context, userID uint32) error
+	DeleteUserSubscriber(ctx context.Context, userID sql.NullInt32) error
+	DeleteUserTwoFactor(ctx context.Context, userID uint32) error
+	EnableUserTwoFactor(ctx context.Context, arg EnableUserTwoFactorParams) error
+	FinishCampaigns(ctx context.Context) error
+	GetAPIKey(ctx context.Context, id uint32) (ApiKey, error)
+	GetActiveAPIKeyByHash(ctx context.Context, arg GetActiveAPIKeyByHashParams) (GetActiveAPIKeyByHashRow, error)
 	GetBlog(ctx context.Context, id uint32) (Blog, error)
 	GetBlogsByAuthor(ctx context.Context, author uint32) ([]Blog, error)
+	GetCampaign(ctx context.Context, id uint32) (NewsletterCampaign, error)
+	GetCampaignStats(ctx context.Context, campaignID uint32) (GetCampaignStatsRow, error)
 	GetCategory(ctx context.Context, id uint32) (Category, error)
+	GetComment(ctx context.Context, id uint32) (GetCommentRow, error)
+	GetDefaultAddress(ctx context.Context, userID uint32) (Address, error)
+	GetLoginAttempt(ctx context.Context, arg GetLoginAttemptParams) (LoginAttempt, error)
+	GetNotificationPreferences(ctx context.Context, userID uint32) (NotificationPreference, error)
+	GetOAuthState(ctx context.Context, state string) (OauthState, error)
 	GetOrder(ctx context.Context, id uint32) (Order, error)
 	GetOrderOrderItems(ctx context.Context, orderID uint32) ([]OrderItem, error)
+	GetPasswordToken(ctx context.Context, tokenHash string) (PasswordToken, error)
 	GetProduct(ctx context.Context, id uint32) (Product, error)
 	GetProductName(ctx context.Context, id uint32) (string, error)
 	GetProductOrderItems(ctx context.Context, productID uint32) ([]OrderItem, error)
 	GetProductQuantity(ctx context.Context, id uint32) (uint32, error)
+	GetProductReviewSummary(ctx context.Context, productID uint32) (ProductReviewSummary, error)
+	GetPublishedBlog(ctx context.Context, id uint32) (Blog, error)
+	GetPublishedBlogBySlug(ctx context.Context, slug string) (Blog, error)
 	GetReview(ctx context.Context, id uint32) (Review, error)
+	GetReviewForUpdate(ctx context.Context, id uint32) (Review, error)
+	GetRole(ctx context.Context, name string) (Role, error)
 	GetSubscribedUsers(ctx context.Context) ([]User, error)
+	GetSubscriber(ctx context.Context, id uint32) (NewsletterSubscriber, error)
+	GetSubscriberByEmail(ctx context.Context, email string) (NewsletterSubscriber, error)
+	GetSubscriberByToken(ctx context.Context, confirmTokenHash sql.NullString) (NewsletterSubscriber, error)
+	GetTransaction(ctx context.Context, id uint32) (Transaction, error)
+	GetUserAddress(ctx context.Context, arg GetUserAddressParams) (Address, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
 	GetUserById(ctx context.Context, id uint32) (User, error)
 	GetUserEmail(ctx context.Context, id uint32) (string, error)
+	GetUserIdentity(ctx context.Context, arg GetUserIdentityParams) (UserIdentity, error)
+	GetUserProductReview(ctx context.Context, arg GetUserProductReviewParams) (Review, error)
+	GetUserTwoFactor(ctx context.Context, userID uint32) (UserTwoFactor, error)
+	GetUserTwoFactorForUpdate(ctx context.Context, userID uint32) (UserTwoFactor, error)
+	HasDeliveredOrderWithProduct(ctx context.Context, arg HasDeliveredOrderWithProductParams) (bool, error)
+	IncrementBlogCommentCount(ctx context.Context, id uint32) error
+	LinkSubscriberUser(ctx context.Context, arg LinkSubscriberUserParams) error
+	ListAPIKeys(ctx context.Context) ([]ApiKey, error)
+	ListAuditEntries(ctx context.Context, arg ListAuditEntriesParams) ([]AuditLog, error)
+	ListAuthorBlogs(ctx context.Context, arg ListAuthorBlogsParams) ([]Blog, error)
+	ListBlogComments(ctx context.Context, blogID uint32) ([]ListBlogCommentsRow, error)
+	ListBlogProducts(ctx context.Context, blogID uint32) ([]Product, error)
+	ListBlogTags(ctx context.Context, blogID uint32) ([]string, error)
 	ListBlogs(ctx context.Context) ([]Blog, error)
+	ListCampaignDeliveries(ctx context.Context, arg ListCampaignDeliveriesParams) ([]NewsletterDelivery, error)
+	ListCampaigns(ctx context.Context, arg ListCampaignsParams) ([]NewsletterCampaign, error)
 	ListCart(ctx context.Context) ([]Cart, error)
 	ListCartByUser(ctx context.Context) ([]ListCartByUserRow, error)
 	ListCategories(ctx context.Context) ([]Category, error)
+	ListCommentsByStatus(ctx context.Context, arg ListCommentsByStatusParams) ([]ListCommentsByStatusRow, error)
 	ListDiscountedProducts(ctx context.Context) ([]Product, error)
+	ListDueOutboxEmails(ctx context.Context, limit int32) ([]EmailOutbox, error)
 	ListFeaturedProducts(ctx context.Context) ([]Product, error)
+	ListLockedLoginAttempts(ctx context.Context, lockedUntil sql.NullTime) ([]LoginAttempt, error)
 	ListNewProducts(ctx context.Context) ([]Product, error)
 	ListOldCarts(ctx context.Context, createdAt time.Time) ([]Cart, error)
 	ListOrderItems(ctx context.Context) ([]OrderItem, error)
+	ListOrderTransactions(ctx context.Context, orderID uint32) ([]Transaction, error)
 	ListOrderWithStatus(ctx context.Context, status string) ([]Order, error)
 	ListOrders(ctx context.Context) ([]Order, error)
+	ListPendingDeliveries(ctx context.Context, limit int32) ([]ListPendingDeliveriesRow, error)
+	ListPermissions(ctx context.Context) ([]Permission, error)
 	ListProductInCarts(ctx context.Context, productID uint32) ([]Cart, error)
+	ListProductReviewSummaries(ctx context.Context) ([]ProductReviewSummary, error)
 	ListProducts(ctx context.Context) ([]Product, error)
 	ListProductsByCategory(ctx context.Context, categoryID uint32) ([]Product, error)
 	ListProductsReviews(ctx context.Context, productID uint32) ([]Review, error)
+	ListProductsReviewsByHelpful(ctx context.Context, productID uint32) ([]Review, error)
+	ListRecipientOutboxEmails(ctx context.Context, recipient string) ([]EmailOutbox, error)
 	ListReviews(ctx context.Context) ([]Review, error)
+	ListReviewsByStatus(ctx context.Context, arg ListReviewsByStatusParams) ([]Review, error)
+	ListRolePermissions(ctx context.Context, role string) ([]string, error)
+	ListRoles(ctx context.Context) ([]Role, error)
 	ListSeasonalProducts(ctx context.Context) ([]Product, error)
+	ListSecurityEvents(ctx context.Context, arg ListSecurityEventsParams) ([]SecurityEvent, error)
+	ListTags(ctx context.Context) ([]ListTagsRow, error)
+	ListUnannouncedBlogs(ctx context.Context) ([]Blog, error)
+	ListUserAddresses(ctx context.Context, userID uint32) ([]Address, error)
 	ListUserCarts(ctx context.Context, userID uint32) ([]Cart, error)
+	ListUserComments(ctx context.Context, userID uint32) ([]ListUserCommentsRow, error)
+	ListUserDeliveries(ctx context.Context, userID sql.NullInt32) ([]NewsletterDelivery, error)
+	ListUserIdentities(ctx context.Context, userID uint32) ([]UserIdentity, error)
+	ListUserNotifications(ctx context.Context, arg ListUserNotificationsParams) ([]Notification, error)
 	ListUserOrders(ctx context.Context, userID uint32) ([]Order, error)
+	ListUserReviewVotes(ctx context.Context, userID uint32) ([]uint32, error)
 	ListUsers(ctx context.Context) ([]User, error)
 	ListUsersReviews(ctx context.Context, userID uint32) ([]Review, error)
+	LockLoginAttempt(ctx context.Context, arg LockLoginAttemptParams) error
+	MarkAllNotificationsRead(ctx context.Context, arg MarkAllNotificationsReadParams) error
+	MarkBlogAnnounced(ctx context.Context, id uint32) error
+	MarkDeliveryFailed(ctx context.Context, arg MarkDeliveryFailedParams) error
+	MarkDeliverySent(ctx context.Context, id uint32) error
+	MarkDeliverySkipped(ctx context.Context, id uint32) error
+	MarkNotificationRead(ctx context.Context, arg MarkNotificationReadParams) (sql.Result, error)
+	MarkOutboxEmailFailed(ctx context.Context, arg MarkOutboxEmailFailedParams) error
+	MarkOutboxEmailSent(ctx context.Context, id uint32) error
+	ModerateComment(ctx context.Context, arg ModerateCommentParams) error
+	ModerateReview(ctx context.Context, arg ModerateReviewParams) error
+	QueueCampaignDeliveries(ctx context.Context, id uint32) (sql.Result, error)
+	QueueSubscriberDeliveries(ctx context.Context, id uint32) (sql.Result, error)
+	RecordLoginFailure(ctx context.Context, arg RecordLoginFailureParams) error
+	RedactUserDeliveries(ctx context.Context, userID sql.NullInt32) error
+	RedactUserOrders(ctx context.Context, arg RedactUserOrdersParams) error
+	RedactUserSecurityEvents(ctx context.Context, arg RedactUserSecurityEventsParams) error
 	ReduceProductQuantity(ctx context.Context, arg ReduceProductQuantityParams) error
+	RequestSubscriberConfirmation(ctx context.Context, arg RequestSubscriberConfirmationParams) error
+	RequirePasswordReset(ctx context.Context, arg RequirePasswordResetParams) error
+	RevokeAPIKey(ctx context.Context, arg RevokeAPIKeyParams) (sql.Result, error)
+	SearchBlogs(ctx context.Context, arg SearchBlogsParams) ([]Blog, error)
+	SearchUsers(ctx context.Context, arg SearchUsersParams) ([]User, error)
+	SetCampaignRecipientCount(ctx context.Context, arg SetCampaignRecipientCountParams) error
+	SetDefaultAddress(ctx context.Context, arg SetDefaultAddressParams) error
+	TouchAPIKey(ctx context.Context, arg TouchAPIKeyParams) error
+	UnsubscribeSubscriber(ctx context.Context, id uint32) error
+	UpdateAddress(ctx context.Context, arg UpdateAddressParams) error
 	UpdateBlog(ctx context.Context, arg UpdateBlogParams) error
 	UpdateCategory(ctx context.Context, arg UpdateCategoryParams) error
+	UpdateComment(ctx context.Context, arg UpdateCommentParams) error
+	UpdateHelpfulCount(ctx context.Context, id uint32) error
 	UpdateOrderStatus(ctx context.Context, arg UpdateOrderStatusParams) error
 	UpdateProduct(ctx context.Context, arg UpdateProductParams) error
 	UpdateProductQuantity(ctx context.Context, arg UpdateProductQuantityParams) error
 	UpdateRating(ctx context.Context, id uint32) error
 	UpdateRefreshToken(ctx context.Context, arg UpdateRefreshTokenParams) error
+	UpdateReview(ctx context.Context, arg UpdateReviewParams) error
+	UpdateReviewReply(ctx context.Context, arg UpdateReviewReplyParams) error
 	UpdateSubscriptionStatus(ctx context.Context, arg UpdateSubscriptionStatusParams) error
+	UpdateTwoFactorLastUsedStep(ctx context.Context, arg UpdateTwoFactorLastUsedStepParams) (int64, error)
+	UpdateTwoFactorRecoveryCodes(ctx context.Context, arg UpdateTwoFactorRecoveryCodesParams) error
 	UpdateUserCart(ctx context.Context, arg UpdateUserCartParams) error
 	UpdateUserCredentials(ctx context.Context, arg UpdateUserCredentialsParams) error
+	UpdateUserDisabled(ctx context.Context, arg UpdateUserDisabledParams) error
+	UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) error
 	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) error
+	UpsertNotificationPreferences(ctx context.Context, arg UpsertNotificationPreferencesParams) error
+	UpsertTag(ctx context.Context, name string) (sql.Result, error)
 }
 
 var _ Querier = (*Queries)(nil)
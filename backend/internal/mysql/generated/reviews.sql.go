@@ -8,21 +8,27 @@ package generated
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"time"
 )
 
 const createReview = `-- name: CreateReview :execresult
 INSERT INTO reviews (
-  user_id, product_id, rating, review
+  user_id, product_id, rating, review, verified_purchase, status, moderation_reason, img_urls
 ) VALUES (
-  ?, ?, ?, ?
+  ?, ?, ?, ?, ?, ?, ?, ?
 )
 `
 
 type CreateReviewParams struct {
-	UserID    uint32 `json:"user_id"`
-	ProductID uint32 `json:"product_id"`
-	Rating    uint32 `json:"rating"`
-	Review    string `json:"review"`
+	UserID           uint32          `json:"user_id"`
+	ProductID        uint32          `json:"product_id"`
+	Rating           uint32          `json:"rating"`
+	Review           string          `json:"review"`
+	VerifiedPurchase bool            `json:"verified_purchase"`
+	Status           string          `json:"status"`
+	ModerationReason string          `json:"moderation_reason"`
+	ImgUrls          json.RawMessage `json:"img_urls"`
 }
 
 func (q *Queries) CreateReview(ctx context.Context, arg CreateReviewParams) (sql.Result, error) {
@@ -31,9 +37,31 @@ func (q *Queries) CreateReview(ctx context.Context, arg CreateReviewParams) (sql
 		arg.ProductID,
 		arg.Rating,
 		arg.Review,
+		arg.VerifiedPurchase,
+		arg.Status,
+		arg.ModerationReason,
+		arg.ImgUrls,
 	)
 }
 
+const createReviewVote = `-- name: CreateReviewVote :exec
+INSERT INTO review_votes (
+  review_id, user_id
+) VALUES (
+  ?, ?
+)
+`
+
+type CreateReviewVoteParams struct {
+	ReviewID uint32 `json:"review_id"`
+	UserID   uint32 `json:"user_id"`
+}
+
+func (q *Queries) CreateReviewVote(ctx context.Context, arg CreateReviewVoteParams) error {
+	_, err := q.db.ExecContext(ctx, createReviewVote, arg.ReviewID, arg.UserID)
+	return err
+}
+
 const deleteReview = `-- name: DeleteReview :exec
 DELETE FROM reviews
 WHERE id = ?
@@ -44,8 +72,32 @@ func (q *Queries) DeleteReview(ctx context.Context, id uint32) error {
 	return err
 }
 
+const deleteReviewVote = `-- name: DeleteReviewVote :execresult
+DELETE FROM review_votes
+WHERE review_id = ? AND user_id = ?
+`
+
+type DeleteReviewVoteParams struct {
+	ReviewID uint32 `json:"review_id"`
+	UserID   uint32 `json:"user_id"`
+}
+
+func (q *Queries) DeleteReviewVote(ctx context.Context, arg DeleteReviewVoteParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, deleteReviewVote, arg.ReviewID, arg.UserID)
+}
+
+const deleteUserReviewVotes = `-- name: DeleteUserReviewVotes :exec
+DELETE FROM review_votes
+WHERE user_id = ?
+`
+
+func (q *Queries) DeleteUserReviewVotes(ctx context.Context, userID uint32) error {
+	_, err := q.db.ExecContext(ctx, deleteUserReviewVotes, userID)
+	return err
+}
+
 const getReview = `-- name: GetReview :one
-SELECT id, user_id, product_id, rating, review, created_at FROM reviews
+SELECT id, user_id, product_id, rating, review, created_at, verified_purchase, updated_at, status, moderation_reason, moderated_by, moderated_at, img_urls, helpful_count, reply, replied_by, replied_at FROM reviews
 WHERE id = ? LIMIT 1
 `
 
@@ -59,13 +111,110 @@ func (q *Queries) GetReview(ctx context.Context, id uint32) (Review, error) {
 		&i.Rating,
 		&i.Review,
 		&i.CreatedAt,
+		&i.VerifiedPurchase,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.ModerationReason,
+		&i.ModeratedBy,
+		&i.ModeratedAt,
+		&i.ImgUrls,
+		&i.HelpfulCount,
+		&i.Reply,
+		&i.RepliedBy,
+		&i.RepliedAt,
 	)
 	return i, err
 }
 
+const getReviewForUpdate = `-- name: GetReviewForUpdate :one
+SELECT id, user_id, product_id, rating, review, created_at, verified_purchase, updated_at, status, moderation_reason, moderated_by, moderated_at, img_urls, helpful_count, reply, replied_by, replied_at FROM reviews
+WHERE id = ? LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetReviewForUpdate(ctx context.Context, id uint32) (Review, error) {
+	row := q.db.QueryRowContext(ctx, getReviewForUpdate, id)
+	var i Review
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ProductID,
+		&i.Rating,
+		&i.Review,
+		&i.CreatedAt,
+		&i.VerifiedPurchase,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.ModerationReason,
+		&i.ModeratedBy,
+		&i.ModeratedAt,
+		&i.ImgUrls,
+		&i.HelpfulCount,
+		&i.Reply,
+		&i.RepliedBy,
+		&i.RepliedAt,
+	)
+	return i, err
+}
+
+const getUserProductReview = `-- name: GetUserProductReview :one
+SELECT id, user_id, product_id, rating, review, created_at, verified_purchase, updated_at, status, moderation_reason, moderated_by, moderated_at, img_urls, helpful_count, reply, replied_by, replied_at FROM reviews
+WHERE user_id = ? AND product_id = ? LIMIT 1
+`
+
+type GetUserProductReviewParams struct {
+	UserID    uint32 `json:"user_id"`
+	ProductID uint32 `json:"product_id"`
+}
+
+func (q *Queries) GetUserProductReview(ctx context.Context, arg GetUserProductReviewParams) (Review, error) {
+	row := q.db.QueryRowContext(ctx, getUserProductReview, arg.UserID, arg.ProductID)
+	var i Review
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ProductID,
+		&i.Rating,
+		&i.Review,
+		&i.CreatedAt,
+		&i.VerifiedPurchase,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.ModerationReason,
+		&i.ModeratedBy,
+		&i.ModeratedAt,
+		&i.ImgUrls,
+		&i.HelpfulCount,
+		&i.Reply,
+		&i.RepliedBy,
+		&i.RepliedAt,
+	)
+	return i, err
+}
+
+const hasDeliveredOrderWithProduct = `-- name: HasDeliveredOrderWithProduct :one
+SELECT EXISTS (
+  SELECT 1 FROM orders o
+  JOIN order_items oi ON oi.order_id = o.id
+  WHERE o.user_id = ? AND oi.product_id = ? AND o.status = 'DELIVERED'
+) AS delivered
+`
+
+type HasDeliveredOrderWithProductParams struct {
+	UserID    uint32 `json:"user_id"`
+	ProductID uint32 `json:"product_id"`
+}
+
+func (q *Queries) HasDeliveredOrderWithProduct(ctx context.Context, arg HasDeliveredOrderWithProductParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, hasDeliveredOrderWithProduct, arg.UserID, arg.ProductID)
+	var delivered bool
+	err := row.Scan(&delivered)
+	return delivered, err
+}
+
 const listProductsReviews = `-- name: ListProductsReviews :many
-SELECT id, user_id, product_id, rating, review, created_at FROM reviews
-WHERE product_id = ?
+SELECT id, user_id, product_id, rating, review, created_at, verified_purchase, updated_at, status, moderation_reason, moderated_by, moderated_at, img_urls, helpful_count, reply, replied_by, replied_at FROM reviews
+WHERE product_id = ? AND status = 'APPROVED'
 ORDER BY created_at DESC
 `
 
@@ -85,6 +234,64 @@ func (q *Queries) ListProductsReviews(ctx context.Context, productID uint32) ([]
 			&i.Rating,
 			&i.Review,
 			&i.CreatedAt,
+			&i.VerifiedPurchase,
+			&i.UpdatedAt,
+			&i.Status,
+			&i.ModerationReason,
+			&i.ModeratedBy,
+			&i.ModeratedAt,
+			&i.ImgUrls,
+			&i.HelpfulCount,
+			&i.Reply,
+			&i.RepliedBy,
+			&i.RepliedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProductsReviewsByHelpful = `-- name: ListProductsReviewsByHelpful :many
+SELECT id, user_id, product_id, rating, review, created_at, verified_purchase, updated_at, status, moderation_reason, moderated_by, moderated_at, img_urls, helpful_count, reply, replied_by, replied_at FROM reviews
+WHERE product_id = ? AND status = 'APPROVED'
+ORDER BY helpful_count DESC, created_at DESC
+`
+
+func (q *Queries) ListProductsReviewsByHelpful(ctx context.Context, productID uint32) ([]Review, error) {
+	rows, err := q.db.QueryContext(ctx, listProductsReviewsByHelpful, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Review
+	for rows.Next() {
+		var i Review
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.ProductID,
+			&i.Rating,
+			&i.Review,
+			&i.CreatedAt,
+			&i.VerifiedPurchase,
+			&i.UpdatedAt,
+			&i.Status,
+			&i.ModerationReason,
+			&i.ModeratedBy,
+			&i.ModeratedAt,
+			&i.ImgUrls,
+			&i.HelpfulCount,
+			&i.Reply,
+			&i.RepliedBy,
+			&i.RepliedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -100,7 +307,8 @@ func (q *Queries) ListProductsReviews(ctx context.Context, productID uint32) ([]
 }
 
 const listReviews = `-- name: ListReviews :many
-SELECT id, user_id, product_id, rating, review, created_at FROM reviews
+SELECT id, user_id, product_id, rating, review, created_at, verified_purchase, updated_at, status, moderation_reason, moderated_by, moderated_at, img_urls, helpful_count, reply, replied_by, replied_at FROM reviews
+WHERE status = 'APPROVED'
 ORDER BY created_at DESC
 `
 
@@ -120,6 +328,71 @@ func (q *Queries) ListReviews(ctx context.Context) ([]Review, error) {
 			&i.Rating,
 			&i.Review,
 			&i.CreatedAt,
+			&i.VerifiedPurchase,
+			&i.UpdatedAt,
+			&i.Status,
+			&i.ModerationReason,
+			&i.ModeratedBy,
+			&i.ModeratedAt,
+			&i.ImgUrls,
+			&i.HelpfulCount,
+			&i.Reply,
+			&i.RepliedBy,
+			&i.RepliedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReviewsByStatus = `-- name: ListReviewsByStatus :many
+SELECT id, user_id, product_id, rating, review, created_at, verified_purchase, updated_at, status, moderation_reason, moderated_by, moderated_at, img_urls, helpful_count, reply, replied_by, replied_at FROM reviews
+WHERE status = ?
+ORDER BY created_at ASC
+LIMIT ? OFFSET ?
+`
+
+type ListReviewsByStatusParams struct {
+	Status string `json:"status"`
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
+}
+
+func (q *Queries) ListReviewsByStatus(ctx context.Context, arg ListReviewsByStatusParams) ([]Review, error) {
+	rows, err := q.db.QueryContext(ctx, listReviewsByStatus, arg.Status, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Review
+	for rows.Next() {
+		var i Review
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.ProductID,
+			&i.Rating,
+			&i.Review,
+			&i.CreatedAt,
+			&i.VerifiedPurchase,
+			&i.UpdatedAt,
+			&i.Status,
+			&i.ModerationReason,
+			&i.ModeratedBy,
+			&i.ModeratedAt,
+			&i.ImgUrls,
+			&i.HelpfulCount,
+			&i.Reply,
+			&i.RepliedBy,
+			&i.RepliedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -134,8 +407,36 @@ func (q *Queries) ListReviews(ctx context.Context) ([]Review, error) {
 	return items, nil
 }
 
+const listUserReviewVotes = `-- name: ListUserReviewVotes :many
+SELECT review_id FROM review_votes
+WHERE user_id = ?
+`
+
+func (q *Queries) ListUserReviewVotes(ctx context.Context, userID uint32) ([]uint32, error) {
+	rows, err := q.db.QueryContext(ctx, listUserReviewVotes, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uint32
+	for rows.Next() {
+		var review_id uint32
+		if err := rows.Scan(&review_id); err != nil {
+			return nil, err
+		}
+		items = append(items, review_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listUsersReviews = `-- name: ListUsersReviews :many
-SELECT id, user_id, product_id, rating, review, created_at FROM reviews
+SELECT id, user_id, product_id, rating, review, created_at, verified_purchase, updated_at, status, moderation_reason, moderated_by, moderated_at, img_urls, helpful_count, reply, replied_by, replied_at FROM reviews
 WHERE user_id = ?
 ORDER BY created_at DESC
 `
@@ -156,6 +457,17 @@ func (q *Queries) ListUsersReviews(ctx context.Context, userID uint32) ([]Review
 			&i.Rating,
 			&i.Review,
 			&i.CreatedAt,
+			&i.VerifiedPurchase,
+			&i.UpdatedAt,
+			&i.Status,
+			&i.ModerationReason,
+			&i.ModeratedBy,
+			&i.ModeratedAt,
+			&i.ImgUrls,
+			&i.HelpfulCount,
+			&i.Reply,
+			&i.RepliedBy,
+			&i.RepliedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -169,3 +481,109 @@ func (q *Queries) ListUsersReviews(ctx context.Context, userID uint32) ([]Review
 	}
 	return items, nil
 }
+
+const moderateReview = `-- name: ModerateReview :exec
+UPDATE reviews
+  SET status = ?,
+  moderation_reason = ?,
+  moderated_by = ?,
+  moderated_at = ?
+WHERE id = ?
+`
+
+type ModerateReviewParams struct {
+	Status           string        `json:"status"`
+	ModerationReason string        `json:"moderation_reason"`
+	ModeratedBy      sql.NullInt32 `json:"moderated_by"`
+	ModeratedAt      sql.NullTime  `json:"moderated_at"`
+	ID               uint32        `json:"id"`
+}
+
+func (q *Queries) ModerateReview(ctx context.Context, arg ModerateReviewParams) error {
+	_, err := q.db.ExecContext(ctx, moderateReview,
+		arg.Status,
+		arg.ModerationReason,
+		arg.ModeratedBy,
+		arg.ModeratedAt,
+		arg.ID,
+	)
+	return err
+}
+
+const updateHelpfulCount = `-- name: UpdateHelpfulCount :exec
+UPDATE reviews
+  SET helpful_count = (
+    SELECT COUNT(*) FROM review_votes
+    WHERE review_votes.review_id = reviews.id
+)
+WHERE id = ?
+`
+
+func (q *Queries) UpdateHelpfulCount(ctx context.Context, id uint32) error {
+	_, err := q.db.ExecContext(ctx, updateHelpfulCount, id)
+	return err
+}
+
+const updateReview = `-- name: UpdateReview :exec
+UPDATE reviews
+  SET rating = ?,
+  review = ?,
+  img_urls = ?,
+  verified_purchase = ?,
+  status = ?,
+  moderation_reason = ?,
+  moderated_by = NULL,
+  moderated_at = NULL,
+  updated_at = ?
+WHERE id = ?
+`
+
+type UpdateReviewParams struct {
+	Rating           uint32          `json:"rating"`
+	Review           string          `json:"review"`
+	ImgUrls          json.RawMessage `json:"img_urls"`
+	VerifiedPurchase bool            `json:"verified_purchase"`
+	Status           string          `json:"status"`
+	ModerationReason string          `json:"moderation_reason"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+	ID               uint32          `json:"id"`
+}
+
+func (q *Queries) UpdateReview(ctx context.Context, arg UpdateReviewParams) error {
+	_, err := q.db.ExecContext(ctx, updateReview,
+		arg.Rating,
+		arg.Review,
+		arg.ImgUrls,
+		arg.VerifiedPurchase,
+		arg.Status,
+		arg.ModerationReason,
+		arg.UpdatedAt,
+		arg.ID,
+	)
+	return err
+}
+
+const updateReviewReply = `-- name: UpdateReviewReply :exec
+UPDATE reviews
+  SET reply = ?,
+  replied_by = ?,
+  replied_at = ?
+WHERE id = ?
+`
+
+type UpdateReviewReplyParams struct {
+	Reply     sql.NullString `json:"reply"`
+	RepliedBy sql.NullInt32  `json:"replied_by"`
+	RepliedAt sql.NullTime   `json:"replied_at"`
+	ID        uint32         `json:"id"`
+}
+
+func (q *Queries) UpdateReviewReply(ctx context.Context, arg UpdateReviewReplyParams) error {
+	_, err := q.db.ExecContext(ctx, updateReviewReply,
+		arg.Reply,
+		arg.RepliedBy,
+		arg.RepliedAt,
+		arg.ID,
+	)
+	return err
+}
@@ -10,6 +10,37 @@ import (
 	"database/sql"
 )
 
+const anonymiseUser = `-- name: AnonymiseUser :exec
+UPDATE users
+  set email = ?,
+  password = ?,
+  full_name = '',
+  phone_number = '',
+  subscription = false,
+  refresh_token = '',
+  deleted_at = CURRENT_TIMESTAMP,
+  updated_at = CURRENT_TIMESTAMP,
+  updated_by = ?
+WHERE id = ?
+`
+
+type AnonymiseUserParams struct {
+	Email     string        `json:"email"`
+	Password  string        `json:"password"`
+	UpdatedBy sql.NullInt32 `json:"updated_by"`
+	ID        uint32        `json:"id"`
+}
+
+func (q *Queries) AnonymiseUser(ctx context.Context, arg AnonymiseUserParams) error {
+	_, err := q.db.ExecContext(ctx, anonymiseUser,
+		arg.Email,
+		arg.Password,
+		arg.UpdatedBy,
+		arg.ID,
+	)
+	return err
+}
+
 const createUser = `-- name: CreateUser :execresult
 INSERT INTO users
     (email, password, subscription, role, refresh_token, updated_by)
@@ -37,18 +68,8 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (sql.Res
 	)
 }
 
-const deleteUser = `-- name: DeleteUser :exec
-DELETE FROM users
-WHERE id = ?
-`
-
-func (q *Queries) DeleteUser(ctx context.Context, id uint32) error {
-	_, err := q.db.ExecContext(ctx, deleteUser, id)
-	return err
-}
-
 const getSubscribedUsers = `-- name: GetSubscribedUsers :many
-SELECT id, email, password, subscription, role, refresh_token, updated_by, updated_at, created_at FROM users
+SELECT id, email, password, subscription, role, refresh_token, updated_by, updated_at, created_at, full_name, phone_number, deleted_at, disabled_at, password_reset_required, tokens_valid_after FROM users
 WHERE subscription = true
 ORDER BY email
 `
@@ -72,6 +93,12 @@ func (q *Queries) GetSubscribedUsers(ctx context.Context) ([]User, error) {
 			&i.UpdatedBy,
 			&i.UpdatedAt,
 			&i.CreatedAt,
+			&i.FullName,
+			&i.PhoneNumber,
+			&i.DeletedAt,
+			&i.DisabledAt,
+			&i.PasswordResetRequired,
+			&i.TokensValidAfter,
 		); err != nil {
 			return nil, err
 		}
@@ -87,7 +114,7 @@ func (q *Queries) GetSubscribedUsers(ctx context.Context) ([]User, error) {
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, email, password, subscription, role, refresh_token, updated_by, updated_at, created_at FROM users
+SELECT id, email, password, subscription, role, refresh_token, updated_by, updated_at, created_at, full_name, phone_number, deleted_at, disabled_at, password_reset_required, tokens_valid_after FROM users
 WHERE email = ? LIMIT 1
 `
 
@@ -104,12 +131,18 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.UpdatedBy,
 		&i.UpdatedAt,
 		&i.CreatedAt,
+		&i.FullName,
+		&i.PhoneNumber,
+		&i.DeletedAt,
+		&i.DisabledAt,
+		&i.PasswordResetRequired,
+		&i.TokensValidAfter,
 	)
 	return i, err
 }
 
 const getUserById = `-- name: GetUserById :one
-SELECT id, email, password, subscription, role, refresh_token, updated_by, updated_at, created_at FROM users
+SELECT id, email, password, subscription, role, refresh_token, updated_by, updated_at, created_at, full_name, phone_number, deleted_at, disabled_at, password_reset_required, tokens_valid_after FROM users
 WHERE id = ? LIMIT 1
 `
 
@@ -126,6 +159,12 @@ func (q *Queries) GetUserById(ctx context.Context, id uint32) (User, error) {
 		&i.UpdatedBy,
 		&i.UpdatedAt,
 		&i.CreatedAt,
+		&i.FullName,
+		&i.PhoneNumber,
+		&i.DeletedAt,
+		&i.DisabledAt,
+		&i.PasswordResetRequired,
+		&i.TokensValidAfter,
 	)
 	return i, err
 }
@@ -143,7 +182,7 @@ func (q *Queries) GetUserEmail(ctx context.Context, id uint32) (string, error) {
 }
 
 const listUsers = `-- name: ListUsers :many
-SELECT id, email, password, subscription, role, refresh_token, updated_by, updated_at, created_at FROM users
+SELECT id, email, password, subscription, role, refresh_token, updated_by, updated_at, created_at, full_name, phone_number, deleted_at, disabled_at, password_reset_required, tokens_valid_after FROM users
 ORDER BY email
 `
 
@@ -166,6 +205,110 @@ func (q *Queries) ListUsers(ctx context.Context) ([]User, error) {
 			&i.UpdatedBy,
 			&i.UpdatedAt,
 			&i.CreatedAt,
+			&i.FullName,
+			&i.PhoneNumber,
+			&i.DeletedAt,
+			&i.DisabledAt,
+			&i.PasswordResetRequired,
+			&i.TokensValidAfter,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const requirePasswordReset = `-- name: RequirePasswordReset :exec
+UPDATE users
+  set password_reset_required = true,
+  refresh_token = '',
+  tokens_valid_after = ?,
+  updated_at = CURRENT_TIMESTAMP,
+  updated_by = ?
+WHERE id = ?
+`
+
+type RequirePasswordResetParams struct {
+	TokensValidAfter sql.NullTime  `json:"tokens_valid_after"`
+	UpdatedBy        sql.NullInt32 `json:"updated_by"`
+	ID               uint32        `json:"id"`
+}
+
+func (q *Queries) RequirePasswordReset(ctx context.Context, arg RequirePasswordResetParams) error {
+	_, err := q.db.ExecContext(ctx, requirePasswordReset, arg.TokensValidAfter, arg.UpdatedBy, arg.ID)
+	return err
+}
+
+const searchUsers = `-- name: SearchUsers :many
+SELECT id, email, password, subscription, role, refresh_token, updated_by, updated_at, created_at, full_name, phone_number, deleted_at, disabled_at, password_reset_required, tokens_valid_after FROM users
+WHERE (? IS NULL OR email LIKE ?)
+  AND (? IS NULL OR role = ?)
+  AND (? IS NULL OR created_at >= ?)
+  AND (? IS NULL OR created_at < ?)
+  AND (? IS NULL OR EXISTS (SELECT 1 FROM orders WHERE orders.user_id = users.id) = ?)
+  AND (? IS NULL OR (disabled_at IS NOT NULL) = ?)
+ORDER BY created_at DESC, id DESC
+LIMIT ? OFFSET ?
+`
+
+type SearchUsersParams struct {
+	Email         sql.NullString `json:"email"`
+	Role          sql.NullString `json:"role"`
+	CreatedAfter  sql.NullTime   `json:"created_after"`
+	CreatedBefore sql.NullTime   `json:"created_before"`
+	HasOrders     interface{}    `json:"has_orders"`
+	Disabled      interface{}    `json:"disabled"`
+	Limit         int32          `json:"limit"`
+	Offset        int32          `json:"offset"`
+}
+
+func (q *Queries) SearchUsers(ctx context.Context, arg SearchUsersParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, searchUsers,
+		arg.Email,
+		arg.Email,
+		arg.Role,
+		arg.Role,
+		arg.CreatedAfter,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.CreatedBefore,
+		arg.HasOrders,
+		arg.HasOrders,
+		arg.Disabled,
+		arg.Disabled,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.Password,
+			&i.Subscription,
+			&i.Role,
+			&i.RefreshToken,
+			&i.UpdatedBy,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.FullName,
+			&i.PhoneNumber,
+			&i.DeletedAt,
+			&i.DisabledAt,
+			&i.PasswordResetRequired,
+			&i.TokensValidAfter,
 		); err != nil {
 			return nil, err
 		}
@@ -218,19 +361,70 @@ func (q *Queries) UpdateSubscriptionStatus(ctx context.Context, arg UpdateSubscr
 const updateUserCredentials = `-- name: UpdateUserCredentials :exec
 UPDATE users
   set password = ?,
+  password_reset_required = false,
+  tokens_valid_after = ?,
   updated_at = CURRENT_TIMESTAMP,
   updated_by = ?
 WHERE id = ?
 `
 
 type UpdateUserCredentialsParams struct {
-	Password  string        `json:"password"`
-	UpdatedBy sql.NullInt32 `json:"updated_by"`
-	ID        uint32        `json:"id"`
+	Password         string        `json:"password"`
+	TokensValidAfter sql.NullTime  `json:"tokens_valid_after"`
+	UpdatedBy        sql.NullInt32 `json:"updated_by"`
+	ID               uint32        `json:"id"`
 }
 
 func (q *Queries) UpdateUserCredentials(ctx context.Context, arg UpdateUserCredentialsParams) error {
-	_, err := q.db.ExecContext(ctx, updateUserCredentials, arg.Password, arg.UpdatedBy, arg.ID)
+	_, err := q.db.ExecContext(ctx, updateUserCredentials, arg.Password, arg.TokensValidAfter, arg.UpdatedBy, arg.ID)
+	return err
+}
+
+const updateUserDisabled = `-- name: UpdateUserDisabled :exec
+UPDATE users
+  set disabled_at = ?,
+  refresh_token = '',
+  tokens_valid_after = ?,
+  updated_at = CURRENT_TIMESTAMP,
+  updated_by = ?
+WHERE id = ?
+`
+
+type UpdateUserDisabledParams struct {
+	DisabledAt       sql.NullTime  `json:"disabled_at"`
+	TokensValidAfter sql.NullTime  `json:"tokens_valid_after"`
+	UpdatedBy        sql.NullInt32 `json:"updated_by"`
+	ID               uint32        `json:"id"`
+}
+
+func (q *Queries) UpdateUserDisabled(ctx context.Context, arg UpdateUserDisabledParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserDisabled, arg.DisabledAt, arg.TokensValidAfter, arg.UpdatedBy, arg.ID)
+	return err
+}
+
+const updateUserProfile = `-- name: UpdateUserProfile :exec
+UPDATE users
+  set full_name = coalesce(?, full_name),
+  phone_number = coalesce(?, phone_number),
+  updated_at = CURRENT_TIMESTAMP,
+  updated_by = ?
+WHERE id = ?
+`
+
+type UpdateUserProfileParams struct {
+	FullName    sql.NullString `json:"full_name"`
+	PhoneNumber sql.NullString `json:"phone_number"`
+	UpdatedBy   sql.NullInt32  `json:"updated_by"`
+	ID          uint32         `json:"id"`
+}
+
+func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserProfile,
+		arg.FullName,
+		arg.PhoneNumber,
+		arg.UpdatedBy,
+		arg.ID,
+	)
 	return err
 }
 
@@ -0,0 +1,3 @@
+ALTER TABLE user_two_factor DROP FOREIGN KEY fk_user_two_factor_user_id;
+
+DROP TABLE IF EXISTS user_two_factor;
\ No newline at end of file
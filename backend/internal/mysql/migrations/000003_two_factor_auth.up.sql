@@ -0,0 +1,15 @@
+-- Two factor authentication table
+CREATE TABLE user_two_factor (
+    user_id int unsigned PRIMARY KEY,
+    secret varchar(255) NOT NULL COMMENT 'base32 totp secret',
+    enabled boolean NOT NULL DEFAULT false COMMENT 'false until the first code is verified',
+    recovery_codes json NOT NULL COMMENT 'sha256 hashes of unused recovery codes',
+    last_used_step bigint unsigned NOT NULL DEFAULT 0 COMMENT 'last accepted totp step, prevents code reuse',
+    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Foreign Keys
+-- ALTER TABLE user_two_factor ADD FOREIGN KEY (user_id) REFERENCES users (id);
+
+ALTER TABLE user_two_factor ADD CONSTRAINT fk_user_two_factor_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
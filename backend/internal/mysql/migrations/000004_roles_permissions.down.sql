@@ -0,0 +1,9 @@
+ALTER TABLE users DROP FOREIGN KEY fk_users_role;
+ALTER TABLE role_permissions DROP FOREIGN KEY fk_role_permissions_role;
+ALTER TABLE role_permissions DROP FOREIGN KEY fk_role_permissions_permission;
+
+ALTER TABLE users MODIFY role varchar(124) NOT NULL COMMENT 'USER or ADMIN';
+
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS roles;
\ No newline at end of file
@@ -0,0 +1,61 @@
+-- Roles table
+CREATE TABLE roles (
+    name varchar(124) PRIMARY KEY,
+    description text NOT NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Permissions table
+CREATE TABLE permissions (
+    name varchar(124) PRIMARY KEY COMMENT 'resource:action e.g products:write',
+    description text NOT NULL
+);
+
+-- Role permissions table
+CREATE TABLE role_permissions (
+    role varchar(124) NOT NULL,
+    permission varchar(124) NOT NULL,
+    PRIMARY KEY (role, permission)
+);
+
+INSERT INTO roles (name, description) VALUES
+    ('ADMIN', 'Full access to the shop'),
+    ('STAFF', 'Manages the catalogue and fulfils orders'),
+    ('BLOGGER', 'Writes blog posts'),
+    ('USER', 'Customer account');
+
+INSERT INTO permissions (name, description) VALUES
+    ('users:read', 'View any user account'),
+    ('users:manage', 'Change user roles and manage accounts'),
+    ('roles:manage', 'Manage roles and their permissions'),
+    ('products:write', 'Create, update and delete products and stock'),
+    ('categories:write', 'Create, update and delete categories'),
+    ('orders:read', 'View all orders'),
+    ('orders:fulfil', 'Update order status'),
+    ('orders:delete', 'Delete orders'),
+    ('carts:read', 'View all carts'),
+    ('reviews:moderate', 'Delete reviews'),
+    ('blogs:publish', 'Create, update and delete own blog posts');
+
+INSERT INTO role_permissions (role, permission)
+    SELECT 'ADMIN', name FROM permissions;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('STAFF', 'products:write'),
+    ('STAFF', 'categories:write'),
+    ('STAFF', 'orders:read'),
+    ('STAFF', 'orders:fulfil'),
+    ('STAFF', 'carts:read'),
+    ('STAFF', 'reviews:moderate'),
+    ('BLOGGER', 'blogs:publish');
+
+ALTER TABLE users MODIFY role varchar(124) NOT NULL COMMENT 'name of a row in roles';
+
+-- Foreign Keys
+-- ALTER TABLE role_permissions ADD FOREIGN KEY (role) REFERENCES roles (name);
+-- ALTER TABLE role_permissions ADD FOREIGN KEY (permission) REFERENCES permissions (name);
+-- ALTER TABLE users ADD FOREIGN KEY (role) REFERENCES roles (name);
+
+ALTER TABLE role_permissions ADD CONSTRAINT fk_role_permissions_role FOREIGN KEY (role) REFERENCES roles (name) ON DELETE CASCADE;
+ALTER TABLE role_permissions ADD CONSTRAINT fk_role_permissions_permission FOREIGN KEY (permission) REFERENCES permissions (name) ON DELETE CASCADE;
+ALTER TABLE users ADD CONSTRAINT fk_users_role FOREIGN KEY (role) REFERENCES roles (name);
@@ -0,0 +1,4 @@
+DELETE FROM permissions WHERE name = 'security:manage';
+
+DROP TABLE IF EXISTS security_events;
+DROP TABLE IF EXISTS login_attempts;
@@ -0,0 +1,30 @@
+-- Login attempts table
+CREATE TABLE login_attempts (
+    scope varchar(10) NOT NULL COMMENT 'EMAIL or IP',
+    identifier varchar(255) NOT NULL COMMENT 'lower cased email or client ip',
+    failed_attempts int unsigned NOT NULL DEFAULT 0 COMMENT 'consecutive failures inside the attempt window',
+    locked_until timestamp NULL DEFAULT NULL COMMENT 'logins are refused until this time',
+    last_failed_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (scope, identifier)
+);
+
+-- Security events table
+CREATE TABLE security_events (
+    id int unsigned PRIMARY KEY AUTO_INCREMENT,
+    event varchar(50) NOT NULL COMMENT 'LOGIN_FAILED, LOGIN_BLOCKED, ACCOUNT_LOCKED, ...',
+    user_id int unsigned NULL COMMENT 'account the event is about, null for unknown emails',
+    actor_id int unsigned NULL COMMENT 'user that performed the action when not the account owner',
+    email varchar(255) NOT NULL DEFAULT '',
+    ip_address varchar(45) NOT NULL DEFAULT '',
+    details text NOT NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX security_events_email_idx ON security_events (email);
+CREATE INDEX security_events_created_at_idx ON security_events (created_at);
+
+INSERT INTO permissions (name, description) VALUES
+    ('security:manage', 'Review security events and lift login lockouts');
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('ADMIN', 'security:manage');
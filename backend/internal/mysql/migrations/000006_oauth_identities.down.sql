@@ -0,0 +1,4 @@
+ALTER TABLE user_identities DROP FOREIGN KEY fk_user_identities_user_id;
+
+DROP TABLE IF EXISTS user_identities;
+DROP TABLE IF EXISTS oauth_states;
@@ -0,0 +1,26 @@
+-- OAuth states table
+CREATE TABLE oauth_states (
+    state varchar(64) PRIMARY KEY,
+    provider varchar(50) NOT NULL,
+    code_verifier varchar(128) NOT NULL COMMENT 'pkce verifier, never sent to the browser',
+    nonce varchar(64) NOT NULL,
+    expires_at timestamp NOT NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- User identities table
+CREATE TABLE user_identities (
+    provider varchar(50) NOT NULL,
+    subject varchar(255) NOT NULL COMMENT 'sub claim of the provider id token',
+    user_id int unsigned NOT NULL,
+    email varchar(255) NOT NULL COMMENT 'email the provider reported when linked',
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (provider, subject)
+);
+
+CREATE INDEX user_identities_user_id_idx ON user_identities (user_id);
+
+-- Foreign Keys
+-- ALTER TABLE user_identities ADD FOREIGN KEY (user_id) REFERENCES users (id);
+
+ALTER TABLE user_identities ADD CONSTRAINT fk_user_identities_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
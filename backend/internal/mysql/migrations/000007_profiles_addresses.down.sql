@@ -0,0 +1,9 @@
+ALTER TABLE orders DROP FOREIGN KEY fk_orders_address_id;
+ALTER TABLE addresses DROP FOREIGN KEY fk_addresses_user_id;
+
+ALTER TABLE orders DROP COLUMN address_id;
+
+DROP TABLE IF EXISTS addresses;
+
+ALTER TABLE users DROP COLUMN phone_number;
+ALTER TABLE users DROP COLUMN full_name;
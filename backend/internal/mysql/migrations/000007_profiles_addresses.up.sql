@@ -0,0 +1,28 @@
+ALTER TABLE users ADD COLUMN full_name varchar(255) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN phone_number varchar(50) NOT NULL DEFAULT '';
+
+-- Addresses table
+CREATE TABLE addresses (
+  id int unsigned AUTO_INCREMENT PRIMARY KEY,
+  user_id int unsigned NOT NULL,
+  recipient_name varchar(255) NOT NULL,
+  phone_number varchar(50) NOT NULL,
+  county varchar(100) NOT NULL,
+  city varchar(100) NOT NULL,
+  street varchar(255) NOT NULL COMMENT 'street, building and house number',
+  postal_code varchar(20) NOT NULL DEFAULT '',
+  is_default boolean NOT NULL DEFAULT false COMMENT 'used when an order does not name an address',
+  updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX addresses_user_id_idx ON addresses (user_id);
+
+ALTER TABLE orders ADD COLUMN address_id int unsigned NULL COMMENT 'address the order was shipped to, shipping_address keeps the snapshot';
+
+-- Foreign Keys
+-- ALTER TABLE addresses ADD FOREIGN KEY (user_id) REFERENCES users (id);
+-- ALTER TABLE orders ADD FOREIGN KEY (address_id) REFERENCES addresses (id);
+
+ALTER TABLE addresses ADD CONSTRAINT fk_addresses_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE orders ADD CONSTRAINT fk_orders_address_id FOREIGN KEY (address_id) REFERENCES addresses (id) ON DELETE SET NULL;
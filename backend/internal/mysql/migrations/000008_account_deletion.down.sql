@@ -0,0 +1,11 @@
+ALTER TABLE users DROP FOREIGN KEY fk_users_updated_by;
+ALTER TABLE transactions DROP FOREIGN KEY fk_transactions_user_id;
+ALTER TABLE orders DROP FOREIGN KEY fk_orders_updated_by;
+ALTER TABLE orders DROP FOREIGN KEY fk_orders_user_id;
+
+ALTER TABLE users ADD CONSTRAINT fk_users_updated_by FOREIGN KEY (updated_by) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE transactions ADD CONSTRAINT fk_transactions_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE orders ADD CONSTRAINT fk_orders_updated_by FOREIGN KEY (updated_by) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE orders ADD CONSTRAINT fk_orders_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+
+ALTER TABLE users DROP COLUMN deleted_at;
@@ -0,0 +1,12 @@
+ALTER TABLE users ADD COLUMN deleted_at timestamp NULL COMMENT 'set when the account was anonymised, the row stays for orders and transactions';
+
+-- orders and transactions are kept for accounting, users are anonymised instead of deleted
+ALTER TABLE orders DROP FOREIGN KEY fk_orders_user_id;
+ALTER TABLE orders DROP FOREIGN KEY fk_orders_updated_by;
+ALTER TABLE transactions DROP FOREIGN KEY fk_transactions_user_id;
+ALTER TABLE users DROP FOREIGN KEY fk_users_updated_by;
+
+ALTER TABLE orders ADD CONSTRAINT fk_orders_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE RESTRICT;
+ALTER TABLE orders ADD CONSTRAINT fk_orders_updated_by FOREIGN KEY (updated_by) REFERENCES users (id) ON DELETE RESTRICT;
+ALTER TABLE transactions ADD CONSTRAINT fk_transactions_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE RESTRICT;
+ALTER TABLE users ADD CONSTRAINT fk_users_updated_by FOREIGN KEY (updated_by) REFERENCES users (id) ON DELETE SET NULL;
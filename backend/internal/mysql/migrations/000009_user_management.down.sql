@@ -0,0 +1,9 @@
+ALTER TABLE password_tokens DROP FOREIGN KEY fk_password_tokens_created_by;
+ALTER TABLE password_tokens DROP FOREIGN KEY fk_password_tokens_user_id;
+
+DROP TABLE IF EXISTS password_tokens;
+
+DROP INDEX users_created_at_idx ON users;
+
+ALTER TABLE users DROP COLUMN password_reset_required;
+ALTER TABLE users DROP COLUMN disabled_at;
@@ -0,0 +1,23 @@
+ALTER TABLE users ADD COLUMN disabled_at timestamp NULL COMMENT 'set while an admin has disabled the account';
+ALTER TABLE users ADD COLUMN password_reset_required boolean NOT NULL DEFAULT false COMMENT 'login is refused until the password is reset';
+
+CREATE INDEX users_created_at_idx ON users (created_at);
+
+-- Password tokens table
+CREATE TABLE password_tokens (
+  token_hash char(64) PRIMARY KEY COMMENT 'sha256 of the token, the token itself is only handed out once',
+  user_id int unsigned NOT NULL,
+  purpose varchar(20) NOT NULL COMMENT 'RESET or INVITE',
+  expires_at timestamp NOT NULL,
+  created_by int unsigned NULL COMMENT 'admin that forced the reset or sent the invite',
+  created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX password_tokens_user_id_idx ON password_tokens (user_id);
+
+-- Foreign Keys
+-- ALTER TABLE password_tokens ADD FOREIGN KEY (user_id) REFERENCES users (id);
+-- ALTER TABLE password_tokens ADD FOREIGN KEY (created_by) REFERENCES users (id);
+
+ALTER TABLE password_tokens ADD CONSTRAINT fk_password_tokens_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE password_tokens ADD CONSTRAINT fk_password_tokens_created_by FOREIGN KEY (created_by) REFERENCES users (id) ON DELETE SET NULL;
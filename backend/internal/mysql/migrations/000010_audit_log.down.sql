@@ -0,0 +1,6 @@
+DELETE FROM role_permissions WHERE permission = 'audit:read';
+DELETE FROM permissions WHERE name = 'audit:read';
+
+ALTER TABLE audit_log DROP FOREIGN KEY fk_audit_log_actor_id;
+
+DROP TABLE IF EXISTS audit_log;
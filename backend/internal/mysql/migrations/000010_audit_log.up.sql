@@ -0,0 +1,28 @@
+-- Audit log table
+CREATE TABLE audit_log (
+    id int unsigned AUTO_INCREMENT PRIMARY KEY,
+    actor_id int unsigned NULL,
+    action varchar(20) NOT NULL COMMENT 'CREATE, UPDATE or DELETE',
+    entity_type varchar(50) NOT NULL,
+    entity_id varchar(64) NOT NULL,
+    `before` json NULL COMMENT 'previous values of the fields that changed',
+    `after` json NULL COMMENT 'new values of the fields that changed',
+    ip_address varchar(45) NOT NULL DEFAULT '',
+    request_id varchar(64) NOT NULL DEFAULT '',
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX audit_log_entity_idx ON audit_log (entity_type, entity_id);
+CREATE INDEX audit_log_actor_id_idx ON audit_log (actor_id);
+CREATE INDEX audit_log_created_at_idx ON audit_log (created_at);
+
+-- Foreign Keys
+-- ALTER TABLE audit_log ADD FOREIGN KEY (actor_id) REFERENCES users (id);
+
+ALTER TABLE audit_log ADD CONSTRAINT fk_audit_log_actor_id FOREIGN KEY (actor_id) REFERENCES users (id) ON DELETE SET NULL;
+
+INSERT INTO permissions (name, description) VALUES
+    ('audit:read', 'Read the audit log of administrative changes');
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('ADMIN', 'audit:read');
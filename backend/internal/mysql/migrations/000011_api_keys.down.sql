@@ -0,0 +1,6 @@
+DELETE FROM role_permissions WHERE permission IN ('api_keys:manage', 'stock:write');
+DELETE FROM permissions WHERE name IN ('api_keys:manage', 'stock:write');
+
+ALTER TABLE api_keys DROP FOREIGN KEY fk_api_keys_created_by;
+
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,31 @@
+-- API keys table
+CREATE TABLE api_keys (
+  id int unsigned AUTO_INCREMENT PRIMARY KEY,
+  name varchar(124) NOT NULL COMMENT 'what the key is used for e.g warehouse sync',
+  prefix varchar(16) NOT NULL COMMENT 'start of the key, shown so keys can be told apart',
+  key_hash char(64) NOT NULL UNIQUE COMMENT 'sha256 of the key, the key itself is only shown once',
+  scopes json NOT NULL COMMENT 'permissions the key grants e.g ["orders:read"]',
+  created_by int unsigned NOT NULL COMMENT 'admin that created the key, requests act on their behalf',
+  expires_at timestamp NULL,
+  last_used_at timestamp NULL,
+  revoked_at timestamp NULL,
+  created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX api_keys_created_by_idx ON api_keys (created_by);
+
+-- Foreign Keys
+-- ALTER TABLE api_keys ADD FOREIGN KEY (created_by) REFERENCES users (id);
+
+ALTER TABLE api_keys ADD CONSTRAINT fk_api_keys_created_by FOREIGN KEY (created_by) REFERENCES users (id) ON DELETE CASCADE;
+
+INSERT INTO permissions (name, description) VALUES
+    ('api_keys:manage', 'Create, list and revoke API keys'),
+    ('stock:write', 'Update product stock levels');
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('ADMIN', 'api_keys:manage');
+
+-- stock used to fall under products:write so every role that had it keeps it
+INSERT INTO role_permissions (role, permission)
+    SELECT role, 'stock:write' FROM role_permissions WHERE permission = 'products:write';
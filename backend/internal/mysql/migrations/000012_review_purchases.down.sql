@@ -0,0 +1,4 @@
+DROP INDEX reviews_user_product_idx ON reviews;
+
+ALTER TABLE reviews DROP COLUMN updated_at;
+ALTER TABLE reviews DROP COLUMN verified_purchase;
@@ -0,0 +1,18 @@
+ALTER TABLE reviews ADD COLUMN verified_purchase boolean NOT NULL DEFAULT false COMMENT 'the author had a delivered order with the product when reviewing';
+ALTER TABLE reviews ADD COLUMN updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+-- keep only the newest review of every user for a product
+DELETE r FROM reviews r
+JOIN reviews newer ON newer.user_id = r.user_id AND newer.product_id = r.product_id AND newer.id > r.id;
+
+UPDATE reviews r
+SET r.verified_purchase = EXISTS (
+    SELECT 1 FROM orders o
+    JOIN order_items oi ON oi.order_id = o.id
+    WHERE o.user_id = r.user_id AND oi.product_id = r.product_id AND o.status = 'DELIVERED'
+);
+
+UPDATE products p
+SET p.rating = COALESCE((SELECT AVG(r.rating) FROM reviews r WHERE r.product_id = p.id), 0);
+
+CREATE UNIQUE INDEX reviews_user_product_idx ON reviews (user_id, product_id);
@@ -0,0 +1,11 @@
+ALTER TABLE notifications DROP FOREIGN KEY fk_notifications_user_id;
+ALTER TABLE reviews DROP FOREIGN KEY fk_reviews_moderated_by;
+
+DROP TABLE IF EXISTS notifications;
+
+DROP INDEX reviews_status_idx ON reviews;
+
+ALTER TABLE reviews DROP COLUMN moderated_at;
+ALTER TABLE reviews DROP COLUMN moderated_by;
+ALTER TABLE reviews DROP COLUMN moderation_reason;
+ALTER TABLE reviews DROP COLUMN status;
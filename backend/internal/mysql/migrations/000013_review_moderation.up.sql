@@ -0,0 +1,29 @@
+ALTER TABLE reviews ADD COLUMN status varchar(20) NOT NULL DEFAULT 'PENDING' COMMENT 'PENDING, APPROVED or REJECTED, only approved reviews are shown and rated';
+ALTER TABLE reviews ADD COLUMN moderation_reason varchar(255) NOT NULL DEFAULT '' COMMENT 'why the review was rejected or held by the filter';
+ALTER TABLE reviews ADD COLUMN moderated_by int unsigned NULL;
+ALTER TABLE reviews ADD COLUMN moderated_at timestamp NULL;
+
+-- reviews written before moderation existed were already live
+UPDATE reviews SET status = 'APPROVED';
+
+CREATE INDEX reviews_status_idx ON reviews (status, created_at);
+
+-- Notifications table
+CREATE TABLE notifications (
+  id int unsigned AUTO_INCREMENT PRIMARY KEY,
+  user_id int unsigned NOT NULL,
+  type varchar(50) NOT NULL COMMENT 'REVIEW_APPROVED, REVIEW_REJECTED, ...',
+  title varchar(255) NOT NULL,
+  message text NOT NULL,
+  read_at timestamp NULL,
+  created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX notifications_user_id_idx ON notifications (user_id, created_at);
+
+-- Foreign Keys
+-- ALTER TABLE reviews ADD FOREIGN KEY (moderated_by) REFERENCES users (id);
+-- ALTER TABLE notifications ADD FOREIGN KEY (user_id) REFERENCES users (id);
+
+ALTER TABLE reviews ADD CONSTRAINT fk_reviews_moderated_by FOREIGN KEY (moderated_by) REFERENCES users (id) ON DELETE SET NULL;
+ALTER TABLE notifications ADD CONSTRAINT fk_notifications_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
@@ -0,0 +1,13 @@
+ALTER TABLE review_votes DROP FOREIGN KEY fk_review_votes_user_id;
+ALTER TABLE review_votes DROP FOREIGN KEY fk_review_votes_review_id;
+ALTER TABLE reviews DROP FOREIGN KEY fk_reviews_replied_by;
+
+DROP TABLE IF EXISTS review_votes;
+
+DROP INDEX reviews_helpful_idx ON reviews;
+
+ALTER TABLE reviews DROP COLUMN replied_at;
+ALTER TABLE reviews DROP COLUMN replied_by;
+ALTER TABLE reviews DROP COLUMN reply;
+ALTER TABLE reviews DROP COLUMN helpful_count;
+ALTER TABLE reviews DROP COLUMN img_urls;
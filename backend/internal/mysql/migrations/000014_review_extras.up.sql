@@ -0,0 +1,30 @@
+ALTER TABLE reviews ADD COLUMN img_urls json NULL;
+ALTER TABLE reviews ADD COLUMN helpful_count int unsigned NOT NULL DEFAULT 0 COMMENT 'will be updated anytime a helpful vote is added or removed';
+ALTER TABLE reviews ADD COLUMN reply text NULL COMMENT 'public answer from the shop';
+ALTER TABLE reviews ADD COLUMN replied_by int unsigned NULL;
+ALTER TABLE reviews ADD COLUMN replied_at timestamp NULL;
+
+UPDATE reviews SET img_urls = JSON_ARRAY();
+
+ALTER TABLE reviews MODIFY COLUMN img_urls json NOT NULL COMMENT 'photos of the item the customer received';
+
+CREATE INDEX reviews_helpful_idx ON reviews (product_id, helpful_count);
+
+-- Review votes table
+CREATE TABLE review_votes (
+  review_id int unsigned NOT NULL,
+  user_id int unsigned NOT NULL,
+  created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (review_id, user_id)
+);
+
+CREATE INDEX review_votes_user_id_idx ON review_votes (user_id);
+
+-- Foreign Keys
+-- ALTER TABLE reviews ADD FOREIGN KEY (replied_by) REFERENCES users (id);
+-- ALTER TABLE review_votes ADD FOREIGN KEY (review_id) REFERENCES reviews (id);
+-- ALTER TABLE review_votes ADD FOREIGN KEY (user_id) REFERENCES users (id);
+
+ALTER TABLE reviews ADD CONSTRAINT fk_reviews_replied_by FOREIGN KEY (replied_by) REFERENCES users (id) ON DELETE SET NULL;
+ALTER TABLE review_votes ADD CONSTRAINT fk_review_votes_review_id FOREIGN KEY (review_id) REFERENCES reviews (id) ON DELETE CASCADE;
+ALTER TABLE review_votes ADD CONSTRAINT fk_review_votes_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
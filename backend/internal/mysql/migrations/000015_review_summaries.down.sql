@@ -0,0 +1,3 @@
+ALTER TABLE product_review_summaries DROP FOREIGN KEY fk_product_review_summaries_product_id;
+
+DROP TABLE IF EXISTS product_review_summaries;
@@ -0,0 +1,35 @@
+-- Product review summaries table
+CREATE TABLE product_review_summaries (
+  product_id int unsigned PRIMARY KEY,
+  review_count int NOT NULL DEFAULT 0 COMMENT 'approved reviews only',
+  rating_total int NOT NULL DEFAULT 0 COMMENT 'sum of the ratings, average is rating_total / review_count',
+  star_1 int NOT NULL DEFAULT 0,
+  star_2 int NOT NULL DEFAULT 0,
+  star_3 int NOT NULL DEFAULT 0,
+  star_4 int NOT NULL DEFAULT 0,
+  star_5 int NOT NULL DEFAULT 0,
+  recommend_count int NOT NULL DEFAULT 0 COMMENT 'reviews rated 4 or 5',
+  updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO product_review_summaries (
+  product_id, review_count, rating_total, star_1, star_2, star_3, star_4, star_5, recommend_count
+)
+SELECT
+  product_id,
+  COUNT(*),
+  SUM(rating),
+  SUM(rating = 1),
+  SUM(rating = 2),
+  SUM(rating = 3),
+  SUM(rating = 4),
+  SUM(rating = 5),
+  SUM(rating >= 4)
+FROM reviews
+WHERE status = 'APPROVED'
+GROUP BY product_id;
+
+-- Foreign Keys
+-- ALTER TABLE product_review_summaries ADD FOREIGN KEY (product_id) REFERENCES products (id);
+
+ALTER TABLE product_review_summaries ADD CONSTRAINT fk_product_review_summaries_product_id FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE;
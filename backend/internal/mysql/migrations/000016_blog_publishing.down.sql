@@ -0,0 +1,7 @@
+DROP INDEX blogs_status_publish_at_idx ON blogs;
+DROP INDEX blogs_slug_idx ON blogs;
+
+ALTER TABLE blogs DROP COLUMN updated_at;
+ALTER TABLE blogs DROP COLUMN publish_at;
+ALTER TABLE blogs DROP COLUMN slug;
+ALTER TABLE blogs DROP COLUMN status;
@@ -0,0 +1,16 @@
+ALTER TABLE blogs ADD COLUMN status varchar(20) NOT NULL DEFAULT 'DRAFT' COMMENT 'DRAFT, PUBLISHED or ARCHIVED';
+ALTER TABLE blogs ADD COLUMN slug varchar(255) NULL;
+ALTER TABLE blogs ADD COLUMN publish_at timestamp NULL COMMENT 'published blogs are public from this time';
+ALTER TABLE blogs ADD COLUMN updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+-- blogs written before drafts existed were already public, the id keeps their slugs unique
+UPDATE blogs
+  SET status = 'PUBLISHED',
+  publish_at = created_at,
+  updated_at = created_at,
+  slug = CONCAT(TRIM(BOTH '-' FROM LEFT(LOWER(REGEXP_REPLACE(title, '[^A-Za-z0-9]+', '-')), 200)), '-', id);
+
+ALTER TABLE blogs MODIFY COLUMN slug varchar(255) NOT NULL COMMENT 'url friendly title, GET /blogs/slug/:slug';
+
+CREATE UNIQUE INDEX blogs_slug_idx ON blogs (slug);
+CREATE INDEX blogs_status_publish_at_idx ON blogs (status, publish_at);
@@ -0,0 +1,4 @@
+ALTER TABLE blogs DROP COLUMN reading_minutes;
+ALTER TABLE blogs DROP COLUMN excerpt;
+ALTER TABLE blogs DROP COLUMN content_html;
+ALTER TABLE blogs MODIFY COLUMN content text NOT NULL;
@@ -0,0 +1,12 @@
+ALTER TABLE blogs MODIFY COLUMN content mediumtext NOT NULL COMMENT 'markdown source written by the author';
+ALTER TABLE blogs ADD COLUMN content_html mediumtext NULL;
+ALTER TABLE blogs ADD COLUMN excerpt varchar(300) NOT NULL DEFAULT '';
+ALTER TABLE blogs ADD COLUMN reading_minutes int unsigned NOT NULL DEFAULT 1;
+
+-- older blogs were plain text, escaping it keeps the stored html safe until they are edited
+UPDATE blogs
+  SET content_html = CONCAT('<p>', REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(content, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&#34;'), '''', '&#39;'), '</p>'),
+  excerpt = LEFT(content, 160),
+  reading_minutes = GREATEST(1, CEIL((CHAR_LENGTH(TRIM(content)) - CHAR_LENGTH(REPLACE(TRIM(content), ' ', '')) + 1) / 200));
+
+ALTER TABLE blogs MODIFY COLUMN content_html mediumtext NOT NULL COMMENT 'sanitized html rendered from content';
@@ -0,0 +1,10 @@
+ALTER TABLE blog_products DROP FOREIGN KEY fk_blog_products_product_id;
+ALTER TABLE blog_products DROP FOREIGN KEY fk_blog_products_blog_id;
+ALTER TABLE blog_tags DROP FOREIGN KEY fk_blog_tags_tag_id;
+ALTER TABLE blog_tags DROP FOREIGN KEY fk_blog_tags_blog_id;
+
+DROP TABLE IF EXISTS blog_products;
+DROP TABLE IF EXISTS blog_tags;
+DROP TABLE IF EXISTS tags;
+
+DROP INDEX blogs_search_idx ON blogs;
@@ -0,0 +1,40 @@
+-- Tags table
+CREATE TABLE tags (
+  id int unsigned AUTO_INCREMENT PRIMARY KEY,
+  name varchar(50) NOT NULL COMMENT 'lower case and url friendly, GET /blogs?tag=',
+  created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX tags_name_idx ON tags (name);
+
+-- Blog tags table
+CREATE TABLE blog_tags (
+  blog_id int unsigned NOT NULL,
+  tag_id int unsigned NOT NULL,
+  PRIMARY KEY (blog_id, tag_id)
+);
+
+CREATE INDEX blog_tags_tag_id_idx ON blog_tags (tag_id);
+
+-- Blog products table
+CREATE TABLE blog_products (
+  blog_id int unsigned NOT NULL,
+  product_id int unsigned NOT NULL,
+  position int unsigned NOT NULL DEFAULT 0 COMMENT 'order the products appear in the post',
+  PRIMARY KEY (blog_id, product_id)
+);
+
+CREATE INDEX blog_products_product_id_idx ON blog_products (product_id);
+
+CREATE FULLTEXT INDEX blogs_search_idx ON blogs (title, content);
+
+-- Foreign Keys
+-- ALTER TABLE blog_tags ADD FOREIGN KEY (blog_id) REFERENCES blogs (id);
+-- ALTER TABLE blog_tags ADD FOREIGN KEY (tag_id) REFERENCES tags (id);
+-- ALTER TABLE blog_products ADD FOREIGN KEY (blog_id) REFERENCES blogs (id);
+-- ALTER TABLE blog_products ADD FOREIGN KEY (product_id) REFERENCES products (id);
+
+ALTER TABLE blog_tags ADD CONSTRAINT fk_blog_tags_blog_id FOREIGN KEY (blog_id) REFERENCES blogs (id) ON DELETE CASCADE;
+ALTER TABLE blog_tags ADD CONSTRAINT fk_blog_tags_tag_id FOREIGN KEY (tag_id) REFERENCES tags (id) ON DELETE CASCADE;
+ALTER TABLE blog_products ADD CONSTRAINT fk_blog_products_blog_id FOREIGN KEY (blog_id) REFERENCES blogs (id) ON DELETE CASCADE;
+ALTER TABLE blog_products ADD CONSTRAINT fk_blog_products_product_id FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE;
@@ -0,0 +1,11 @@
+DELETE FROM role_permissions WHERE permission = 'comments:moderate';
+DELETE FROM permissions WHERE name = 'comments:moderate';
+
+ALTER TABLE blog_comments DROP FOREIGN KEY fk_blog_comments_moderated_by;
+ALTER TABLE blog_comments DROP FOREIGN KEY fk_blog_comments_parent_id;
+ALTER TABLE blog_comments DROP FOREIGN KEY fk_blog_comments_user_id;
+ALTER TABLE blog_comments DROP FOREIGN KEY fk_blog_comments_blog_id;
+
+DROP TABLE IF EXISTS blog_comments;
+
+ALTER TABLE blogs DROP COLUMN comment_count;
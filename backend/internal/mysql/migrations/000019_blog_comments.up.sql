@@ -0,0 +1,39 @@
+-- Blog comments table
+CREATE TABLE blog_comments (
+  id int unsigned AUTO_INCREMENT PRIMARY KEY,
+  blog_id int unsigned NOT NULL,
+  user_id int unsigned NOT NULL,
+  parent_id int unsigned NULL COMMENT 'the comment this one replies to',
+  content text NOT NULL,
+  status varchar(20) NOT NULL DEFAULT 'PENDING' COMMENT 'PENDING, APPROVED or HIDDEN, only approved comments are shown and counted',
+  moderation_reason varchar(255) NOT NULL DEFAULT '' COMMENT 'why the comment was hidden or held by the filter',
+  moderated_by int unsigned NULL,
+  moderated_at timestamp NULL,
+  deleted_at timestamp NULL COMMENT 'deleted by the author, kept so replies stay in their thread',
+  updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX blog_comments_blog_id_idx ON blog_comments (blog_id, status, created_at);
+CREATE INDEX blog_comments_user_id_idx ON blog_comments (user_id, created_at);
+CREATE INDEX blog_comments_status_idx ON blog_comments (status, created_at);
+
+ALTER TABLE blogs ADD COLUMN comment_count int unsigned NOT NULL DEFAULT 0 COMMENT 'approved comments that are not deleted';
+
+INSERT INTO permissions (name, description) VALUES
+    ('comments:moderate', 'Approve and hide blog comments');
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('ADMIN', 'comments:moderate'),
+    ('STAFF', 'comments:moderate');
+
+-- Foreign Keys
+-- ALTER TABLE blog_comments ADD FOREIGN KEY (blog_id) REFERENCES blogs (id);
+-- ALTER TABLE blog_comments ADD FOREIGN KEY (user_id) REFERENCES users (id);
+-- ALTER TABLE blog_comments ADD FOREIGN KEY (parent_id) REFERENCES blog_comments (id);
+-- ALTER TABLE blog_comments ADD FOREIGN KEY (moderated_by) REFERENCES users (id);
+
+ALTER TABLE blog_comments ADD CONSTRAINT fk_blog_comments_blog_id FOREIGN KEY (blog_id) REFERENCES blogs (id) ON DELETE CASCADE;
+ALTER TABLE blog_comments ADD CONSTRAINT fk_blog_comments_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE blog_comments ADD CONSTRAINT fk_blog_comments_parent_id FOREIGN KEY (parent_id) REFERENCES blog_comments (id) ON DELETE CASCADE;
+ALTER TABLE blog_comments ADD CONSTRAINT fk_blog_comments_moderated_by FOREIGN KEY (moderated_by) REFERENCES users (id) ON DELETE SET NULL;
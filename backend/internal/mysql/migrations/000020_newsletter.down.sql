@@ -0,0 +1,12 @@
+DELETE FROM role_permissions WHERE permission = 'newsletter:send';
+DELETE FROM permissions WHERE name = 'newsletter:send';
+
+ALTER TABLE newsletter_deliveries DROP FOREIGN KEY fk_newsletter_deliveries_user_id;
+ALTER TABLE newsletter_deliveries DROP FOREIGN KEY fk_newsletter_deliveries_campaign_id;
+ALTER TABLE newsletter_campaigns DROP FOREIGN KEY fk_newsletter_campaigns_created_by;
+ALTER TABLE newsletter_campaigns DROP FOREIGN KEY fk_newsletter_campaigns_blog_id;
+
+DROP TABLE IF EXISTS newsletter_deliveries;
+DROP TABLE IF EXISTS newsletter_campaigns;
+
+ALTER TABLE blogs DROP COLUMN announced;
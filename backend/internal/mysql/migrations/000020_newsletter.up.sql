@@ -0,0 +1,55 @@
+-- Newsletter campaigns table
+CREATE TABLE newsletter_campaigns (
+  id int unsigned AUTO_INCREMENT PRIMARY KEY,
+  subject varchar(255) NOT NULL,
+  content mediumtext NOT NULL COMMENT 'markdown source written by the sender',
+  content_html mediumtext NOT NULL COMMENT 'sanitized html rendered from content',
+  blog_id int unsigned NULL COMMENT 'set on campaigns announcing a published blog',
+  status varchar(20) NOT NULL DEFAULT 'SENDING' COMMENT 'SENDING until no delivery is pending, then SENT',
+  recipient_count int unsigned NOT NULL DEFAULT 0,
+  created_by int unsigned NULL COMMENT 'null on blog announcements',
+  sent_at timestamp NULL,
+  created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX newsletter_campaigns_blog_id_idx ON newsletter_campaigns (blog_id);
+CREATE INDEX newsletter_campaigns_status_idx ON newsletter_campaigns (status);
+
+-- Newsletter deliveries table
+CREATE TABLE newsletter_deliveries (
+  id int unsigned AUTO_INCREMENT PRIMARY KEY,
+  campaign_id int unsigned NOT NULL,
+  user_id int unsigned NOT NULL,
+  email varchar(255) NOT NULL COMMENT 'address at the time the campaign was queued',
+  status varchar(20) NOT NULL DEFAULT 'PENDING' COMMENT 'PENDING, SENT, FAILED or SKIPPED when the user unsubscribed before the send',
+  attempts int unsigned NOT NULL DEFAULT 0,
+  last_error varchar(255) NOT NULL DEFAULT '',
+  sent_at timestamp NULL,
+  updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX newsletter_deliveries_campaign_user_idx ON newsletter_deliveries (campaign_id, user_id);
+CREATE INDEX newsletter_deliveries_status_idx ON newsletter_deliveries (status, id);
+
+ALTER TABLE blogs ADD COLUMN announced boolean NOT NULL DEFAULT false COMMENT 'the blog was sent to newsletter subscribers';
+
+-- blogs published before the newsletter existed are not announced
+UPDATE blogs SET announced = true WHERE status = 'PUBLISHED';
+
+INSERT INTO permissions (name, description) VALUES
+    ('newsletter:send', 'Send newsletter campaigns and view their stats');
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('ADMIN', 'newsletter:send');
+
+-- Foreign Keys
+-- ALTER TABLE newsletter_campaigns ADD FOREIGN KEY (blog_id) REFERENCES blogs (id);
+-- ALTER TABLE newsletter_campaigns ADD FOREIGN KEY (created_by) REFERENCES users (id);
+-- ALTER TABLE newsletter_deliveries ADD FOREIGN KEY (campaign_id) REFERENCES newsletter_campaigns (id);
+-- ALTER TABLE newsletter_deliveries ADD FOREIGN KEY (user_id) REFERENCES users (id);
+
+ALTER TABLE newsletter_campaigns ADD CONSTRAINT fk_newsletter_campaigns_blog_id FOREIGN KEY (blog_id) REFERENCES blogs (id) ON DELETE SET NULL;
+ALTER TABLE newsletter_campaigns ADD CONSTRAINT fk_newsletter_campaigns_created_by FOREIGN KEY (created_by) REFERENCES users (id) ON DELETE SET NULL;
+ALTER TABLE newsletter_deliveries ADD CONSTRAINT fk_newsletter_deliveries_campaign_id FOREIGN KEY (campaign_id) REFERENCES newsletter_campaigns (id) ON DELETE CASCADE;
+ALTER TABLE newsletter_deliveries ADD CONSTRAINT fk_newsletter_deliveries_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
@@ -0,0 +1,12 @@
+ALTER TABLE newsletter_deliveries DROP FOREIGN KEY fk_newsletter_deliveries_subscriber_id;
+ALTER TABLE newsletter_subscribers DROP FOREIGN KEY fk_newsletter_subscribers_user_id;
+
+DROP INDEX newsletter_deliveries_campaign_subscriber_idx ON newsletter_deliveries;
+
+-- deliveries to subscribers have no user to keep
+DELETE FROM newsletter_deliveries WHERE user_id IS NULL;
+
+ALTER TABLE newsletter_deliveries DROP COLUMN subscriber_id;
+ALTER TABLE newsletter_deliveries MODIFY user_id int unsigned NOT NULL;
+
+DROP TABLE IF EXISTS newsletter_subscribers;
@@ -0,0 +1,29 @@
+-- Newsletter subscribers table
+CREATE TABLE newsletter_subscribers (
+  id int unsigned AUTO_INCREMENT PRIMARY KEY,
+  email varchar(255) NOT NULL,
+  status varchar(20) NOT NULL DEFAULT 'PENDING' COMMENT 'PENDING until the address is confirmed, CONFIRMED or UNSUBSCRIBED',
+  user_id int unsigned NULL COMMENT 'set once someone registers with the email, users.subscription decides from then on',
+  confirm_token_hash varchar(64) NULL COMMENT 'sha256 of the confirmation token, the token itself is only emailed',
+  confirm_expires_at timestamp NULL,
+  confirmed_at timestamp NULL,
+  unsubscribed_at timestamp NULL,
+  updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX newsletter_subscribers_email_idx ON newsletter_subscribers (email);
+CREATE UNIQUE INDEX newsletter_subscribers_token_idx ON newsletter_subscribers (confirm_token_hash);
+CREATE INDEX newsletter_subscribers_user_id_idx ON newsletter_subscribers (user_id);
+
+ALTER TABLE newsletter_deliveries MODIFY user_id int unsigned NULL COMMENT 'set on deliveries to registered users';
+ALTER TABLE newsletter_deliveries ADD COLUMN subscriber_id int unsigned NULL COMMENT 'set on deliveries to subscribers without an account';
+
+CREATE UNIQUE INDEX newsletter_deliveries_campaign_subscriber_idx ON newsletter_deliveries (campaign_id, subscriber_id);
+
+-- Foreign Keys
+-- ALTER TABLE newsletter_subscribers ADD FOREIGN KEY (user_id) REFERENCES users (id);
+-- ALTER TABLE newsletter_deliveries ADD FOREIGN KEY (subscriber_id) REFERENCES newsletter_subscribers (id);
+
+ALTER TABLE newsletter_subscribers ADD CONSTRAINT fk_newsletter_subscribers_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL;
+ALTER TABLE newsletter_deliveries ADD CONSTRAINT fk_newsletter_deliveries_subscriber_id FOREIGN KEY (subscriber_id) REFERENCES newsletter_subscribers (id) ON DELETE CASCADE;
@@ -0,0 +1,19 @@
+-- Email outbox table
+CREATE TABLE email_outbox (
+  id int unsigned AUTO_INCREMENT PRIMARY KEY,
+  recipient varchar(255) NOT NULL,
+  subject varchar(255) NOT NULL,
+  template varchar(50) NOT NULL COMMENT 'template the email was rendered from',
+  text_body mediumtext NOT NULL COMMENT 'emptied once sent, the body can hold one time links',
+  html_body mediumtext NOT NULL COMMENT 'emptied once sent, the body can hold one time links',
+  headers json NULL COMMENT 'extra message headers',
+  status varchar(20) NOT NULL DEFAULT 'PENDING' COMMENT 'PENDING, SENT or FAILED once EMAIL_MAX_ATTEMPTS is reached',
+  attempts int unsigned NOT NULL DEFAULT 0,
+  last_error varchar(255) NOT NULL DEFAULT '',
+  next_attempt_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  sent_at timestamp NULL,
+  updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX email_outbox_status_idx ON email_outbox (status, next_attempt_at);
@@ -0,0 +1,3 @@
+ALTER TABLE notification_preferences DROP FOREIGN KEY fk_notification_preferences_user_id;
+
+DROP TABLE IF EXISTS notification_preferences;
@@ -0,0 +1,13 @@
+-- Notification preferences table
+CREATE TABLE notification_preferences (
+  user_id int unsigned PRIMARY KEY,
+  order_email boolean NOT NULL DEFAULT true COMMENT 'email when an order is placed, paid or changes status',
+  order_sms boolean NOT NULL DEFAULT false COMMENT 'sms to users.phone_number for the same order updates',
+  updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Foreign Keys
+-- ALTER TABLE notification_preferences ADD FOREIGN KEY (user_id) REFERENCES users (id);
+
+ALTER TABLE notification_preferences ADD CONSTRAINT fk_notification_preferences_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
@@ -0,0 +1 @@
+ALTER TABLE newsletter_deliveries DROP COLUMN claimed_until;
@@ -0,0 +1 @@
+ALTER TABLE newsletter_deliveries ADD COLUMN claimed_until timestamp NULL COMMENT 'other workers skip the delivery until then';
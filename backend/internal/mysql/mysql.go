@@ -94,6 +94,11 @@ func (s *Store) execTx(ctx context.Context, fn func(q *generated.Queries) error)
 			return pkg.Errorf(pkg.INTERNAL_ERROR, "tx err: %v, rb err: %v", err, rbErr)
 		}
 
+		// keep the code of errors raised on purpose inside the transaction
+		if pkg.ErrorCode(err) != pkg.INTERNAL_ERROR {
+			return err
+		}
+
 		return pkg.Errorf(pkg.INTERNAL_ERROR, "tx err: %v", err)
 	}
 
@@ -0,0 +1,518 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/mysql/generated"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+	"github.com/go-sql-driver/mysql"
+)
+
+// longest send error kept on a delivery, the column is a varchar(255)
+const maxDeliveryErrorLength = 255
+
+// a pending subscriber is sent at most one confirmation email in this time,
+// the subscribe endpoint is public and should not be usable to flood an inbox
+const confirmationResendInterval = 5 * time.Minute
+
+var _ repository.NewsletterRepository = (*NewsletterRepository)(nil)
+
+type NewsletterRepository struct {
+	db      *Store
+	queries generated.Querier
+}
+
+func NewNewsletterRepository(db *Store) *NewsletterRepository {
+	q := generated.New(db.db)
+
+	return &NewsletterRepository{
+		db:      db,
+		queries: q,
+	}
+}
+
+func (n *NewsletterRepository) CreateCampaign(ctx context.Context, campaign *repository.Campaign) (*repository.Campaign, error) {
+	if campaign.Subject == "" {
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "subject is required")
+	}
+
+	if campaign.Content == "" {
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "content is required")
+	}
+
+	campaign.ContentHTML, _, _ = renderContent(campaign.Content)
+
+	var id int64
+
+	err := n.db.execTx(ctx, func(q *generated.Queries) error {
+		result, err := q.CreateCampaign(ctx, generated.CreateCampaignParams{
+			Subject:     campaign.Subject,
+			Content:     campaign.Content,
+			ContentHtml: campaign.ContentHTML,
+			BlogID:      nullUint32(campaign.BlogID),
+			CreatedBy:   nullUint32(campaign.CreatedBy),
+		})
+		if err != nil {
+			var mysqlErr *mysql.MySQLError
+			if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+				return pkg.Errorf(pkg.ALREADY_EXISTS_ERROR, "blog %d was already announced", *campaign.BlogID)
+			}
+
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create campaign: %v", err)
+		}
+
+		id, err = result.LastInsertId()
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get last inserted id: %v", err)
+		}
+
+		var recipients int64
+
+		for _, queue := range []func(context.Context, uint32) (sql.Result, error){q.QueueCampaignDeliveries, q.QueueSubscriberDeliveries} {
+			result, err := queue(ctx, uint32(id))
+			if err != nil {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to queue deliveries: %v", err)
+			}
+
+			queued, err := result.RowsAffected()
+			if err != nil {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to count recipients: %v", err)
+			}
+
+			recipients += queued
+		}
+
+		if err := q.SetCampaignRecipientCount(ctx, generated.SetCampaignRecipientCountParams{
+			RecipientCount: uint32(recipients),
+			ID:             uint32(id),
+		}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update campaign: %v", err)
+		}
+
+		if campaign.BlogID != nil {
+			if err := q.MarkBlogAnnounced(ctx, *campaign.BlogID); err != nil {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update blog: %v", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return n.GetCampaign(ctx, uint32(id))
+}
+
+func (n *NewsletterRepository) GetCampaign(ctx context.Context, id uint32) (*repository.Campaign, error) {
+	campaign, err := n.queries.GetCampaign(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "no campaign found with id %d", id)
+		}
+
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get campaign: %v", err)
+	}
+
+	return campaignFromRow(campaign), nil
+}
+
+func (n *NewsletterRepository) ListCampaigns(ctx context.Context, limit int32, offset int32) ([]*repository.Campaign, error) {
+	campaigns, err := n.queries.ListCampaigns(ctx, generated.ListCampaignsParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list campaigns: %v", err)
+	}
+
+	result := []*repository.Campaign{}
+
+	for _, campaign := range campaigns {
+		result = append(result, campaignFromRow(campaign))
+	}
+
+	return result, nil
+}
+
+func (n *NewsletterRepository) GetCampaignStats(ctx context.Context, id uint32) (*repository.CampaignStats, error) {
+	if _, err := n.GetCampaign(ctx, id); err != nil {
+		return nil, err
+	}
+
+	stats, err := n.queries.GetCampaignStats(ctx, id)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get campaign stats: %v", err)
+	}
+
+	return &repository.CampaignStats{
+		CampaignID: id,
+		Total:      stats.Total,
+		Pending:    stats.Pending,
+		Sent:       stats.Sent,
+		Failed:     stats.Failed,
+		Skipped:    stats.Skipped,
+	}, nil
+}
+
+func (n *NewsletterRepository) ListCampaignDeliveries(ctx context.Context, id uint32, status string, limit int32, offset int32) ([]*repository.Delivery, error) {
+	if _, err := n.GetCampaign(ctx, id); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := n.queries.ListCampaignDeliveries(ctx, generated.ListCampaignDeliveriesParams{
+		CampaignID: id,
+		Status:     sql.NullString{Valid: status != "", String: status},
+		Limit:      limit,
+		Offset:     offset,
+	})
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list deliveries: %v", err)
+	}
+
+	result := []*repository.Delivery{}
+
+	for _, delivery := range deliveries {
+		result = append(result, deliveryFromRow(delivery))
+	}
+
+	return result, nil
+}
+
+func (n *NewsletterRepository) ListUserDeliveries(ctx context.Context, userID uint32) ([]*repository.Delivery, error) {
+	deliveries, err := n.queries.ListUserDeliveries(ctx, nullUint32(&userID))
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list deliveries: %v", err)
+	}
+
+	result := []*repository.Delivery{}
+
+	for _, delivery := range deliveries {
+		result = append(result, deliveryFromRow(delivery))
+	}
+
+	return result, nil
+}
+
+func (n *NewsletterRepository) ListUnannouncedBlogs(ctx context.Context) ([]*repository.Blog, error) {
+	blogs, err := n.queries.ListUnannouncedBlogs(ctx)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list blogs: %v", err)
+	}
+
+	result := []*repository.Blog{}
+
+	for _, blog := range blogs {
+		result = append(result, blogFromRow(blog))
+	}
+
+	return result, nil
+}
+
+func (n *NewsletterRepository) ClaimPendingDeliveries(ctx context.Context, limit int32, lease time.Duration) ([]*repository.Delivery, error) {
+	result := []*repository.Delivery{}
+
+	// rows another worker is claiming are skipped, and the claimed ones are
+	// not pending again until the lease runs out
+	err := n.db.execTx(ctx, func(q *generated.Queries) error {
+		rows, err := q.ListPendingDeliveries(ctx, limit)
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list deliveries: %v", err)
+		}
+
+		claimedUntil := sql.NullTime{
+			Valid: true,
+			Time:  time.Now().Add(lease),
+		}
+
+		for _, row := range rows {
+			if err := q.ClaimDelivery(ctx, generated.ClaimDeliveryParams{
+				ClaimedUntil: claimedUntil,
+				ID:           row.NewsletterDelivery.ID,
+			}); err != nil {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to claim delivery: %v", err)
+			}
+
+			delivery := deliveryFromRow(row.NewsletterDelivery)
+			if delivery.UserID != nil {
+				delivery.Subscribed = row.Subscription.Bool
+			} else {
+				delivery.Subscribed = row.SubscriberStatus.String == repository.SubscriberConfirmed
+			}
+
+			result = append(result, delivery)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (n *NewsletterRepository) MarkDeliverySent(ctx context.Context, id uint32) error {
+	if err := n.queries.MarkDeliverySent(ctx, id); err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update delivery: %v", err)
+	}
+
+	return nil
+}
+
+func (n *NewsletterRepository) MarkDeliveryFailed(ctx context.Context, id uint32, reason string) error {
+	if len(reason) > maxDeliveryErrorLength {
+		reason = reason[:maxDeliveryErrorLength]
+	}
+
+	if err := n.queries.MarkDeliveryFailed(ctx, generated.MarkDeliveryFailedParams{
+		LastError:   reason,
+		MaxAttempts: n.db.config.NEWSLETTER_MAX_ATTEMPTS,
+		ID:          id,
+	}); err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update delivery: %v", err)
+	}
+
+	return nil
+}
+
+func (n *NewsletterRepository) MarkDeliverySkipped(ctx context.Context, id uint32) error {
+	if err := n.queries.MarkDeliverySkipped(ctx, id); err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update delivery: %v", err)
+	}
+
+	return nil
+}
+
+func (n *NewsletterRepository) FinishCampaigns(ctx context.Context) error {
+	if err := n.queries.FinishCampaigns(ctx); err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to finish campaigns: %v", err)
+	}
+
+	return nil
+}
+
+func (n *NewsletterRepository) Subscribe(ctx context.Context, email string, tokenHash string, expiresAt time.Time) (bool, error) {
+	if email == "" {
+		return false, pkg.Errorf(pkg.INVALID_ERROR, "email is required")
+	}
+
+	pending := true
+
+	err := n.db.execTx(ctx, func(q *generated.Queries) error {
+		var userID sql.NullInt32
+
+		user, err := q.GetUserByEmail(ctx, email)
+		if err != nil && err != sql.ErrNoRows {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get user: %v", err)
+		}
+
+		if err == nil {
+			userID = sql.NullInt32{Valid: true, Int32: int32(user.ID)}
+		}
+
+		subscriber, err := q.GetSubscriberByEmail(ctx, email)
+		if err != nil {
+			if err != sql.ErrNoRows {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get subscriber: %v", err)
+			}
+
+			if userID.Valid && user.Subscription {
+				pending = false
+
+				return nil
+			}
+
+			if _, err := q.CreateSubscriber(ctx, generated.CreateSubscriberParams{
+				Email:            email,
+				UserID:           userID,
+				ConfirmTokenHash: sql.NullString{Valid: true, String: tokenHash},
+				ConfirmExpiresAt: sql.NullTime{Valid: true, Time: expiresAt},
+			}); err != nil {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create subscriber: %v", err)
+			}
+
+			return nil
+		}
+
+		// a registered user's subscription is on the user, not the subscriber row
+		if (userID.Valid && user.Subscription) || (!userID.Valid && subscriber.Status == repository.SubscriberConfirmed) {
+			pending = false
+
+			return nil
+		}
+
+		if subscriber.Status == repository.SubscriberPending && time.Since(subscriber.UpdatedAt) < confirmationResendInterval {
+			pending = false
+
+			return nil
+		}
+
+		if err := q.RequestSubscriberConfirmation(ctx, generated.RequestSubscriberConfirmationParams{
+			UserID:           userID,
+			ConfirmTokenHash: sql.NullString{Valid: true, String: tokenHash},
+			ConfirmExpiresAt: sql.NullTime{Valid: true, Time: expiresAt},
+			ID:               subscriber.ID,
+		}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update subscriber: %v", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return pending, nil
+}
+
+func (n *NewsletterRepository) ConfirmSubscriber(ctx context.Context, tokenHash string) (*repository.Subscriber, error) {
+	var id uint32
+
+	// the subscriber row stays locked until commit so a token is only redeemed once
+	err := n.db.execTx(ctx, func(q *generated.Queries) error {
+		subscriber, err := q.GetSubscriberByToken(ctx, sql.NullString{Valid: true, String: tokenHash})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return pkg.Errorf(pkg.INVALID_ERROR, "invalid or expired confirmation link")
+			}
+
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get subscriber: %v", err)
+		}
+
+		if !subscriber.ConfirmExpiresAt.Valid || time.Now().After(subscriber.ConfirmExpiresAt.Time) {
+			return pkg.Errorf(pkg.INVALID_ERROR, "invalid or expired confirmation link")
+		}
+
+		id = subscriber.ID
+
+		if err := q.ConfirmSubscriber(ctx, subscriber.ID); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to confirm subscriber: %v", err)
+		}
+
+		if subscriber.UserID.Valid {
+			return setUserSubscription(ctx, q, uint32(subscriber.UserID.Int32), true)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	subscriber, err := n.queries.GetSubscriber(ctx, id)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get subscriber: %v", err)
+	}
+
+	return subscriberFromRow(subscriber), nil
+}
+
+func (n *NewsletterRepository) UnsubscribeSubscriber(ctx context.Context, id uint32) error {
+	return n.db.execTx(ctx, func(q *generated.Queries) error {
+		subscriber, err := q.GetSubscriber(ctx, id)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return pkg.Errorf(pkg.NOT_FOUND_ERROR, "no subscriber found with id %d", id)
+			}
+
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get subscriber: %v", err)
+		}
+
+		if err := q.UnsubscribeSubscriber(ctx, id); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to unsubscribe: %v", err)
+		}
+
+		if subscriber.UserID.Valid {
+			return setUserSubscription(ctx, q, uint32(subscriber.UserID.Int32), false)
+		}
+
+		return nil
+	})
+}
+
+// linkNewsletterSubscriber hands the subscription of the email over to the
+// newly registered user, it returns whether the user is now subscribed.
+func linkNewsletterSubscriber(ctx context.Context, q *generated.Queries, userID uint32, email string) (bool, error) {
+	subscriber, err := q.GetSubscriberByEmail(ctx, email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+
+		return false, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get subscriber: %v", err)
+	}
+
+	if err := q.LinkSubscriberUser(ctx, generated.LinkSubscriberUserParams{
+		UserID: sql.NullInt32{Valid: true, Int32: int32(userID)},
+		ID:     subscriber.ID,
+	}); err != nil {
+		return false, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to link subscriber: %v", err)
+	}
+
+	if subscriber.Status != repository.SubscriberConfirmed {
+		return false, nil
+	}
+
+	return true, setUserSubscription(ctx, q, userID, true)
+}
+
+func setUserSubscription(ctx context.Context, q *generated.Queries, userID uint32, subscribed bool) error {
+	if err := q.UpdateSubscriptionStatus(ctx, generated.UpdateSubscriptionStatusParams{
+		Subscription: subscribed,
+		UpdatedBy:    sql.NullInt32{Valid: true, Int32: int32(userID)},
+		ID:           userID,
+	}); err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update subscription: %v", err)
+	}
+
+	return nil
+}
+
+func campaignFromRow(campaign generated.NewsletterCampaign) *repository.Campaign {
+	return &repository.Campaign{
+		ID:             campaign.ID,
+		Subject:        campaign.Subject,
+		Content:        campaign.Content,
+		ContentHTML:    campaign.ContentHtml,
+		BlogID:         uint32Ptr(campaign.BlogID),
+		Status:         campaign.Status,
+		RecipientCount: campaign.RecipientCount,
+		CreatedBy:      uint32Ptr(campaign.CreatedBy),
+		SentAt:         timePtr(campaign.SentAt),
+		CreatedAt:      campaign.CreatedAt,
+	}
+}
+
+func deliveryFromRow(delivery generated.NewsletterDelivery) *repository.Delivery {
+	return &repository.Delivery{
+		ID:           delivery.ID,
+		CampaignID:   delivery.CampaignID,
+		UserID:       uint32Ptr(delivery.UserID),
+		SubscriberID: uint32Ptr(delivery.SubscriberID),
+		Email:        delivery.Email,
+		Status:       delivery.Status,
+		Attempts:     delivery.Attempts,
+		LastError:    delivery.LastError,
+		SentAt:       timePtr(delivery.SentAt),
+		UpdatedAt:    delivery.UpdatedAt,
+		CreatedAt:    delivery.CreatedAt,
+	}
+}
+
+func subscriberFromRow(subscriber generated.NewsletterSubscriber) *repository.Subscriber {
+	return &repository.Subscriber{
+		ID:             subscriber.ID,
+		Email:          subscriber.Email,
+		Status:         subscriber.Status,
+		UserID:         uint32Ptr(subscriber.UserID),
+		ConfirmedAt:    timePtr(subscriber.ConfirmedAt),
+		UnsubscribedAt: timePtr(subscriber.UnsubscribedAt),
+		UpdatedAt:      subscriber.UpdatedAt,
+		CreatedAt:      subscriber.CreatedAt,
+	}
+}
@@ -0,0 +1,138 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/mysql/generated"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+	"github.com/go-sql-driver/mysql"
+)
+
+var _ repository.NotificationRepository = (*NotificationRepository)(nil)
+
+type NotificationRepository struct {
+	db      *Store
+	queries generated.Querier
+}
+
+func NewNotificationRepository(db *Store) *NotificationRepository {
+	q := generated.New(db.db)
+
+	return &NotificationRepository{
+		db:      db,
+		queries: q,
+	}
+}
+
+func (n *NotificationRepository) ListUserNotifications(ctx context.Context, filter repository.NotificationFilter) ([]*repository.Notification, error) {
+	req := generated.ListUserNotificationsParams{
+		UserID: filter.UserID,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}
+
+	if filter.Unread {
+		req.Unread = true
+	}
+
+	notifications, err := n.queries.ListUserNotifications(ctx, req)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list notifications: %v", err)
+	}
+
+	result := []*repository.Notification{}
+	for _, notification := range notifications {
+		result = append(result, &repository.Notification{
+			ID:        notification.ID,
+			UserID:    notification.UserID,
+			Type:      notification.Type,
+			Title:     notification.Title,
+			Message:   notification.Message,
+			ReadAt:    timePtr(notification.ReadAt),
+			CreatedAt: notification.CreatedAt,
+		})
+	}
+
+	return result, nil
+}
+
+func (n *NotificationRepository) MarkNotificationRead(ctx context.Context, userID uint32, id uint32) error {
+	result, err := n.queries.MarkNotificationRead(ctx, generated.MarkNotificationReadParams{
+		ReadAt: sql.NullTime{
+			Valid: true,
+			Time:  time.Now(),
+		},
+		ID:     id,
+		UserID: userID,
+	})
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to mark notification read: %v", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get rows affected: %v", err)
+	}
+
+	if rows == 0 {
+		return pkg.Errorf(pkg.NOT_FOUND_ERROR, "no unread notification found with id %d", id)
+	}
+
+	return nil
+}
+
+func (n *NotificationRepository) MarkAllNotificationsRead(ctx context.Context, userID uint32) error {
+	err := n.queries.MarkAllNotificationsRead(ctx, generated.MarkAllNotificationsReadParams{
+		ReadAt: sql.NullTime{
+			Valid: true,
+			Time:  time.Now(),
+		},
+		UserID: userID,
+	})
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to mark notifications read: %v", err)
+	}
+
+	return nil
+}
+
+func (n *NotificationRepository) GetNotificationPreferences(ctx context.Context, userID uint32) (*repository.NotificationPreferences, error) {
+	preferences, err := n.queries.GetNotificationPreferences(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return &repository.NotificationPreferences{
+				UserID:     userID,
+				OrderEmail: true,
+			}, nil
+		}
+
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get notification preferences: %v", err)
+	}
+
+	return &repository.NotificationPreferences{
+		UserID:     preferences.UserID,
+		OrderEmail: preferences.OrderEmail,
+		OrderSMS:   preferences.OrderSms,
+	}, nil
+}
+
+func (n *NotificationRepository) UpdateNotificationPreferences(ctx context.Context, preferences *repository.NotificationPreferences) error {
+	if err := n.queries.UpsertNotificationPreferences(ctx, generated.UpsertNotificationPreferencesParams{
+		UserID:     preferences.UserID,
+		OrderEmail: preferences.OrderEmail,
+		OrderSms:   preferences.OrderSMS,
+	}); err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1452 {
+			return pkg.Errorf(pkg.NOT_FOUND_ERROR, "no user found with id %d", preferences.UserID)
+		}
+
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update notification preferences: %v", err)
+	}
+
+	return nil
+}
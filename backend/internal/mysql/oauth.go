@@ -0,0 +1,152 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/mysql/generated"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+	"github.com/go-sql-driver/mysql"
+)
+
+var _ repository.OAuthRepository = (*OAuthRepository)(nil)
+
+type OAuthRepository struct {
+	db      *Store
+	queries generated.Querier
+}
+
+func NewOAuthRepository(db *Store) *OAuthRepository {
+	q := generated.New(db.db)
+
+	return &OAuthRepository{
+		db:      db,
+		queries: q,
+	}
+}
+
+func (o *OAuthRepository) CreateOAuthState(ctx context.Context, state *repository.OAuthState) error {
+	// abandoned logins leave states behind, clear them out as new ones come in
+	if err := o.queries.DeleteExpiredOAuthStates(ctx, time.Now().UTC()); err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to delete expired oauth states: %v", err)
+	}
+
+	err := o.queries.CreateOAuthState(ctx, generated.CreateOAuthStateParams{
+		State:        state.State,
+		Provider:     state.Provider,
+		CodeVerifier: state.CodeVerifier,
+		Nonce:        state.Nonce,
+		ExpiresAt:    state.ExpiresAt.UTC(),
+	})
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create oauth state: %v", err)
+	}
+
+	return nil
+}
+
+func (o *OAuthRepository) ConsumeOAuthState(ctx context.Context, state string) (*repository.OAuthState, error) {
+	var result *repository.OAuthState
+
+	err := o.db.execTx(ctx, func(q *generated.Queries) error {
+		oauthState, err := q.GetOAuthState(ctx, state)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return pkg.Errorf(pkg.AUTHENTICATION_ERROR, "unknown or already used login state")
+			}
+
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get oauth state: %v", err)
+		}
+
+		if err := q.DeleteOAuthState(ctx, state); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to delete oauth state: %v", err)
+		}
+
+		result = &repository.OAuthState{
+			State:        oauthState.State,
+			Provider:     oauthState.Provider,
+			CodeVerifier: oauthState.CodeVerifier,
+			Nonce:        oauthState.Nonce,
+			ExpiresAt:    oauthState.ExpiresAt,
+			CreatedAt:    oauthState.CreatedAt,
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if time.Now().After(result.ExpiresAt) {
+		return nil, pkg.Errorf(pkg.AUTHENTICATION_ERROR, "login state has expired, kindly start again")
+	}
+
+	return result, nil
+}
+
+func (o *OAuthRepository) CreateUserIdentity(ctx context.Context, identity *repository.UserIdentity) error {
+	err := o.queries.CreateUserIdentity(ctx, generated.CreateUserIdentityParams{
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		UserID:   identity.UserID,
+		Email:    identity.Email,
+	})
+	if err != nil {
+		if mysqlErr, ok := err.(*mysql.MySQLError); ok {
+			switch mysqlErr.Number {
+			case 1062:
+				return pkg.Errorf(pkg.ALREADY_EXISTS_ERROR, "%s identity already linked", identity.Provider)
+			case 1452:
+				return pkg.Errorf(pkg.NOT_FOUND_ERROR, "no user found with id %d", identity.UserID)
+			}
+		}
+
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create user identity: %v", err)
+	}
+
+	return nil
+}
+
+func (o *OAuthRepository) GetUserIdentity(ctx context.Context, provider string, subject string) (*repository.UserIdentity, error) {
+	identity, err := o.queries.GetUserIdentity(ctx, generated.GetUserIdentityParams{
+		Provider: provider,
+		Subject:  subject,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "no %s identity found", provider)
+		}
+
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get user identity: %v", err)
+	}
+
+	return &repository.UserIdentity{
+		Provider:  identity.Provider,
+		Subject:   identity.Subject,
+		UserID:    identity.UserID,
+		Email:     identity.Email,
+		CreatedAt: identity.CreatedAt,
+	}, nil
+}
+
+func (o *OAuthRepository) ListUserIdentities(ctx context.Context, userID uint32) ([]*repository.UserIdentity, error) {
+	identities, err := o.queries.ListUserIdentities(ctx, userID)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list user identities: %v", err)
+	}
+
+	result := []*repository.UserIdentity{}
+	for _, identity := range identities {
+		result = append(result, &repository.UserIdentity{
+			Provider:  identity.Provider,
+			Subject:   identity.Subject,
+			UserID:    identity.UserID,
+			Email:     identity.Email,
+			CreatedAt: identity.CreatedAt,
+		})
+	}
+
+	return result, nil
+}
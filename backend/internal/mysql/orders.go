@@ -3,6 +3,8 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"strconv"
 
 	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/mysql/generated"
 	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
@@ -34,6 +36,7 @@ func (o *OrderRepository) CreateOrder(ctx context.Context, order *repository.Ord
 			ShippingAddress: order.ShippingAddress,
 			ShippingAmount:  order.ShippingAmount,
 			UpdatedBy:       order.UserID,
+			AddressID:       nullUint32(order.AddressID),
 		})
 		if err != nil {
 			return pkg.Errorf(pkg.INTERNAL_ERROR, "error creating order: %v", err)
@@ -110,6 +113,7 @@ func (o *OrderRepository) ListOrders(ctx context.Context) ([]*repository.Order,
 			ShippingAmount:  order.ShippingAmount,
 			Status:          order.Status,
 			ShippingAddress: order.ShippingAddress,
+			AddressID:       uint32Ptr(order.AddressID),
 			UpdatedBy:       order.UpdatedBy,
 			UpdatedAt:       order.UpdatedAt,
 			CreatedAt:       order.CreatedAt,
@@ -136,6 +140,7 @@ func (o *OrderRepository) GetOrder(ctx context.Context, id uint32) (*repository.
 		ShippingAmount:  order.ShippingAmount,
 		Status:          order.Status,
 		ShippingAddress: order.ShippingAddress,
+		AddressID:       uint32Ptr(order.AddressID),
 		UpdatedBy:       order.UpdatedBy,
 		UpdatedAt:       order.UpdatedAt,
 		CreatedAt:       order.CreatedAt,
@@ -161,6 +166,7 @@ func (o *OrderRepository) ListOrderWithStatus(ctx context.Context, status string
 			ShippingAmount:  order.ShippingAmount,
 			Status:          order.Status,
 			ShippingAddress: order.ShippingAddress,
+			AddressID:       uint32Ptr(order.AddressID),
 			UpdatedBy:       order.UpdatedBy,
 			UpdatedAt:       order.UpdatedAt,
 			CreatedAt:       order.CreatedAt,
@@ -189,6 +195,7 @@ func (o *OrderRepository) ListUserOrders(ctx context.Context, userID uint32) ([]
 			ShippingAmount:  order.ShippingAmount,
 			Status:          order.Status,
 			ShippingAddress: order.ShippingAddress,
+			AddressID:       uint32Ptr(order.AddressID),
 			UpdatedBy:       order.UpdatedBy,
 			UpdatedAt:       order.UpdatedAt,
 			CreatedAt:       order.CreatedAt,
@@ -342,3 +349,84 @@ func (o *OrderRepository) DeleteOrderOrderItems(ctx context.Context, orderID uin
 
 	return nil
 }
+
+func (o *OrderRepository) RecordPayment(ctx context.Context, payment *repository.Payment) (*repository.Payment, error) {
+	order, err := o.GetOrder(ctx, payment.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if payment.Amount <= 0 {
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "amount must be greater than 0")
+	}
+
+	details := payment.PaymentDetails
+	if len(details) == 0 {
+		details = json.RawMessage("{}")
+	}
+
+	result, err := o.queries.CreateTransaction(ctx, generated.CreateTransactionParams{
+		UserID:            order.UserID,
+		OrderID:           order.ID,
+		PaymentMethod:     payment.PaymentMethod,
+		Amount:            strconv.FormatFloat(payment.Amount, 'f', 2, 64),
+		Status:            payment.Succeeded,
+		PaymentDetails:    details,
+		ResultDescription: payment.ResultDescription,
+	})
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to record payment: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get last inserted id: %v", err)
+	}
+
+	transaction, err := o.queries.GetTransaction(ctx, uint32(id))
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get payment: %v", err)
+	}
+
+	return paymentFromRow(transaction)
+}
+
+func (o *OrderRepository) ListOrderPayments(ctx context.Context, orderID uint32) ([]*repository.Payment, error) {
+	transactions, err := o.queries.ListOrderTransactions(ctx, orderID)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list payments: %v", err)
+	}
+
+	result := []*repository.Payment{}
+
+	for _, transaction := range transactions {
+		payment, err := paymentFromRow(transaction)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, payment)
+	}
+
+	return result, nil
+}
+
+func paymentFromRow(transaction generated.Transaction) (*repository.Payment, error) {
+	amount, err := strconv.ParseFloat(transaction.Amount, 64)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "invalid payment amount %q: %v", transaction.Amount, err)
+	}
+
+	return &repository.Payment{
+		ID:                transaction.ID,
+		UserID:            transaction.UserID,
+		OrderID:           transaction.OrderID,
+		PaymentMethod:     transaction.PaymentMethod,
+		Amount:            amount,
+		Succeeded:         transaction.Status,
+		PaymentDetails:    transaction.PaymentDetails,
+		ResultDescription: transaction.ResultDescription,
+		UpdatedAt:         transaction.UpdatedAt,
+		CreatedAt:         transaction.CreatedAt,
+	}, nil
+}
@@ -0,0 +1,53 @@
+-- name: GetUserAddress :one
+SELECT * FROM addresses
+WHERE id = ? AND user_id = ? LIMIT 1;
+
+-- name: GetDefaultAddress :one
+SELECT * FROM addresses
+WHERE user_id = ? AND is_default = true LIMIT 1;
+
+-- name: ListUserAddresses :many
+SELECT * FROM addresses
+WHERE user_id = ?
+ORDER BY is_default DESC, created_at DESC;
+
+-- name: CountUserAddresses :one
+SELECT COUNT(*) FROM addresses
+WHERE user_id = ?;
+
+-- name: CreateAddress :execresult
+INSERT INTO addresses (
+  user_id, recipient_name, phone_number, county, city, street, postal_code, is_default
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: UpdateAddress :exec
+UPDATE addresses
+  set recipient_name = coalesce(sqlc.narg('recipient_name'), recipient_name),
+  phone_number = coalesce(sqlc.narg('phone_number'), phone_number),
+  county = coalesce(sqlc.narg('county'), county),
+  city = coalesce(sqlc.narg('city'), city),
+  street = coalesce(sqlc.narg('street'), street),
+  postal_code = coalesce(sqlc.narg('postal_code'), postal_code),
+  updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg('id') AND user_id = sqlc.arg('user_id');
+
+-- name: ClearDefaultAddress :exec
+UPDATE addresses
+  set is_default = false
+WHERE user_id = ? AND is_default = true;
+
+-- name: SetDefaultAddress :exec
+UPDATE addresses
+  set is_default = true,
+  updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND user_id = ?;
+
+-- name: DeleteAddress :exec
+DELETE FROM addresses
+WHERE id = ? AND user_id = ?;
+
+-- name: DeleteUserAddresses :exec
+DELETE FROM addresses
+WHERE user_id = ?;
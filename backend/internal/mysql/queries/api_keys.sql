@@ -0,0 +1,40 @@
+-- name: CreateAPIKey :execresult
+INSERT INTO api_keys (
+  name, prefix, key_hash, scopes, created_by, expires_at
+) VALUES (
+  ?, ?, ?, ?, ?, ?
+);
+
+-- name: GetAPIKey :one
+SELECT * FROM api_keys
+WHERE id = ? LIMIT 1;
+
+-- name: GetActiveAPIKeyByHash :one
+SELECT k.id, k.name, k.prefix, k.scopes, k.created_by, k.expires_at, k.last_used_at, k.created_at, u.email, u.role
+FROM api_keys k
+JOIN users u ON u.id = k.created_by
+WHERE k.key_hash = ?
+  AND k.revoked_at IS NULL
+  AND (k.expires_at IS NULL OR k.expires_at > sqlc.arg('now'))
+  AND u.disabled_at IS NULL
+  AND u.deleted_at IS NULL
+LIMIT 1;
+
+-- name: ListAPIKeys :many
+SELECT * FROM api_keys
+ORDER BY created_at DESC, id DESC;
+
+-- name: RevokeAPIKey :execresult
+UPDATE api_keys
+  SET revoked_at = ?
+WHERE id = ? AND revoked_at IS NULL;
+
+-- name: TouchAPIKey :exec
+UPDATE api_keys
+  SET last_used_at = sqlc.arg('now')
+WHERE id = sqlc.arg('id')
+  AND (last_used_at IS NULL OR last_used_at < sqlc.arg('used_before'));
+
+-- name: DeleteUserAPIKeys :exec
+DELETE FROM api_keys
+WHERE created_by = ?;
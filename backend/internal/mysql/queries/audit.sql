@@ -0,0 +1,18 @@
+-- name: CreateAuditEntry :exec
+INSERT INTO audit_log (
+  actor_id, action, entity_type, entity_id, `before`, `after`, ip_address, request_id
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: ListAuditEntries :many
+SELECT * FROM audit_log
+WHERE (sqlc.narg('actor_id') IS NULL OR actor_id = sqlc.narg('actor_id'))
+  AND (sqlc.narg('action') IS NULL OR action = sqlc.narg('action'))
+  AND (sqlc.narg('entity_type') IS NULL OR entity_type = sqlc.narg('entity_type'))
+  AND (sqlc.narg('entity_id') IS NULL OR entity_id = sqlc.narg('entity_id'))
+  AND (sqlc.narg('request_id') IS NULL OR request_id = sqlc.narg('request_id'))
+  AND (sqlc.narg('created_after') IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before') IS NULL OR created_at < sqlc.narg('created_before'))
+ORDER BY created_at DESC, id DESC
+LIMIT ? OFFSET ?;
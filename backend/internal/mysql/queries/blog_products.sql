@@ -0,0 +1,12 @@
+-- name: AddBlogProduct :exec
+INSERT INTO blog_products (blog_id, product_id, position) VALUES (?, ?, ?);
+
+-- name: DeleteBlogProducts :exec
+DELETE FROM blog_products
+WHERE blog_id = ?;
+
+-- name: ListBlogProducts :many
+SELECT products.* FROM products
+JOIN blog_products ON blog_products.product_id = products.id
+WHERE blog_products.blog_id = ?
+ORDER BY blog_products.position;
@@ -2,19 +2,47 @@
 SELECT * FROM blogs
 WHERE id = ? LIMIT 1;
 
+-- name: GetPublishedBlog :one
+SELECT * FROM blogs
+WHERE id = ? AND status = 'PUBLISHED' AND publish_at <= CURRENT_TIMESTAMP LIMIT 1;
+
+-- name: GetPublishedBlogBySlug :one
+SELECT * FROM blogs
+WHERE slug = ? AND status = 'PUBLISHED' AND publish_at <= CURRENT_TIMESTAMP LIMIT 1;
+
 -- name: GetBlogsByAuthor :many
 SELECT * FROM blogs
-WHERE author = ?;
+WHERE author = ? AND status = 'PUBLISHED' AND publish_at <= CURRENT_TIMESTAMP
+ORDER BY publish_at DESC;
+
+-- name: ListAuthorBlogs :many
+SELECT * FROM blogs
+WHERE author = sqlc.arg('author')
+  AND (sqlc.narg('status') IS NULL OR status = sqlc.narg('status'))
+ORDER BY updated_at DESC;
 
 -- name: ListBlogs :many
 SELECT * FROM blogs
-ORDER BY created_at DESC;
+WHERE status = 'PUBLISHED' AND publish_at <= CURRENT_TIMESTAMP
+ORDER BY publish_at DESC;
+
+-- name: SearchBlogs :many
+SELECT * FROM blogs
+WHERE status = 'PUBLISHED' AND publish_at <= CURRENT_TIMESTAMP
+  AND (sqlc.narg('tag') IS NULL OR EXISTS (
+    SELECT 1 FROM blog_tags
+    JOIN tags ON tags.id = blog_tags.tag_id
+    WHERE blog_tags.blog_id = blogs.id AND tags.name = sqlc.narg('tag')
+  ))
+  AND (sqlc.narg('query') IS NULL OR MATCH(title, content) AGAINST (sqlc.narg('query') IN NATURAL LANGUAGE MODE))
+ORDER BY MATCH(title, content) AGAINST (COALESCE(sqlc.narg('query'), '') IN NATURAL LANGUAGE MODE) DESC, publish_at DESC, id DESC
+LIMIT ? OFFSET ?;
 
 -- name: CreateBlog :execresult
 INSERT INTO blogs (
-  author, title, content, img_urls
+  author, title, content, content_html, excerpt, reading_minutes, img_urls, status, slug, publish_at
 ) VALUES (
-  ?, ?, ?, ?
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 );
 
 -- name: DeleteBlog :exec
@@ -25,6 +53,32 @@ WHERE id = ?;
 UPDATE blogs
   set title = coalesce(sqlc.narg('title'), title),
   content = coalesce(sqlc.narg('content'), content),
-  img_urls = coalesce(sqlc.narg('img_urls'), img_urls)
+  content_html = coalesce(sqlc.narg('content_html'), content_html),
+  excerpt = coalesce(sqlc.narg('excerpt'), excerpt),
+  reading_minutes = coalesce(sqlc.narg('reading_minutes'), reading_minutes),
+  img_urls = coalesce(sqlc.narg('img_urls'), img_urls),
+  status = coalesce(sqlc.narg('status'), status),
+  slug = coalesce(sqlc.narg('slug'), slug),
+  publish_at = coalesce(sqlc.narg('publish_at'), publish_at),
+  updated_at = CURRENT_TIMESTAMP
 WHERE id = sqlc.arg('id');
 
+-- name: IncrementBlogCommentCount :exec
+UPDATE blogs
+  set comment_count = comment_count + 1
+WHERE id = ?;
+
+-- name: DecrementBlogCommentCount :exec
+UPDATE blogs
+  set comment_count = comment_count - 1
+WHERE id = ? AND comment_count > 0;
+
+-- name: ListUnannouncedBlogs :many
+SELECT * FROM blogs
+WHERE status = 'PUBLISHED' AND announced = false AND publish_at <= CURRENT_TIMESTAMP
+ORDER BY publish_at;
+
+-- name: MarkBlogAnnounced :exec
+UPDATE blogs
+  set announced = true
+WHERE id = ?;
@@ -0,0 +1,57 @@
+-- name: GetComment :one
+SELECT sqlc.embed(blog_comments), users.full_name AS author_name FROM blog_comments
+JOIN users ON users.id = blog_comments.user_id
+WHERE blog_comments.id = ? LIMIT 1;
+
+-- name: ListBlogComments :many
+SELECT sqlc.embed(blog_comments), users.full_name AS author_name FROM blog_comments
+JOIN users ON users.id = blog_comments.user_id
+WHERE blog_comments.blog_id = ? AND blog_comments.status = 'APPROVED'
+ORDER BY blog_comments.created_at, blog_comments.id;
+
+-- name: ListCommentsByStatus :many
+SELECT sqlc.embed(blog_comments), users.full_name AS author_name FROM blog_comments
+JOIN users ON users.id = blog_comments.user_id
+WHERE blog_comments.status = ? AND blog_comments.deleted_at IS NULL
+ORDER BY blog_comments.created_at, blog_comments.id
+LIMIT ? OFFSET ?;
+
+-- name: ListUserComments :many
+SELECT sqlc.embed(blog_comments), users.full_name AS author_name FROM blog_comments
+JOIN users ON users.id = blog_comments.user_id
+WHERE blog_comments.user_id = ? AND blog_comments.deleted_at IS NULL
+ORDER BY blog_comments.created_at DESC;
+
+-- name: CountUserCommentsSince :one
+SELECT COUNT(*) FROM blog_comments
+WHERE user_id = ? AND created_at >= ?;
+
+-- name: CreateComment :execresult
+INSERT INTO blog_comments (
+  blog_id, user_id, parent_id, content, status, moderation_reason
+) VALUES (
+  ?, ?, ?, ?, ?, ?
+);
+
+-- name: UpdateComment :exec
+UPDATE blog_comments
+  set content = ?,
+  status = ?,
+  moderation_reason = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: ModerateComment :exec
+UPDATE blog_comments
+  set status = ?,
+  moderation_reason = ?,
+  moderated_by = ?,
+  moderated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: DeleteComment :exec
+UPDATE blog_comments
+  set content = '',
+  deleted_at = CURRENT_TIMESTAMP,
+  updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
@@ -0,0 +1,46 @@
+-- name: CreateOutboxEmail :execresult
+INSERT INTO email_outbox (
+  recipient, subject, template, text_body, html_body, headers
+) VALUES (
+  ?, ?, ?, ?, ?, ?
+);
+
+-- name: ListDueOutboxEmails :many
+SELECT * FROM email_outbox
+WHERE status = 'PENDING' AND next_attempt_at <= CURRENT_TIMESTAMP
+ORDER BY next_attempt_at, id
+LIMIT ?
+FOR UPDATE SKIP LOCKED;
+
+-- name: ClaimOutboxEmail :exec
+UPDATE email_outbox
+  set next_attempt_at = ?
+WHERE id = ?;
+
+-- name: MarkOutboxEmailSent :exec
+UPDATE email_outbox
+  set status = 'SENT',
+  attempts = attempts + 1,
+  text_body = '',
+  html_body = '',
+  sent_at = CURRENT_TIMESTAMP,
+  updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: MarkOutboxEmailFailed :exec
+UPDATE email_outbox
+  set attempts = attempts + 1,
+  last_error = sqlc.arg('last_error'),
+  status = IF(attempts >= sqlc.arg('max_attempts'), 'FAILED', 'PENDING'),
+  next_attempt_at = sqlc.arg('next_attempt_at'),
+  updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg('id');
+
+-- name: DeleteRecipientOutboxEmails :exec
+DELETE FROM email_outbox
+WHERE recipient = ?;
+
+-- name: ListRecipientOutboxEmails :many
+SELECT * FROM email_outbox
+WHERE recipient = ?
+ORDER BY id;
@@ -0,0 +1,106 @@
+-- name: CreateCampaign :execresult
+INSERT INTO newsletter_campaigns (
+  subject, content, content_html, blog_id, created_by
+) VALUES (
+  ?, ?, ?, ?, ?
+);
+
+-- name: GetCampaign :one
+SELECT * FROM newsletter_campaigns
+WHERE id = ? LIMIT 1;
+
+-- name: ListCampaigns :many
+SELECT * FROM newsletter_campaigns
+ORDER BY created_at DESC, id DESC
+LIMIT ? OFFSET ?;
+
+-- name: SetCampaignRecipientCount :exec
+UPDATE newsletter_campaigns
+  set recipient_count = ?
+WHERE id = ?;
+
+-- name: FinishCampaigns :exec
+UPDATE newsletter_campaigns
+  set status = 'SENT',
+  sent_at = CURRENT_TIMESTAMP
+WHERE status = 'SENDING' AND NOT EXISTS (
+  SELECT 1 FROM newsletter_deliveries
+  WHERE newsletter_deliveries.campaign_id = newsletter_campaigns.id AND newsletter_deliveries.status = 'PENDING'
+);
+
+-- name: QueueCampaignDeliveries :execresult
+INSERT INTO newsletter_deliveries (campaign_id, user_id, email)
+SELECT newsletter_campaigns.id, users.id, users.email FROM newsletter_campaigns
+JOIN users ON users.subscription = true
+WHERE newsletter_campaigns.id = ?;
+
+-- name: QueueSubscriberDeliveries :execresult
+INSERT INTO newsletter_deliveries (campaign_id, subscriber_id, email)
+SELECT newsletter_campaigns.id, newsletter_subscribers.id, newsletter_subscribers.email FROM newsletter_campaigns
+JOIN newsletter_subscribers ON newsletter_subscribers.status = 'CONFIRMED' AND newsletter_subscribers.user_id IS NULL
+WHERE newsletter_campaigns.id = ?;
+
+-- name: GetCampaignStats :one
+SELECT COUNT(*) AS total,
+  COUNT(CASE WHEN status = 'PENDING' THEN 1 END) AS pending,
+  COUNT(CASE WHEN status = 'SENT' THEN 1 END) AS sent,
+  COUNT(CASE WHEN status = 'FAILED' THEN 1 END) AS failed,
+  COUNT(CASE WHEN status = 'SKIPPED' THEN 1 END) AS skipped
+FROM newsletter_deliveries
+WHERE campaign_id = ?;
+
+-- name: ListCampaignDeliveries :many
+SELECT * FROM newsletter_deliveries
+WHERE campaign_id = sqlc.arg('campaign_id') AND (sqlc.narg('status') IS NULL OR status = sqlc.narg('status'))
+ORDER BY id
+LIMIT ? OFFSET ?;
+
+-- name: ListUserDeliveries :many
+SELECT * FROM newsletter_deliveries
+WHERE user_id = ?
+ORDER BY id;
+
+-- name: ListPendingDeliveries :many
+SELECT sqlc.embed(newsletter_deliveries), users.subscription, newsletter_subscribers.status AS subscriber_status FROM newsletter_deliveries
+LEFT JOIN users ON users.id = newsletter_deliveries.user_id
+LEFT JOIN newsletter_subscribers ON newsletter_subscribers.id = newsletter_deliveries.subscriber_id
+WHERE newsletter_deliveries.status = 'PENDING'
+  AND (newsletter_deliveries.claimed_until IS NULL OR newsletter_deliveries.claimed_until <= CURRENT_TIMESTAMP)
+ORDER BY newsletter_deliveries.id
+LIMIT ?
+FOR UPDATE OF newsletter_deliveries SKIP LOCKED;
+
+-- name: ClaimDelivery :exec
+UPDATE newsletter_deliveries
+  set claimed_until = ?
+WHERE id = ?;
+
+-- name: MarkDeliverySent :exec
+UPDATE newsletter_deliveries
+  set status = 'SENT',
+  attempts = attempts + 1,
+  sent_at = CURRENT_TIMESTAMP,
+  updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: MarkDeliveryFailed :exec
+UPDATE newsletter_deliveries
+  set attempts = attempts + 1,
+  last_error = sqlc.arg('last_error'),
+  status = IF(attempts >= sqlc.arg('max_attempts'), 'FAILED', 'PENDING'),
+  claimed_until = NULL,
+  updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg('id');
+
+-- name: MarkDeliverySkipped :exec
+UPDATE newsletter_deliveries
+  set status = 'SKIPPED',
+  updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: RedactUserDeliveries :exec
+UPDATE newsletter_deliveries
+  set email = '',
+  status = IF(status = 'PENDING', 'SKIPPED', status),
+  updated_at = CURRENT_TIMESTAMP
+WHERE user_id = ?;
@@ -0,0 +1,58 @@
+-- name: CreateSubscriber :execresult
+INSERT INTO newsletter_subscribers (
+  email, user_id, confirm_token_hash, confirm_expires_at
+) VALUES (
+  ?, ?, ?, ?
+);
+
+-- name: GetSubscriber :one
+SELECT * FROM newsletter_subscribers
+WHERE id = ? LIMIT 1;
+
+-- name: GetSubscriberByEmail :one
+SELECT * FROM newsletter_subscribers
+WHERE email = ? LIMIT 1
+FOR UPDATE;
+
+-- name: GetSubscriberByToken :one
+SELECT * FROM newsletter_subscribers
+WHERE confirm_token_hash = ? LIMIT 1
+FOR UPDATE;
+
+-- name: RequestSubscriberConfirmation :exec
+UPDATE newsletter_subscribers
+  set status = 'PENDING',
+  user_id = ?,
+  confirm_token_hash = ?,
+  confirm_expires_at = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: ConfirmSubscriber :exec
+UPDATE newsletter_subscribers
+  set status = 'CONFIRMED',
+  confirm_token_hash = NULL,
+  confirm_expires_at = NULL,
+  confirmed_at = CURRENT_TIMESTAMP,
+  unsubscribed_at = NULL,
+  updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: UnsubscribeSubscriber :exec
+UPDATE newsletter_subscribers
+  set status = 'UNSUBSCRIBED',
+  confirm_token_hash = NULL,
+  confirm_expires_at = NULL,
+  unsubscribed_at = CURRENT_TIMESTAMP,
+  updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: LinkSubscriberUser :exec
+UPDATE newsletter_subscribers
+  set user_id = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: DeleteUserSubscriber :exec
+DELETE FROM newsletter_subscribers
+WHERE user_id = ?;
@@ -0,0 +1,17 @@
+-- name: GetNotificationPreferences :one
+SELECT * FROM notification_preferences
+WHERE user_id = ? LIMIT 1;
+
+-- name: UpsertNotificationPreferences :exec
+INSERT INTO notification_preferences (
+  user_id, order_email, order_sms
+) VALUES (
+  ?, ?, ?
+) ON DUPLICATE KEY UPDATE
+  order_email = VALUES(order_email),
+  order_sms = VALUES(order_sms),
+  updated_at = CURRENT_TIMESTAMP;
+
+-- name: DeleteNotificationPreferences :exec
+DELETE FROM notification_preferences
+WHERE user_id = ?;
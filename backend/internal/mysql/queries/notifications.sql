@@ -0,0 +1,27 @@
+-- name: CreateNotification :execresult
+INSERT INTO notifications (
+  user_id, type, title, message
+) VALUES (
+  ?, ?, ?, ?
+);
+
+-- name: ListUserNotifications :many
+SELECT * FROM notifications
+WHERE user_id = sqlc.arg('user_id')
+  AND (sqlc.narg('unread') IS NULL OR read_at IS NULL)
+ORDER BY created_at DESC, id DESC
+LIMIT ? OFFSET ?;
+
+-- name: MarkNotificationRead :execresult
+UPDATE notifications
+  SET read_at = ?
+WHERE id = ? AND user_id = ? AND read_at IS NULL;
+
+-- name: MarkAllNotificationsRead :exec
+UPDATE notifications
+  SET read_at = ?
+WHERE user_id = ? AND read_at IS NULL;
+
+-- name: DeleteUserNotifications :exec
+DELETE FROM notifications
+WHERE user_id = ?;
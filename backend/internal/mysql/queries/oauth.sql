@@ -0,0 +1,38 @@
+-- name: CreateOAuthState :exec
+INSERT INTO oauth_states (
+  state, provider, code_verifier, nonce, expires_at
+) VALUES (
+  ?, ?, ?, ?, ?
+);
+
+-- name: GetOAuthState :one
+SELECT * FROM oauth_states
+WHERE state = ? LIMIT 1 FOR UPDATE;
+
+-- name: DeleteOAuthState :exec
+DELETE FROM oauth_states
+WHERE state = ?;
+
+-- name: DeleteExpiredOAuthStates :exec
+DELETE FROM oauth_states
+WHERE expires_at < ?;
+
+-- name: CreateUserIdentity :exec
+INSERT INTO user_identities (
+  provider, subject, user_id, email
+) VALUES (
+  ?, ?, ?, ?
+);
+
+-- name: GetUserIdentity :one
+SELECT * FROM user_identities
+WHERE provider = ? AND subject = ? LIMIT 1;
+
+-- name: ListUserIdentities :many
+SELECT * FROM user_identities
+WHERE user_id = ?
+ORDER BY created_at;
+
+-- name: DeleteUserIdentities :exec
+DELETE FROM user_identities
+WHERE user_id = ?;
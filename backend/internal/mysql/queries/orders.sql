@@ -18,9 +18,9 @@ ORDER BY created_at DESC;
 
 -- name: CreateOrder :execresult
 INSERT INTO orders (
-  user_id, amount, shipping_address, shipping_amount, updated_by
+  user_id, amount, shipping_address, shipping_amount, updated_by, address_id
 ) VALUES (
-  ?, ?, ?, ?, ?
+  ?, ?, ?, ?, ?, ?
 );
 
 -- name: DeleteOrder :exec
@@ -32,4 +32,13 @@ UPDATE orders
   set status = sqlc.arg("status"),
   updated_by = coalesce(sqlc.narg("updated_by"), updated_by),
   updated_at = CURRENT_TIMESTAMP
-WHERE id = sqlc.arg("id");
\ No newline at end of file
+WHERE id = sqlc.arg("id");
+-- name: CountUserOpenOrders :one
+SELECT COUNT(*) FROM orders
+WHERE user_id = ? AND status != 'DELIVERED';
+
+-- name: RedactUserOrders :exec
+UPDATE orders
+  set shipping_address = ?,
+  address_id = NULL
+WHERE user_id = ?;
@@ -0,0 +1,15 @@
+-- name: CreatePasswordToken :exec
+INSERT INTO password_tokens (
+  token_hash, user_id, purpose, expires_at, created_by
+) VALUES (
+  ?, ?, ?, ?, ?
+);
+
+-- name: GetPasswordToken :one
+SELECT * FROM password_tokens
+WHERE token_hash = ? LIMIT 1
+FOR UPDATE;
+
+-- name: DeleteUserPasswordTokens :exec
+DELETE FROM password_tokens
+WHERE user_id = ?;
@@ -79,9 +79,9 @@ WHERE id = ?;
 
 -- name: UpdateRating :exec
 UPDATE products
-SET rating = (
-    SELECT AVG(rating) 
-    FROM reviews
-    WHERE reviews.product_id = products.id
-)
+SET rating = COALESCE((
+    SELECT rating_total / review_count
+    FROM product_review_summaries
+    WHERE product_review_summaries.product_id = products.id AND review_count > 0
+), 0)
 WHERE products.id = ?;
\ No newline at end of file
@@ -0,0 +1,24 @@
+-- name: GetProductReviewSummary :one
+SELECT * FROM product_review_summaries
+WHERE product_id = ? LIMIT 1;
+
+-- name: ListProductReviewSummaries :many
+SELECT * FROM product_review_summaries
+ORDER BY product_id;
+
+-- name: AdjustReviewSummary :exec
+INSERT INTO product_review_summaries (
+  product_id, review_count, rating_total, star_1, star_2, star_3, star_4, star_5, recommend_count
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+ON DUPLICATE KEY UPDATE
+  review_count = review_count + VALUES(review_count),
+  rating_total = rating_total + VALUES(rating_total),
+  star_1 = star_1 + VALUES(star_1),
+  star_2 = star_2 + VALUES(star_2),
+  star_3 = star_3 + VALUES(star_3),
+  star_4 = star_4 + VALUES(star_4),
+  star_5 = star_5 + VALUES(star_5),
+  recommend_count = recommend_count + VALUES(recommend_count),
+  updated_at = CURRENT_TIMESTAMP;
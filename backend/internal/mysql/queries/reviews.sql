@@ -5,24 +5,107 @@ ORDER BY created_at DESC;
 
 -- name: ListProductsReviews :many
 SELECT * FROM reviews
-WHERE product_id = ?
+WHERE product_id = ? AND status = 'APPROVED'
 ORDER BY created_at DESC;
 
+-- name: ListProductsReviewsByHelpful :many
+SELECT * FROM reviews
+WHERE product_id = ? AND status = 'APPROVED'
+ORDER BY helpful_count DESC, created_at DESC;
+
 -- name: ListReviews :many
 SELECT * FROM reviews
+WHERE status = 'APPROVED'
 ORDER BY created_at DESC;
 
+-- name: ListReviewsByStatus :many
+SELECT * FROM reviews
+WHERE status = ?
+ORDER BY created_at ASC
+LIMIT ? OFFSET ?;
+
 -- name: GetReview :one
 SELECT * FROM reviews
 WHERE id = ? LIMIT 1;
 
+-- name: GetReviewForUpdate :one
+SELECT * FROM reviews
+WHERE id = ? LIMIT 1
+FOR UPDATE;
+
+-- name: GetUserProductReview :one
+SELECT * FROM reviews
+WHERE user_id = ? AND product_id = ? LIMIT 1;
+
+-- name: HasDeliveredOrderWithProduct :one
+SELECT EXISTS (
+  SELECT 1 FROM orders o
+  JOIN order_items oi ON oi.order_id = o.id
+  WHERE o.user_id = ? AND oi.product_id = ? AND o.status = 'DELIVERED'
+) AS delivered;
+
 -- name: CreateReview :execresult
 INSERT INTO reviews (
-  user_id, product_id, rating, review
+  user_id, product_id, rating, review, verified_purchase, status, moderation_reason, img_urls
 ) VALUES (
-  ?, ?, ?, ?
+  ?, ?, ?, ?, ?, ?, ?, ?
 );
 
+-- name: UpdateReview :exec
+UPDATE reviews
+  SET rating = ?,
+  review = ?,
+  img_urls = ?,
+  verified_purchase = ?,
+  status = ?,
+  moderation_reason = ?,
+  moderated_by = NULL,
+  moderated_at = NULL,
+  updated_at = ?
+WHERE id = ?;
+
+-- name: ModerateReview :exec
+UPDATE reviews
+  SET status = ?,
+  moderation_reason = ?,
+  moderated_by = ?,
+  moderated_at = ?
+WHERE id = ?;
+
+-- name: UpdateReviewReply :exec
+UPDATE reviews
+  SET reply = ?,
+  replied_by = ?,
+  replied_at = ?
+WHERE id = ?;
+
+-- name: CreateReviewVote :exec
+INSERT INTO review_votes (
+  review_id, user_id
+) VALUES (
+  ?, ?
+);
+
+-- name: DeleteReviewVote :execresult
+DELETE FROM review_votes
+WHERE review_id = ? AND user_id = ?;
+
+-- name: ListUserReviewVotes :many
+SELECT review_id FROM review_votes
+WHERE user_id = ?;
+
+-- name: DeleteUserReviewVotes :exec
+DELETE FROM review_votes
+WHERE user_id = ?;
+
+-- name: UpdateHelpfulCount :exec
+UPDATE reviews
+  SET helpful_count = (
+    SELECT COUNT(*) FROM review_votes
+    WHERE review_votes.review_id = reviews.id
+)
+WHERE id = ?;
+
 -- name: DeleteReview :exec
 DELETE FROM reviews
 WHERE id = ?;
\ No newline at end of file
@@ -0,0 +1,42 @@
+-- name: GetRole :one
+SELECT * FROM roles
+WHERE name = ? LIMIT 1;
+
+-- name: ListRoles :many
+SELECT * FROM roles
+ORDER BY name;
+
+-- name: CreateRole :exec
+INSERT INTO roles (
+  name, description
+) VALUES (
+  ?, ?
+);
+
+-- name: DeleteRole :exec
+DELETE FROM roles
+WHERE name = ?;
+
+-- name: ListPermissions :many
+SELECT * FROM permissions
+ORDER BY name;
+
+-- name: ListRolePermissions :many
+SELECT permission FROM role_permissions
+WHERE role = ?
+ORDER BY permission;
+
+-- name: CreateRolePermission :exec
+INSERT INTO role_permissions (
+  role, permission
+) VALUES (
+  ?, ?
+);
+
+-- name: DeleteRolePermissions :exec
+DELETE FROM role_permissions
+WHERE role = ?;
+
+-- name: CheckRolePermission :one
+SELECT COUNT(*) FROM role_permissions
+WHERE role = ? AND permission = ?;
@@ -0,0 +1,52 @@
+-- name: GetLoginAttempt :one
+SELECT * FROM login_attempts
+WHERE scope = ? AND identifier = ? LIMIT 1;
+
+-- name: RecordLoginFailure :exec
+INSERT INTO login_attempts (
+  scope, identifier, failed_attempts, last_failed_at
+) VALUES (
+  sqlc.arg('scope'), sqlc.arg('identifier'), 1, sqlc.arg('now')
+) ON DUPLICATE KEY UPDATE
+  failed_attempts = IF(
+    last_failed_at < sqlc.arg('window_start') AND (locked_until IS NULL OR locked_until < sqlc.arg('window_start')),
+    1,
+    failed_attempts + 1
+  ),
+  last_failed_at = VALUES(last_failed_at);
+
+-- name: LockLoginAttempt :exec
+UPDATE login_attempts
+  set locked_until = ?
+WHERE scope = ? AND identifier = ?;
+
+-- name: DeleteLoginAttempt :exec
+DELETE FROM login_attempts
+WHERE scope = ? AND identifier = ?;
+
+-- name: ListLockedLoginAttempts :many
+SELECT * FROM login_attempts
+WHERE locked_until > ?
+ORDER BY locked_until DESC;
+
+-- name: CreateSecurityEvent :exec
+INSERT INTO security_events (
+  event, user_id, actor_id, email, ip_address, details
+) VALUES (
+  ?, ?, ?, ?, ?, ?
+);
+
+-- name: ListSecurityEvents :many
+SELECT * FROM security_events
+WHERE (sqlc.narg('event') IS NULL OR event = sqlc.narg('event'))
+  AND (sqlc.narg('email') IS NULL OR email = sqlc.narg('email'))
+  AND (sqlc.narg('user_id') IS NULL OR user_id = sqlc.narg('user_id'))
+ORDER BY created_at DESC, id DESC
+LIMIT ? OFFSET ?;
+
+-- name: RedactUserSecurityEvents :exec
+UPDATE security_events
+  set email = '',
+  ip_address = '',
+  details = ''
+WHERE user_id = sqlc.arg('user_id') OR email = sqlc.arg('email');
@@ -0,0 +1,24 @@
+-- name: UpsertTag :execresult
+INSERT INTO tags (name) VALUES (?)
+ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id);
+
+-- name: AddBlogTag :exec
+INSERT INTO blog_tags (blog_id, tag_id) VALUES (?, ?);
+
+-- name: DeleteBlogTags :exec
+DELETE FROM blog_tags
+WHERE blog_id = ?;
+
+-- name: ListBlogTags :many
+SELECT tags.name FROM tags
+JOIN blog_tags ON blog_tags.tag_id = tags.id
+WHERE blog_tags.blog_id = ?
+ORDER BY tags.name;
+
+-- name: ListTags :many
+SELECT tags.name, COUNT(blogs.id) AS blog_count FROM tags
+JOIN blog_tags ON blog_tags.tag_id = tags.id
+JOIN blogs ON blogs.id = blog_tags.blog_id
+WHERE blogs.status = 'PUBLISHED' AND blogs.publish_at <= CURRENT_TIMESTAMP
+GROUP BY tags.id, tags.name
+ORDER BY blog_count DESC, tags.name;
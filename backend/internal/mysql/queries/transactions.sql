@@ -0,0 +1,15 @@
+-- name: CreateTransaction :execresult
+INSERT INTO transactions (
+  user_id, order_id, payment_method, amount, status, payment_details, result_description
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: GetTransaction :one
+SELECT * FROM transactions
+WHERE id = ? LIMIT 1;
+
+-- name: ListOrderTransactions :many
+SELECT * FROM transactions
+WHERE order_id = ?
+ORDER BY created_at DESC, id DESC;
@@ -0,0 +1,43 @@
+-- name: GetUserTwoFactor :one
+SELECT * FROM user_two_factor
+WHERE user_id = ? LIMIT 1;
+
+-- name: GetUserTwoFactorForUpdate :one
+SELECT * FROM user_two_factor
+WHERE user_id = ? LIMIT 1
+FOR UPDATE;
+
+-- name: CreateUserTwoFactor :exec
+INSERT INTO user_two_factor (
+  user_id, secret, recovery_codes
+) VALUES (
+  ?, ?, ?
+) ON DUPLICATE KEY UPDATE
+  secret = VALUES(secret),
+  enabled = false,
+  recovery_codes = VALUES(recovery_codes),
+  last_used_step = 0,
+  updated_at = CURRENT_TIMESTAMP;
+
+-- name: EnableUserTwoFactor :exec
+UPDATE user_two_factor
+  set enabled = true,
+  recovery_codes = ?,
+  last_used_step = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE user_id = ?;
+
+-- name: UpdateTwoFactorLastUsedStep :execrows
+UPDATE user_two_factor
+  set last_used_step = sqlc.arg(last_used_step)
+WHERE user_id = sqlc.arg(user_id) AND last_used_step < sqlc.arg(last_used_step);
+
+-- name: UpdateTwoFactorRecoveryCodes :exec
+UPDATE user_two_factor
+  set recovery_codes = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE user_id = ?;
+
+-- name: DeleteUserTwoFactor :exec
+DELETE FROM user_two_factor
+WHERE user_id = ?;
@@ -19,19 +19,41 @@ ORDER BY email;
 SELECT * FROM users
 ORDER BY email;
 
+-- name: SearchUsers :many
+SELECT * FROM users
+WHERE (sqlc.narg('email') IS NULL OR email LIKE sqlc.narg('email'))
+  AND (sqlc.narg('role') IS NULL OR role = sqlc.narg('role'))
+  AND (sqlc.narg('created_after') IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before') IS NULL OR created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('has_orders') IS NULL OR EXISTS (SELECT 1 FROM orders WHERE orders.user_id = users.id) = sqlc.narg('has_orders'))
+  AND (sqlc.narg('disabled') IS NULL OR (disabled_at IS NOT NULL) = sqlc.narg('disabled'))
+ORDER BY created_at DESC, id DESC
+LIMIT ? OFFSET ?;
+
 -- name: CreateUser :execresult
 INSERT INTO users
     (email, password, subscription, role, refresh_token, updated_by)
 VALUES
     (?, ?, ?, ?, ?, ?);
 
--- name: DeleteUser :exec
-DELETE FROM users
+-- name: AnonymiseUser :exec
+UPDATE users
+  set email = ?,
+  password = ?,
+  full_name = '',
+  phone_number = '',
+  subscription = false,
+  refresh_token = '',
+  deleted_at = CURRENT_TIMESTAMP,
+  updated_at = CURRENT_TIMESTAMP,
+  updated_by = ?
 WHERE id = ?;
 
 -- name: UpdateUserCredentials :exec
 UPDATE users
   set password = ?,
+  password_reset_required = false,
+  tokens_valid_after = ?,
   updated_at = CURRENT_TIMESTAMP,
   updated_by = ?
 WHERE id = ?;
@@ -53,4 +75,30 @@ WHERE id = ?;
 -- name: UpdateRefreshToken :exec
 UPDATE users
   set refresh_token = ?
-WHERE id = ?;
\ No newline at end of file
+WHERE id = ?;
+
+-- name: UpdateUserProfile :exec
+UPDATE users
+  set full_name = coalesce(sqlc.narg('full_name'), full_name),
+  phone_number = coalesce(sqlc.narg('phone_number'), phone_number),
+  updated_at = CURRENT_TIMESTAMP,
+  updated_by = sqlc.arg('updated_by')
+WHERE id = sqlc.arg('id');
+
+-- name: UpdateUserDisabled :exec
+UPDATE users
+  set disabled_at = ?,
+  refresh_token = '',
+  tokens_valid_after = ?,
+  updated_at = CURRENT_TIMESTAMP,
+  updated_by = ?
+WHERE id = ?;
+
+-- name: RequirePasswordReset :exec
+UPDATE users
+  set password_reset_required = true,
+  refresh_token = '',
+  tokens_valid_after = ?,
+  updated_at = CURRENT_TIMESTAMP,
+  updated_by = ?
+WHERE id = ?;
@@ -2,10 +2,15 @@ package mysql
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
 
 	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/mysql/generated"
 	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
 	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+	"github.com/go-sql-driver/mysql"
 )
 
 var _ repository.ReviewRepository = (*ReviewsRepository)(nil)
@@ -13,6 +18,7 @@ var _ repository.ReviewRepository = (*ReviewsRepository)(nil)
 type ReviewsRepository struct {
 	db      *Store
 	queries generated.Querier
+	filter  *pkg.ContentFilter
 }
 
 func NewReviewRepository(db *Store) *ReviewsRepository {
@@ -21,53 +27,189 @@ func NewReviewRepository(db *Store) *ReviewsRepository {
 	return &ReviewsRepository{
 		db:      db,
 		queries: q,
+		filter:  pkg.NewContentFilter(db.config.REVIEW_BLOCKED_WORDS),
 	}
 }
 
+// moderationStatus publishes reviews the filter passes, everything else
+// waits in the moderation queue.
+func (r *ReviewsRepository) moderationStatus(text string) (string, string) {
+	if !r.db.config.REVIEW_FILTER_ENABLED {
+		return repository.ReviewPending, ""
+	}
+
+	if reason := r.filter.Check(text); reason != "" {
+		return repository.ReviewPending, reason
+	}
+
+	return repository.ReviewApproved, ""
+}
+
 func (r *ReviewsRepository) CreateReview(ctx context.Context, review *repository.Review) (*repository.Review, error) {
 	if err := review.Validate(); err != nil {
 		return nil, pkg.Errorf(pkg.INVALID_ERROR, "%v", err)
 	}
 
-	result, err := r.queries.CreateReview(ctx, generated.CreateReviewParams{
-		ProductID: review.ProductID,
-		UserID:    review.UserID,
-		Rating:    review.Rating,
-		Review:    review.Review,
-	})
-	if err != nil {
-		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "%v", err)
+	if len(review.ImgUrls) == 0 {
+		review.ImgUrls = json.RawMessage("[]")
 	}
 
-	id, err := result.LastInsertId()
+	err := r.db.execTx(ctx, func(q *generated.Queries) error {
+		existing, err := q.GetUserProductReview(ctx, generated.GetUserProductReviewParams{
+			UserID:    review.UserID,
+			ProductID: review.ProductID,
+		})
+		if err == nil {
+			return pkg.Errorf(pkg.ALREADY_EXISTS_ERROR, "you already reviewed this product, update review %d instead", existing.ID)
+		}
+
+		if err != sql.ErrNoRows {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get review: %v", err)
+		}
+
+		verified, err := q.HasDeliveredOrderWithProduct(ctx, generated.HasDeliveredOrderWithProductParams{
+			UserID:    review.UserID,
+			ProductID: review.ProductID,
+		})
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to check orders: %v", err)
+		}
+
+		if !verified && r.db.config.REVIEWS_REQUIRE_PURCHASE {
+			return pkg.Errorf(pkg.FORBIDDEN_ERROR, "only customers with a delivered order of this product can review it")
+		}
+
+		status, reason := r.moderationStatus(review.Review)
+
+		result, err := q.CreateReview(ctx, generated.CreateReviewParams{
+			ProductID:        review.ProductID,
+			UserID:           review.UserID,
+			Rating:           review.Rating,
+			Review:           review.Review,
+			VerifiedPurchase: verified,
+			Status:           status,
+			ModerationReason: reason,
+			ImgUrls:          review.ImgUrls,
+		})
+		if err != nil {
+			// lost a race with another request for the same product
+			if mysqlErr, ok := err.(*mysql.MySQLError); ok && mysqlErr.Number == 1062 {
+				return pkg.Errorf(pkg.ALREADY_EXISTS_ERROR, "you already reviewed this product")
+			}
+
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "%v", err)
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get last inserted id: %v", err)
+		}
+
+		review.ID = uint32(id)
+
+		if status == repository.ReviewApproved {
+			if err := adjustReviewSummary(ctx, q, review.ProductID, review.Rating, 1); err != nil {
+				return err
+			}
+		}
+
+		// update the products new rating
+		if err := q.UpdateRating(ctx, review.ProductID); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update rating: %v", err)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get last inserted id: %v", err)
+		return nil, err
 	}
 
-	review.ID = uint32(id)
+	return r.GetReview(ctx, review.ID)
+}
+
+func (r *ReviewsRepository) UpdateUserReview(ctx context.Context, review *repository.Review) (*repository.Review, error) {
+	err := r.db.execTx(ctx, func(q *generated.Queries) error {
+		existing, err := getReviewForUpdate(ctx, q, review.ID)
+		if err != nil {
+			return err
+		}
+
+		if existing.UserID != review.UserID {
+			return pkg.Errorf(pkg.FORBIDDEN_ERROR, "review %d belongs to another user", review.ID)
+		}
+
+		review.ProductID = existing.ProductID
+
+		// photos are kept unless the edit replaces them
+		if review.ImgUrls == nil {
+			review.ImgUrls = existing.ImgUrls
+		}
+
+		if err := review.Validate(); err != nil {
+			return pkg.Errorf(pkg.INVALID_ERROR, "%v", err)
+		}
+
+		// the order may have been delivered since the review was written
+		verified, err := q.HasDeliveredOrderWithProduct(ctx, generated.HasDeliveredOrderWithProductParams{
+			UserID:    review.UserID,
+			ProductID: review.ProductID,
+		})
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to check orders: %v", err)
+		}
+
+		status, reason := r.moderationStatus(review.Review)
+
+		if err := q.UpdateReview(ctx, generated.UpdateReviewParams{
+			Rating:           review.Rating,
+			Review:           review.Review,
+			ImgUrls:          review.ImgUrls,
+			VerifiedPurchase: verified,
+			Status:           status,
+			ModerationReason: reason,
+			UpdatedAt:        time.Now(),
+			ID:               review.ID,
+		}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update review: %v", err)
+		}
+
+		if existing.Status == repository.ReviewApproved {
+			if err := adjustReviewSummary(ctx, q, existing.ProductID, existing.Rating, -1); err != nil {
+				return err
+			}
+		}
+
+		if status == repository.ReviewApproved {
+			if err := adjustReviewSummary(ctx, q, review.ProductID, review.Rating, 1); err != nil {
+				return err
+			}
+		}
 
-	// update the products new rating
-	err = r.queries.UpdateRating(ctx, review.ProductID)
+		// update the products new rating
+		if err := q.UpdateRating(ctx, review.ProductID); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update rating: %v", err)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update rating: %v", err)
+		return nil, err
 	}
 
-	return review, nil
+	return r.GetReview(ctx, review.ID)
 }
 
 func (r *ReviewsRepository) GetReview(ctx context.Context, id uint32) (*repository.Review, error) {
 	review, err := r.queries.GetReview(ctx, id)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "no review found with id %d", id)
+		}
+
 		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "%v", err)
 	}
 
-	return &repository.Review{
-		ID:        review.ID,
-		ProductID: review.ProductID,
-		UserID:    review.UserID,
-		Rating:    review.Rating,
-		Review:    review.Review,
-	}, nil
+	return reviewFromRow(review), nil
 }
 
 func (r *ReviewsRepository) ListReviews(ctx context.Context) ([]*repository.Review, error) {
@@ -78,13 +220,7 @@ func (r *ReviewsRepository) ListReviews(ctx context.Context) ([]*repository.Revi
 
 	var result []*repository.Review
 	for _, review := range reviews {
-		result = append(result, &repository.Review{
-			ID:        review.ID,
-			ProductID: review.ProductID,
-			UserID:    review.UserID,
-			Rating:    review.Rating,
-			Review:    review.Review,
-		})
+		result = append(result, reviewFromRow(review))
 	}
 
 	return result, nil
@@ -98,43 +234,427 @@ func (r *ReviewsRepository) ListUsersReviews(ctx context.Context, userID uint32)
 
 	var result []*repository.Review
 	for _, review := range reviews {
-		result = append(result, &repository.Review{
-			ID:        review.ID,
-			ProductID: review.ProductID,
-			UserID:    review.UserID,
-			Rating:    review.Rating,
-			Review:    review.Review,
-		})
+		result = append(result, reviewFromRow(review))
 	}
 
 	return result, nil
 }
 
-func (r *ReviewsRepository) ListProductsReviews(ctx context.Context, productID uint32) ([]*repository.Review, error) {
-	reviews, err := r.queries.ListProductsReviews(ctx, productID)
+func (r *ReviewsRepository) ListProductsReviews(ctx context.Context, productID uint32, sort string) ([]*repository.Review, error) {
+	var reviews []generated.Review
+	var err error
+
+	switch sort {
+	case repository.ReviewSortNewest, "":
+		reviews, err = r.queries.ListProductsReviews(ctx, productID)
+	case repository.ReviewSortHelpful:
+		reviews, err = r.queries.ListProductsReviewsByHelpful(ctx, productID)
+	default:
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "sort must be %s or %s", repository.ReviewSortNewest, repository.ReviewSortHelpful)
+	}
 	if err != nil {
 		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "%v", err)
 	}
 
 	var result []*repository.Review
 	for _, review := range reviews {
-		result = append(result, &repository.Review{
-			ID:        review.ID,
-			ProductID: review.ProductID,
-			UserID:    review.UserID,
-			Rating:    review.Rating,
-			Review:    review.Review,
-		})
+		result = append(result, reviewFromRow(review))
+	}
+
+	return result, nil
+}
+
+func (r *ReviewsRepository) ListReviewsByStatus(ctx context.Context, status string, limit int32, offset int32) ([]*repository.Review, error) {
+	reviews, err := r.queries.ListReviewsByStatus(ctx, generated.ListReviewsByStatusParams{
+		Status: status,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "%v", err)
+	}
+
+	result := []*repository.Review{}
+	for _, review := range reviews {
+		result = append(result, reviewFromRow(review))
 	}
 
 	return result, nil
 }
 
+func (r *ReviewsRepository) ModerateReview(ctx context.Context, id uint32, moderatorID uint32, status string, reason string) (*repository.Review, error) {
+	if status != repository.ReviewApproved && status != repository.ReviewRejected {
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "status must be %s or %s", repository.ReviewApproved, repository.ReviewRejected)
+	}
+
+	if status == repository.ReviewRejected && reason == "" {
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "reason is required when rejecting a review")
+	}
+
+	err := r.db.execTx(ctx, func(q *generated.Queries) error {
+		review, err := getReviewForUpdate(ctx, q, id)
+		if err != nil {
+			return err
+		}
+
+		if err := q.ModerateReview(ctx, generated.ModerateReviewParams{
+			Status:           status,
+			ModerationReason: reason,
+			ModeratedBy:      nullUint32(&moderatorID),
+			ModeratedAt: sql.NullTime{
+				Valid: true,
+				Time:  time.Now(),
+			},
+			ID: id,
+		}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to moderate review: %v", err)
+		}
+
+		if review.Status == repository.ReviewApproved {
+			if err := adjustReviewSummary(ctx, q, review.ProductID, review.Rating, -1); err != nil {
+				return err
+			}
+		}
+
+		if status == repository.ReviewApproved {
+			if err := adjustReviewSummary(ctx, q, review.ProductID, review.Rating, 1); err != nil {
+				return err
+			}
+		}
+
+		// update the products new rating
+		if err := q.UpdateRating(ctx, review.ProductID); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update rating: %v", err)
+		}
+
+		productName, err := q.GetProductName(ctx, review.ProductID)
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get product name: %v", err)
+		}
+
+		notification := generated.CreateNotificationParams{
+			UserID:  review.UserID,
+			Type:    repository.NotificationReviewApproved,
+			Title:   "Your review was published",
+			Message: fmt.Sprintf("Your review of %s is now visible on the product page.", productName),
+		}
+
+		if status == repository.ReviewRejected {
+			notification.Type = repository.NotificationReviewRejected
+			notification.Title = "Your review was not published"
+			notification.Message = fmt.Sprintf("Your review of %s was rejected: %s", productName, reason)
+		}
+
+		if _, err := q.CreateNotification(ctx, notification); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create notification: %v", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetReview(ctx, id)
+}
+
+func (r *ReviewsRepository) VoteReviewHelpful(ctx context.Context, id uint32, userID uint32) (*repository.Review, error) {
+	review, err := r.GetReview(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if review.Status != repository.ReviewApproved {
+		return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "no review found with id %d", id)
+	}
+
+	if review.UserID == userID {
+		return nil, pkg.Errorf(pkg.FORBIDDEN_ERROR, "you cannot vote on your own review")
+	}
+
+	err = r.db.execTx(ctx, func(q *generated.Queries) error {
+		if err := q.CreateReviewVote(ctx, generated.CreateReviewVoteParams{
+			ReviewID: id,
+			UserID:   userID,
+		}); err != nil {
+			if mysqlErr, ok := err.(*mysql.MySQLError); ok && mysqlErr.Number == 1062 {
+				return pkg.Errorf(pkg.ALREADY_EXISTS_ERROR, "you already voted for this review")
+			}
+
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create vote: %v", err)
+		}
+
+		if err := q.UpdateHelpfulCount(ctx, id); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update helpful count: %v", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetReview(ctx, id)
+}
+
+func (r *ReviewsRepository) RemoveReviewVote(ctx context.Context, id uint32, userID uint32) (*repository.Review, error) {
+	err := r.db.execTx(ctx, func(q *generated.Queries) error {
+		result, err := q.DeleteReviewVote(ctx, generated.DeleteReviewVoteParams{
+			ReviewID: id,
+			UserID:   userID,
+		})
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to delete vote: %v", err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get rows affected: %v", err)
+		}
+
+		if rows == 0 {
+			return pkg.Errorf(pkg.NOT_FOUND_ERROR, "you have not voted for review %d", id)
+		}
+
+		if err := q.UpdateHelpfulCount(ctx, id); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update helpful count: %v", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetReview(ctx, id)
+}
+
+func (r *ReviewsRepository) ReplyToReview(ctx context.Context, id uint32, userID uint32, reply string) (*repository.Review, error) {
+	if reply == "" {
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "reply is required")
+	}
+
+	review, err := r.GetReview(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.execTx(ctx, func(q *generated.Queries) error {
+		if err := q.UpdateReviewReply(ctx, generated.UpdateReviewReplyParams{
+			Reply:     nullString(&reply),
+			RepliedBy: nullUint32(&userID),
+			RepliedAt: sql.NullTime{
+				Valid: true,
+				Time:  time.Now(),
+			},
+			ID: id,
+		}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to reply to review: %v", err)
+		}
+
+		productName, err := q.GetProductName(ctx, review.ProductID)
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get product name: %v", err)
+		}
+
+		if _, err := q.CreateNotification(ctx, generated.CreateNotificationParams{
+			UserID:  review.UserID,
+			Type:    repository.NotificationReviewReplied,
+			Title:   "The shop replied to your review",
+			Message: fmt.Sprintf("We replied to your review of %s: %s", productName, reply),
+		}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create notification: %v", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetReview(ctx, id)
+}
+
+func (r *ReviewsRepository) DeleteReviewReply(ctx context.Context, id uint32) (*repository.Review, error) {
+	review, err := r.GetReview(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if review.Reply == nil {
+		return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "review %d has no reply", id)
+	}
+
+	if err := r.queries.UpdateReviewReply(ctx, generated.UpdateReviewReplyParams{ID: id}); err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to delete reply: %v", err)
+	}
+
+	return r.GetReview(ctx, id)
+}
+
 func (r *ReviewsRepository) DeleteReview(ctx context.Context, id uint32) error {
-	err := r.queries.DeleteReview(ctx, id)
+	return r.deleteReview(ctx, id, nil)
+}
+
+func (r *ReviewsRepository) DeleteUserReview(ctx context.Context, userID uint32, id uint32) error {
+	return r.deleteReview(ctx, id, &userID)
+}
+
+// deleteReview removes the review, when userID is set it must be the author.
+func (r *ReviewsRepository) deleteReview(ctx context.Context, id uint32, userID *uint32) error {
+	return r.db.execTx(ctx, func(q *generated.Queries) error {
+		review, err := getReviewForUpdate(ctx, q, id)
+		if err != nil {
+			return err
+		}
+
+		if userID != nil && review.UserID != *userID {
+			return pkg.Errorf(pkg.FORBIDDEN_ERROR, "review %d belongs to another user", id)
+		}
+
+		if err := q.DeleteReview(ctx, review.ID); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "%v", err)
+		}
+
+		if review.Status == repository.ReviewApproved {
+			if err := adjustReviewSummary(ctx, q, review.ProductID, review.Rating, -1); err != nil {
+				return err
+			}
+		}
+
+		// update the products new rating
+		if err := q.UpdateRating(ctx, review.ProductID); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update rating: %v", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *ReviewsRepository) GetProductReviewSummary(ctx context.Context, productID uint32) (*repository.ReviewSummary, error) {
+	summary, err := r.queries.GetProductReviewSummary(ctx, productID)
+	if err != nil {
+		// products nobody reviewed yet have no row
+		if err == sql.ErrNoRows {
+			return summaryFromRow(generated.ProductReviewSummary{ProductID: productID}), nil
+		}
+
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get review summary: %v", err)
+	}
+
+	return summaryFromRow(summary), nil
+}
+
+func (r *ReviewsRepository) ListProductReviewSummaries(ctx context.Context) ([]*repository.ReviewSummary, error) {
+	summaries, err := r.queries.ListProductReviewSummaries(ctx)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list review summaries: %v", err)
+	}
+
+	result := []*repository.ReviewSummary{}
+	for _, summary := range summaries {
+		result = append(result, summaryFromRow(summary))
+	}
+
+	return result, nil
+}
+
+// getReviewForUpdate locks the review until the transaction ends, so two changes
+// to it cannot both adjust the summary from the same old status.
+func getReviewForUpdate(ctx context.Context, q *generated.Queries, id uint32) (*repository.Review, error) {
+	review, err := q.GetReviewForUpdate(ctx, id)
 	if err != nil {
-		return pkg.Errorf(pkg.INTERNAL_ERROR, "%v", err)
+		if err == sql.ErrNoRows {
+			return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "no review found with id %d", id)
+		}
+
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "%v", err)
+	}
+
+	return reviewFromRow(review), nil
+}
+
+// adjustReviewSummary adds (delta 1) or removes (delta -1) one approved rating
+// from the product summary. Call it in the same transaction as the review change.
+func adjustReviewSummary(ctx context.Context, q *generated.Queries, productID uint32, rating uint32, delta int32) error {
+	arg := generated.AdjustReviewSummaryParams{
+		ProductID:   productID,
+		ReviewCount: delta,
+		RatingTotal: delta * int32(rating),
+	}
+
+	switch rating {
+	case 1:
+		arg.Star1 = delta
+	case 2:
+		arg.Star2 = delta
+	case 3:
+		arg.Star3 = delta
+	case 4:
+		arg.Star4 = delta
+	case 5:
+		arg.Star5 = delta
+	}
+
+	if rating >= 4 {
+		arg.RecommendCount = delta
+	}
+
+	if err := q.AdjustReviewSummary(ctx, arg); err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update review summary: %v", err)
 	}
 
 	return nil
 }
+
+func summaryFromRow(summary generated.ProductReviewSummary) *repository.ReviewSummary {
+	result := &repository.ReviewSummary{
+		ProductID:   summary.ProductID,
+		ReviewCount: uint32(summary.ReviewCount),
+		RatingTotal: uint32(summary.RatingTotal),
+		Distribution: map[uint32]uint32{
+			1: uint32(summary.Star1),
+			2: uint32(summary.Star2),
+			3: uint32(summary.Star3),
+			4: uint32(summary.Star4),
+			5: uint32(summary.Star5),
+		},
+	}
+
+	if summary.ReviewCount > 0 {
+		result.Average = float64(summary.RatingTotal) / float64(summary.ReviewCount)
+		result.RecommendPercent = float64(summary.RecommendCount) * 100 / float64(summary.ReviewCount)
+	}
+
+	return result
+}
+
+func reviewFromRow(review generated.Review) *repository.Review {
+	return &repository.Review{
+		ID:               review.ID,
+		ProductID:        review.ProductID,
+		UserID:           review.UserID,
+		Rating:           review.Rating,
+		Review:           review.Review,
+		ImgUrls:          review.ImgUrls,
+		HelpfulCount:     review.HelpfulCount,
+		VerifiedPurchase: review.VerifiedPurchase,
+		Status:           review.Status,
+		ModerationReason: review.ModerationReason,
+		ModeratedBy:      uint32Ptr(review.ModeratedBy),
+		ModeratedAt:      timePtr(review.ModeratedAt),
+		Reply:            stringPtr(review.Reply),
+		RepliedBy:        uint32Ptr(review.RepliedBy),
+		RepliedAt:        timePtr(review.RepliedAt),
+		UpdatedAt:        review.UpdatedAt,
+		CreatedAt:        review.CreatedAt,
+	}
+}
+
+func stringPtr(v sql.NullString) *string {
+	if !v.Valid {
+		return nil
+	}
+
+	return &v.String
+}
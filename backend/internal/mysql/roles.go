@@ -0,0 +1,190 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/mysql/generated"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+	"github.com/go-sql-driver/mysql"
+)
+
+var _ repository.RoleRepository = (*RoleRepository)(nil)
+
+type RoleRepository struct {
+	db      *Store
+	queries generated.Querier
+}
+
+func NewRoleRepository(db *Store) *RoleRepository {
+	q := generated.New(db.db)
+
+	return &RoleRepository{
+		db:      db,
+		queries: q,
+	}
+}
+
+func (r *RoleRepository) CreateRole(ctx context.Context, role *repository.Role) (*repository.Role, error) {
+	if err := role.Validate(); err != nil {
+		return nil, pkg.Errorf(pkg.INVALID_ERROR, "%v", err)
+	}
+
+	err := r.db.execTx(ctx, func(q *generated.Queries) error {
+		if err := q.CreateRole(ctx, generated.CreateRoleParams{
+			Name:        role.Name,
+			Description: role.Description,
+		}); err != nil {
+			if mysqlErr, ok := err.(*mysql.MySQLError); ok && mysqlErr.Number == 1062 {
+				return pkg.Errorf(pkg.ALREADY_EXISTS_ERROR, "role %s already exists", role.Name)
+			}
+
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create role: %v", err)
+		}
+
+		return setRolePermissions(ctx, q, role.Name, role.Permissions)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetRole(ctx, role.Name)
+}
+
+func (r *RoleRepository) GetRole(ctx context.Context, name string) (*repository.Role, error) {
+	role, err := r.queries.GetRole(ctx, name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "no role found with name %s", name)
+		}
+
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get role: %v", err)
+	}
+
+	permissions, err := r.queries.ListRolePermissions(ctx, role.Name)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get role permissions: %v", err)
+	}
+
+	return &repository.Role{
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: append([]string{}, permissions...),
+		CreatedAt:   role.CreatedAt,
+	}, nil
+}
+
+func (r *RoleRepository) ListRoles(ctx context.Context) ([]*repository.Role, error) {
+	roles, err := r.queries.ListRoles(ctx)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get roles: %v", err)
+	}
+
+	result := []*repository.Role{}
+
+	for _, role := range roles {
+		permissions, err := r.queries.ListRolePermissions(ctx, role.Name)
+		if err != nil {
+			return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get role permissions: %v", err)
+		}
+
+		result = append(result, &repository.Role{
+			Name:        role.Name,
+			Description: role.Description,
+			Permissions: append([]string{}, permissions...),
+			CreatedAt:   role.CreatedAt,
+		})
+	}
+
+	return result, nil
+}
+
+func (r *RoleRepository) DeleteRole(ctx context.Context, name string) error {
+	if name == "ADMIN" || name == "USER" {
+		return pkg.Errorf(pkg.INVALID_ERROR, "the %s role cannot be deleted", name)
+	}
+
+	if _, err := r.GetRole(ctx, name); err != nil {
+		return err
+	}
+
+	if err := r.queries.DeleteRole(ctx, name); err != nil {
+		if mysqlErr, ok := err.(*mysql.MySQLError); ok && mysqlErr.Number == 1451 {
+			return pkg.Errorf(pkg.INVALID_ERROR, "role %s is still assigned to users", name)
+		}
+
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to delete role: %v", err)
+	}
+
+	return nil
+}
+
+func (r *RoleRepository) ListPermissions(ctx context.Context) ([]*repository.Permission, error) {
+	permissions, err := r.queries.ListPermissions(ctx)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get permissions: %v", err)
+	}
+
+	result := []*repository.Permission{}
+
+	for _, permission := range permissions {
+		result = append(result, &repository.Permission{
+			Name:        permission.Name,
+			Description: permission.Description,
+		})
+	}
+
+	return result, nil
+}
+
+func (r *RoleRepository) SetRolePermissions(ctx context.Context, role string, permissions []string) error {
+	if _, err := r.GetRole(ctx, role); err != nil {
+		return err
+	}
+
+	return r.db.execTx(ctx, func(q *generated.Queries) error {
+		if err := q.DeleteRolePermissions(ctx, role); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to clear role permissions: %v", err)
+		}
+
+		return setRolePermissions(ctx, q, role, permissions)
+	})
+}
+
+func (r *RoleRepository) RoleHasPermission(ctx context.Context, role string, permission string) (bool, error) {
+	count, err := r.queries.CheckRolePermission(ctx, generated.CheckRolePermissionParams{
+		Role:       role,
+		Permission: permission,
+	})
+	if err != nil {
+		return false, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to check role permission: %v", err)
+	}
+
+	return count > 0, nil
+}
+
+func setRolePermissions(ctx context.Context, q *generated.Queries, role string, permissions []string) error {
+	seen := map[string]bool{}
+
+	for _, permission := range permissions {
+		if seen[permission] {
+			continue
+		}
+
+		seen[permission] = true
+
+		if err := q.CreateRolePermission(ctx, generated.CreateRolePermissionParams{
+			Role:       role,
+			Permission: permission,
+		}); err != nil {
+			if mysqlErr, ok := err.(*mysql.MySQLError); ok && mysqlErr.Number == 1452 {
+				return pkg.Errorf(pkg.INVALID_ERROR, "unknown permission: %s", permission)
+			}
+
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to add role permission: %v", err)
+		}
+	}
+
+	return nil
+}
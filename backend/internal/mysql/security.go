@@ -0,0 +1,218 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/mysql/generated"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+)
+
+var _ repository.SecurityRepository = (*SecurityRepository)(nil)
+
+type SecurityRepository struct {
+	db      *Store
+	queries generated.Querier
+}
+
+func NewSecurityRepository(db *Store) *SecurityRepository {
+	q := generated.New(db.db)
+
+	return &SecurityRepository{
+		db:      db,
+		queries: q,
+	}
+}
+
+func (s *SecurityRepository) GetLoginAttempt(ctx context.Context, scope string, identifier string) (*repository.LoginAttempt, error) {
+	attempt, err := s.queries.GetLoginAttempt(ctx, generated.GetLoginAttemptParams{
+		Scope:      scope,
+		Identifier: identifier,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "no failed logins for %s %s", scope, identifier)
+		}
+
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get login attempt: %v", err)
+	}
+
+	return loginAttemptFromRow(attempt), nil
+}
+
+func (s *SecurityRepository) RecordFailedLogin(ctx context.Context, scope string, identifier string, policy repository.LoginPolicy) (*repository.LoginAttempt, error) {
+	now := time.Now().UTC()
+
+	var result *repository.LoginAttempt
+
+	// the upsert holds the row lock until commit so concurrent failures are counted one at a time
+	err := s.db.execTx(ctx, func(q *generated.Queries) error {
+		if err := q.RecordLoginFailure(ctx, generated.RecordLoginFailureParams{
+			Scope:       scope,
+			Identifier:  identifier,
+			Now:         now,
+			WindowStart: now.Add(-policy.Window),
+		}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to record login failure: %v", err)
+		}
+
+		attempt, err := q.GetLoginAttempt(ctx, generated.GetLoginAttemptParams{
+			Scope:      scope,
+			Identifier: identifier,
+		})
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get login attempt: %v", err)
+		}
+
+		if lockout := policy.LockoutFor(attempt.FailedAttempts); lockout > 0 {
+			attempt.LockedUntil = sql.NullTime{
+				Valid: true,
+				Time:  now.Add(lockout),
+			}
+
+			if err := q.LockLoginAttempt(ctx, generated.LockLoginAttemptParams{
+				LockedUntil: attempt.LockedUntil,
+				Scope:       scope,
+				Identifier:  identifier,
+			}); err != nil {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to lock login: %v", err)
+			}
+		}
+
+		result = loginAttemptFromRow(attempt)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *SecurityRepository) ClearLoginAttempts(ctx context.Context, scope string, identifier string) error {
+	err := s.queries.DeleteLoginAttempt(ctx, generated.DeleteLoginAttemptParams{
+		Scope:      scope,
+		Identifier: identifier,
+	})
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to clear login attempts: %v", err)
+	}
+
+	return nil
+}
+
+func (s *SecurityRepository) ListLockedLogins(ctx context.Context) ([]*repository.LoginAttempt, error) {
+	attempts, err := s.queries.ListLockedLoginAttempts(ctx, sql.NullTime{
+		Valid: true,
+		Time:  time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list locked logins: %v", err)
+	}
+
+	result := []*repository.LoginAttempt{}
+	for _, attempt := range attempts {
+		result = append(result, loginAttemptFromRow(attempt))
+	}
+
+	return result, nil
+}
+
+func (s *SecurityRepository) CreateSecurityEvent(ctx context.Context, event *repository.SecurityEvent) error {
+	err := s.queries.CreateSecurityEvent(ctx, generated.CreateSecurityEventParams{
+		Event:     event.Event,
+		UserID:    nullUint32(event.UserID),
+		ActorID:   nullUint32(event.ActorID),
+		Email:     event.Email,
+		IpAddress: event.IPAddress,
+		Details:   event.Details,
+	})
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create security event: %v", err)
+	}
+
+	return nil
+}
+
+func (s *SecurityRepository) ListSecurityEvents(ctx context.Context, filter repository.SecurityEventFilter) ([]*repository.SecurityEvent, error) {
+	var req generated.ListSecurityEventsParams
+
+	req.Limit = filter.Limit
+	req.Offset = filter.Offset
+
+	if filter.Event != nil {
+		req.Event = sql.NullString{
+			Valid:  true,
+			String: *filter.Event,
+		}
+	}
+
+	if filter.Email != nil {
+		req.Email = sql.NullString{
+			Valid:  true,
+			String: *filter.Email,
+		}
+	}
+
+	req.UserID = nullUint32(filter.UserID)
+
+	events, err := s.queries.ListSecurityEvents(ctx, req)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list security events: %v", err)
+	}
+
+	result := []*repository.SecurityEvent{}
+	for _, event := range events {
+		result = append(result, &repository.SecurityEvent{
+			ID:        event.ID,
+			Event:     event.Event,
+			UserID:    uint32Ptr(event.UserID),
+			ActorID:   uint32Ptr(event.ActorID),
+			Email:     event.Email,
+			IPAddress: event.IpAddress,
+			Details:   event.Details,
+			CreatedAt: event.CreatedAt,
+		})
+	}
+
+	return result, nil
+}
+
+func loginAttemptFromRow(attempt generated.LoginAttempt) *repository.LoginAttempt {
+	result := &repository.LoginAttempt{
+		Scope:          attempt.Scope,
+		Identifier:     attempt.Identifier,
+		FailedAttempts: attempt.FailedAttempts,
+		LastFailedAt:   attempt.LastFailedAt,
+	}
+
+	if attempt.LockedUntil.Valid {
+		result.LockedUntil = &attempt.LockedUntil.Time
+	}
+
+	return result
+}
+
+func nullUint32(v *uint32) sql.NullInt32 {
+	if v == nil {
+		return sql.NullInt32{}
+	}
+
+	return sql.NullInt32{
+		Valid: true,
+		Int32: int32(*v),
+	}
+}
+
+func uint32Ptr(v sql.NullInt32) *uint32 {
+	if !v.Valid {
+		return nil
+	}
+
+	n := uint32(v.Int32)
+
+	return &n
+}
@@ -0,0 +1,237 @@
+package mysql
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/mysql/generated"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+)
+
+var _ repository.TwoFactorRepository = (*TwoFactorRepository)(nil)
+
+type TwoFactorRepository struct {
+	db      *Store
+	queries generated.Querier
+}
+
+func NewTwoFactorRepository(db *Store) *TwoFactorRepository {
+	q := generated.New(db.db)
+
+	return &TwoFactorRepository{
+		db:      db,
+		queries: q,
+	}
+}
+
+func (t *TwoFactorRepository) GetTwoFactor(ctx context.Context, userID uint32) (*repository.TwoFactor, error) {
+	twoFactor, err := t.queries.GetUserTwoFactor(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, pkg.Errorf(pkg.NOT_FOUND_ERROR, "two factor not set up for user %d", userID)
+		}
+
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get two factor: %v", err)
+	}
+
+	return &repository.TwoFactor{
+		UserID:        twoFactor.UserID,
+		Secret:        twoFactor.Secret,
+		Enabled:       twoFactor.Enabled,
+		RecoveryCodes: twoFactor.RecoveryCodes,
+		LastUsedStep:  twoFactor.LastUsedStep,
+		UpdatedAt:     twoFactor.UpdatedAt,
+		CreatedAt:     twoFactor.CreatedAt,
+	}, nil
+}
+
+func (t *TwoFactorRepository) CreateTwoFactor(ctx context.Context, userID uint32, secret string) error {
+	if secret == "" {
+		return pkg.Errorf(pkg.INVALID_ERROR, "secret is required")
+	}
+
+	current, err := t.GetTwoFactor(ctx, userID)
+	if err != nil && pkg.ErrorCode(err) != pkg.NOT_FOUND_ERROR {
+		return err
+	}
+
+	if current != nil && current.Enabled {
+		return pkg.Errorf(pkg.ALREADY_EXISTS_ERROR, "two factor already enabled")
+	}
+
+	err = t.queries.CreateUserTwoFactor(ctx, generated.CreateUserTwoFactorParams{
+		UserID:        userID,
+		Secret:        secret,
+		RecoveryCodes: json.RawMessage("[]"),
+	})
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create two factor: %v", err)
+	}
+
+	return nil
+}
+
+func (t *TwoFactorRepository) EnableTwoFactor(ctx context.Context, userID uint32, code string, recoveryCodes []string) error {
+	twoFactor, err := t.GetTwoFactor(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if twoFactor.Enabled {
+		return pkg.Errorf(pkg.ALREADY_EXISTS_ERROR, "two factor already enabled")
+	}
+
+	step, ok := pkg.ValidateTOTPCode(twoFactor.Secret, code, time.Now())
+	if !ok {
+		return pkg.Errorf(pkg.AUTHENTICATION_ERROR, "invalid two factor code")
+	}
+
+	hashed, err := hashRecoveryCodes(recoveryCodes)
+	if err != nil {
+		return err
+	}
+
+	err = t.queries.EnableUserTwoFactor(ctx, generated.EnableUserTwoFactorParams{
+		RecoveryCodes: hashed,
+		LastUsedStep:  step,
+		UserID:        userID,
+	})
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to enable two factor: %v", err)
+	}
+
+	return nil
+}
+
+func (t *TwoFactorRepository) VerifyTwoFactorCode(ctx context.Context, userID uint32, code string) error {
+	twoFactor, err := t.GetTwoFactor(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !twoFactor.Enabled {
+		return pkg.Errorf(pkg.INVALID_ERROR, "two factor is not enabled")
+	}
+
+	step, ok := pkg.ValidateTOTPCode(twoFactor.Secret, code, time.Now())
+	if !ok || step <= twoFactor.LastUsedStep {
+		return pkg.Errorf(pkg.AUTHENTICATION_ERROR, "invalid two factor code")
+	}
+
+	// only moves forward, so a code is accepted once even when two logins race with it
+	rows, err := t.queries.UpdateTwoFactorLastUsedStep(ctx, generated.UpdateTwoFactorLastUsedStepParams{
+		LastUsedStep: step,
+		UserID:       userID,
+	})
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update two factor: %v", err)
+	}
+
+	if rows == 0 {
+		return pkg.Errorf(pkg.AUTHENTICATION_ERROR, "invalid two factor code")
+	}
+
+	return nil
+}
+
+// UseRecoveryCode locks the row while the code is removed, so a code can't be
+// spent by two logins at once.
+func (t *TwoFactorRepository) UseRecoveryCode(ctx context.Context, userID uint32, code string) error {
+	return t.db.execTx(ctx, func(q *generated.Queries) error {
+		row, err := q.GetUserTwoFactorForUpdate(ctx, userID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return pkg.Errorf(pkg.NOT_FOUND_ERROR, "two factor not set up for user %d", userID)
+			}
+
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get two factor: %v", err)
+		}
+
+		if !row.Enabled {
+			return pkg.Errorf(pkg.INVALID_ERROR, "two factor is not enabled")
+		}
+
+		twoFactor := repository.TwoFactor{RecoveryCodes: row.RecoveryCodes}
+
+		codes, err := twoFactor.UnmarshalRecoveryCodes()
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "%v", err)
+		}
+
+		hashedCode := pkg.HashRecoveryCode(code)
+		remaining := []string{}
+		found := false
+
+		for _, c := range codes {
+			if !found && subtle.ConstantTimeCompare([]byte(c), []byte(hashedCode)) == 1 {
+				found = true
+
+				continue
+			}
+
+			remaining = append(remaining, c)
+		}
+
+		if !found {
+			return pkg.Errorf(pkg.AUTHENTICATION_ERROR, "invalid recovery code")
+		}
+
+		data, err := json.Marshal(remaining)
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to marshal recovery_codes: %v", err)
+		}
+
+		err = q.UpdateTwoFactorRecoveryCodes(ctx, generated.UpdateTwoFactorRecoveryCodesParams{
+			RecoveryCodes: data,
+			UserID:        userID,
+		})
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update recovery codes: %v", err)
+		}
+
+		return nil
+	})
+}
+
+func (t *TwoFactorRepository) UpdateRecoveryCodes(ctx context.Context, userID uint32, recoveryCodes []string) error {
+	hashed, err := hashRecoveryCodes(recoveryCodes)
+	if err != nil {
+		return err
+	}
+
+	err = t.queries.UpdateTwoFactorRecoveryCodes(ctx, generated.UpdateTwoFactorRecoveryCodesParams{
+		RecoveryCodes: hashed,
+		UserID:        userID,
+	})
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update recovery codes: %v", err)
+	}
+
+	return nil
+}
+
+func (t *TwoFactorRepository) DeleteTwoFactor(ctx context.Context, userID uint32) error {
+	if err := t.queries.DeleteUserTwoFactor(ctx, userID); err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to delete two factor: %v", err)
+	}
+
+	return nil
+}
+
+func hashRecoveryCodes(codes []string) (json.RawMessage, error) {
+	hashed := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hashed = append(hashed, pkg.HashRecoveryCode(code))
+	}
+
+	data, err := json.Marshal(hashed)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to marshal recovery_codes: %v", err)
+	}
+
+	return data, nil
+}
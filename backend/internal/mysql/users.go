@@ -3,6 +3,9 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/mysql/generated"
 	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
@@ -12,6 +15,9 @@ import (
 
 var _ repository.UserRepository = (*UserRepository)(nil)
 
+// shipping_address of the orders of a deleted account
+const redactedShippingAddress = "REDACTED"
+
 type UserRepository struct {
 	db      *Store
 	queries generated.Querier
@@ -27,12 +33,12 @@ func NewUserRepository(db *Store) *UserRepository {
 }
 
 func (u *UserRepository) CreateUser(ctx context.Context, user *repository.User) (*repository.User, error) {
-	accessToken, err := u.db.tokenMaker.CreateToken(user.ID, user.Email, user.Role, u.db.config.TOKEN_DURATION)
+	accessToken, err := u.db.tokenMaker.CreateToken(user.ID, user.Email, user.Role, pkg.TokenTypeAccess, u.db.config.TOKEN_DURATION)
 	if err != nil {
 		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create token: %v", err)
 	}
 
-	refreshToken, err := u.db.tokenMaker.CreateToken(user.ID, user.Email, user.Role, u.db.config.REFRESH_TOKEN_DURATION)
+	refreshToken, err := u.db.tokenMaker.CreateToken(user.ID, user.Email, user.Role, pkg.TokenTypeRefresh, u.db.config.REFRESH_TOKEN_DURATION)
 	if err != nil {
 		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create token: %v", err)
 	}
@@ -48,30 +54,45 @@ func (u *UserRepository) CreateUser(ctx context.Context, user *repository.User)
 		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to hash password: %v", err)
 	}
 
-	result, err := u.queries.CreateUser(ctx, generated.CreateUserParams{
-		Email:        user.Email,
-		Password:     hashPass,
-		Subscription: user.Subscription,
-		Role:         user.Role,
-		RefreshToken: user.RefreshToken,
-	})
-	if err != nil {
-		if mysqlErr, ok := err.(*mysql.MySQLError); ok {
-			if mysqlErr.Number == 1062 {
-				return nil, pkg.Errorf(pkg.ALREADY_EXISTS_ERROR, "duplicate entry for email: %s", user.Email)
+	err = u.db.execTx(ctx, func(q *generated.Queries) error {
+		result, err := q.CreateUser(ctx, generated.CreateUserParams{
+			Email:        user.Email,
+			Password:     hashPass,
+			Subscription: user.Subscription,
+			Role:         user.Role,
+			RefreshToken: user.RefreshToken,
+		})
+		if err != nil {
+			if mysqlErr, ok := err.(*mysql.MySQLError); ok {
+				if mysqlErr.Number == 1062 {
+					return pkg.Errorf(pkg.ALREADY_EXISTS_ERROR, "duplicate entry for email: %s", user.Email)
+				}
 			}
+
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create user: %v", err)
 		}
 
-		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create user: %v", err)
-	}
+		createdId, err := result.LastInsertId()
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get last inserted id: %v", err)
+		}
+
+		user.ID = uint32(createdId)
 
-	createdId, err := result.LastInsertId()
+		// a confirmed newsletter signup carries over to the account
+		subscribed, err := linkNewsletterSubscriber(ctx, q, user.ID, user.Email)
+		if err != nil {
+			return err
+		}
+
+		user.Subscription = user.Subscription || subscribed
+
+		return nil
+	})
 	if err != nil {
-		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get last inserted id: %v", err)
+		return nil, err
 	}
 
-	user.ID = uint32(createdId)
-
 	// change user refresh token to access_token
 	user.RefreshToken = accessToken
 
@@ -88,17 +109,7 @@ func (u *UserRepository) GetUserById(ctx context.Context, id uint32) (*repositor
 		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get user: %v", err)
 	}
 
-	return &repository.User{
-		ID:           user.ID,
-		Email:        user.Email,
-		Password:     user.Password,
-		Subscription: user.Subscription,
-		Role:         user.Role,
-		RefreshToken: user.RefreshToken,
-		UpdatedBy:    uint32(user.UpdatedBy.Int32),
-		UpdatedAt:    user.UpdatedAt,
-		CreatedAt:    user.CreatedAt,
-	}, nil
+	return userFromRow(user), nil
 }
 
 func (u *UserRepository) GetUserByEmail(ctx context.Context, email string) (*repository.User, error) {
@@ -111,17 +122,7 @@ func (u *UserRepository) GetUserByEmail(ctx context.Context, email string) (*rep
 		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get user: %v", err)
 	}
 
-	return &repository.User{
-		ID:           user.ID,
-		Email:        user.Email,
-		Password:     user.Password,
-		Subscription: user.Subscription,
-		Role:         user.Role,
-		RefreshToken: user.RefreshToken,
-		UpdatedBy:    uint32(user.UpdatedBy.Int32),
-		UpdatedAt:    user.UpdatedAt,
-		CreatedAt:    user.CreatedAt,
-	}, nil
+	return userFromRow(user), nil
 }
 
 func (u *UserRepository) GetSubscribedUsers(ctx context.Context) ([]*repository.User, error) {
@@ -133,17 +134,7 @@ func (u *UserRepository) GetSubscribedUsers(ctx context.Context) ([]*repository.
 	var result []*repository.User
 
 	for _, user := range users {
-		result = append(result, &repository.User{
-			ID:           user.ID,
-			Email:        user.Email,
-			Password:     user.Password,
-			Subscription: user.Subscription,
-			Role:         user.Role,
-			RefreshToken: user.RefreshToken,
-			UpdatedBy:    uint32(user.UpdatedBy.Int32),
-			UpdatedAt:    user.UpdatedAt,
-			CreatedAt:    user.CreatedAt,
-		})
+		result = append(result, userFromRow(user))
 	}
 
 	return result, nil
@@ -171,17 +162,50 @@ func (u *UserRepository) ListUsers(ctx context.Context) ([]*repository.User, err
 	var result []*repository.User
 
 	for _, user := range users {
-		result = append(result, &repository.User{
-			ID:           user.ID,
-			Email:        user.Email,
-			Password:     user.Password,
-			Subscription: user.Subscription,
-			Role:         user.Role,
-			RefreshToken: user.RefreshToken,
-			UpdatedBy:    uint32(user.UpdatedBy.Int32),
-			UpdatedAt:    user.UpdatedAt,
-			CreatedAt:    user.CreatedAt,
-		})
+		result = append(result, userFromRow(user))
+	}
+
+	return result, nil
+}
+
+func (u *UserRepository) SearchUsers(ctx context.Context, filter repository.UserFilter) ([]*repository.User, error) {
+	req := generated.SearchUsersParams{
+		Email:  nullString(filter.Email),
+		Role:   nullString(filter.Role),
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}
+
+	if filter.CreatedAfter != nil {
+		req.CreatedAfter = sql.NullTime{
+			Valid: true,
+			Time:  *filter.CreatedAfter,
+		}
+	}
+
+	if filter.CreatedBefore != nil {
+		req.CreatedBefore = sql.NullTime{
+			Valid: true,
+			Time:  *filter.CreatedBefore,
+		}
+	}
+
+	if filter.HasOrders != nil {
+		req.HasOrders = *filter.HasOrders
+	}
+
+	if filter.Disabled != nil {
+		req.Disabled = *filter.Disabled
+	}
+
+	users, err := u.queries.SearchUsers(ctx, req)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to search users: %v", err)
+	}
+
+	result := []*repository.User{}
+	for _, user := range users {
+		result = append(result, userFromRow(user))
 	}
 
 	return result, nil
@@ -198,8 +222,9 @@ func (u *UserRepository) UpdateUserCredentials(ctx context.Context, id uint32, p
 	}
 
 	err = u.queries.UpdateUserCredentials(ctx, generated.UpdateUserCredentialsParams{
-		ID:       id,
-		Password: hashPass,
+		ID:               id,
+		Password:         hashPass,
+		TokensValidAfter: revokeTokens(),
 		UpdatedBy: sql.NullInt32{
 			Valid: true,
 			Int32: int32(id),
@@ -226,9 +251,43 @@ func (u *UserRepository) UpdateUserSubscriptionStatus(ctx context.Context, id ui
 	return err
 }
 
+func (u *UserRepository) UpdateUserProfile(ctx context.Context, profile *repository.UpdateUserProfile) error {
+	params := generated.UpdateUserProfileParams{
+		ID: profile.ID,
+		UpdatedBy: sql.NullInt32{
+			Valid: true,
+			Int32: int32(profile.UpdatedBy),
+		},
+	}
+
+	if profile.FullName != nil {
+		params.FullName = sql.NullString{
+			Valid:  true,
+			String: *profile.FullName,
+		}
+	}
+
+	if profile.PhoneNumber != nil {
+		params.PhoneNumber = sql.NullString{
+			Valid:  true,
+			String: *profile.PhoneNumber,
+		}
+	}
+
+	if err := u.queries.UpdateUserProfile(ctx, params); err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update user profile: %v", err)
+	}
+
+	return nil
+}
+
 func (u *UserRepository) UpdateUserRole(ctx context.Context, adminId uint32, userId uint32, role string) error {
-	if role != "ADMIN" && role != "USER" {
-		return pkg.Errorf(pkg.INVALID_ERROR, "invalid user role")
+	if _, err := u.queries.GetRole(ctx, role); err != nil {
+		if err == sql.ErrNoRows {
+			return pkg.Errorf(pkg.INVALID_ERROR, "invalid user role")
+		}
+
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get role: %v", err)
 	}
 
 	err := u.queries.UpdateUserRole(ctx, generated.UpdateUserRoleParams{
@@ -253,7 +312,7 @@ func (u *UserRepository) UpdateRefreshToken(ctx context.Context, id uint32) (str
 		return "", pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get user: %v", err)
 	}
 
-	refreshToken, err := u.db.tokenMaker.CreateToken(user.ID, user.Email, user.Role, u.db.config.REFRESH_TOKEN_DURATION)
+	refreshToken, err := u.db.tokenMaker.CreateToken(user.ID, user.Email, user.Role, pkg.TokenTypeRefresh, u.db.config.REFRESH_TOKEN_DURATION)
 	if err != nil {
 		return "", pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create token: %v", err)
 	}
@@ -266,8 +325,416 @@ func (u *UserRepository) UpdateRefreshToken(ctx context.Context, id uint32) (str
 	return refreshToken, err
 }
 
+func (u *UserRepository) SetUserDisabled(ctx context.Context, adminId uint32, userId uint32, disabled bool) error {
+	if _, err := u.GetUserById(ctx, userId); err != nil {
+		return err
+	}
+
+	var disabledAt sql.NullTime
+	if disabled {
+		disabledAt = sql.NullTime{
+			Valid: true,
+			Time:  time.Now().UTC(),
+		}
+	}
+
+	err := u.queries.UpdateUserDisabled(ctx, generated.UpdateUserDisabledParams{
+		DisabledAt:       disabledAt,
+		TokensValidAfter: revokeTokens(),
+		UpdatedBy: sql.NullInt32{
+			Valid: true,
+			Int32: int32(adminId),
+		},
+		ID: userId,
+	})
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update user: %v", err)
+	}
+
+	return nil
+}
+
+func (u *UserRepository) RequirePasswordReset(ctx context.Context, token *repository.PasswordToken) error {
+	if _, err := u.GetUserById(ctx, token.UserID); err != nil {
+		return err
+	}
+
+	return u.db.execTx(ctx, func(q *generated.Queries) error {
+		if err := q.RequirePasswordReset(ctx, generated.RequirePasswordResetParams{
+			TokensValidAfter: revokeTokens(),
+			UpdatedBy:        nullUint32(token.CreatedBy),
+			ID:               token.UserID,
+		}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to require password reset: %v", err)
+		}
+
+		return createPasswordToken(ctx, q, token)
+	})
+}
+
+func (u *UserRepository) InviteUser(ctx context.Context, user *repository.User, token *repository.PasswordToken) (*repository.User, error) {
+	// nobody knows the password, the invite token is the only way in
+	password, err := pkg.RandomURLToken(32)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to generate password: %v", err)
+	}
+
+	hashPass, err := pkg.GenerateHashPassword(password, u.db.config.PASSWORD_COST)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to hash password: %v", err)
+	}
+
+	err = u.db.execTx(ctx, func(q *generated.Queries) error {
+		if _, err := q.GetRole(ctx, user.Role); err != nil {
+			if err == sql.ErrNoRows {
+				return pkg.Errorf(pkg.INVALID_ERROR, "invalid user role")
+			}
+
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get role: %v", err)
+		}
+
+		result, err := q.CreateUser(ctx, generated.CreateUserParams{
+			Email:     user.Email,
+			Password:  hashPass,
+			Role:      user.Role,
+			UpdatedBy: nullUint32(token.CreatedBy),
+		})
+		if err != nil {
+			if mysqlErr, ok := err.(*mysql.MySQLError); ok {
+				if mysqlErr.Number == 1062 {
+					return pkg.Errorf(pkg.ALREADY_EXISTS_ERROR, "duplicate entry for email: %s", user.Email)
+				}
+			}
+
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create user: %v", err)
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get last inserted id: %v", err)
+		}
+
+		user.ID = uint32(id)
+		token.UserID = user.ID
+
+		if _, err := linkNewsletterSubscriber(ctx, q, user.ID, user.Email); err != nil {
+			return err
+		}
+
+		if user.FullName != "" {
+			if err := q.UpdateUserProfile(ctx, generated.UpdateUserProfileParams{
+				FullName: sql.NullString{
+					Valid:  true,
+					String: user.FullName,
+				},
+				UpdatedBy: nullUint32(token.CreatedBy),
+				ID:        user.ID,
+			}); err != nil {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update user profile: %v", err)
+			}
+		}
+
+		if err := q.RequirePasswordReset(ctx, generated.RequirePasswordResetParams{
+			TokensValidAfter: revokeTokens(),
+			UpdatedBy:        nullUint32(token.CreatedBy),
+			ID:               user.ID,
+		}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to require password reset: %v", err)
+		}
+
+		return createPasswordToken(ctx, q, token)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return u.GetUserById(ctx, user.ID)
+}
+
+func (u *UserRepository) CreatePasswordToken(ctx context.Context, token *repository.PasswordToken) error {
+	return u.db.execTx(ctx, func(q *generated.Queries) error {
+		return createPasswordToken(ctx, q, token)
+	})
+}
+
+func (u *UserRepository) ResetPassword(ctx context.Context, tokenHash string, password string) (*repository.User, error) {
+	hashPass, err := pkg.GenerateHashPassword(password, u.db.config.PASSWORD_COST)
+	if err != nil {
+		return nil, pkg.Errorf(pkg.INTERNAL_ERROR, "failed to hash password: %v", err)
+	}
+
+	var userID uint32
+
+	// the token row stays locked until commit so a token is only redeemed once
+	err = u.db.execTx(ctx, func(q *generated.Queries) error {
+		token, err := q.GetPasswordToken(ctx, tokenHash)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return pkg.Errorf(pkg.AUTHENTICATION_ERROR, "invalid or expired password token")
+			}
+
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to get password token: %v", err)
+		}
+
+		if time.Now().After(token.ExpiresAt) {
+			return pkg.Errorf(pkg.AUTHENTICATION_ERROR, "invalid or expired password token")
+		}
+
+		userID = token.UserID
+
+		if err := q.UpdateUserCredentials(ctx, generated.UpdateUserCredentialsParams{
+			Password:         hashPass,
+			TokensValidAfter: revokeTokens(),
+			UpdatedBy: sql.NullInt32{
+				Valid: true,
+				Int32: int32(token.UserID),
+			},
+			ID: token.UserID,
+		}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update password: %v", err)
+		}
+
+		if err := q.DeleteUserPasswordTokens(ctx, token.UserID); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to delete password tokens: %v", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return u.GetUserById(ctx, userID)
+}
+
 func (u *UserRepository) DeleteUser(ctx context.Context, id uint32) error {
-	err := u.queries.DeleteUser(ctx, id)
+	user, err := u.GetUserById(ctx, id)
+	if err != nil {
+		return err
+	}
 
-	return err
+	if user.DeletedAt != nil {
+		return pkg.Errorf(pkg.NOT_FOUND_ERROR, "no user found with id %d", id)
+	}
+
+	// nobody knows the new password so the anonymised row can never log in
+	password, err := pkg.RandomURLToken(32)
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to generate password: %v", err)
+	}
+
+	hashPass, err := pkg.GenerateHashPassword(password, u.db.config.PASSWORD_COST)
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to hash password: %v", err)
+	}
+
+	return u.db.execTx(ctx, func(q *generated.Queries) error {
+		openOrders, err := q.CountUserOpenOrders(ctx, id)
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to count open orders: %v", err)
+		}
+
+		if openOrders > 0 {
+			return pkg.Errorf(pkg.INVALID_ERROR, "account has %d orders that are not delivered yet", openOrders)
+		}
+
+		// helpful votes feed helpful_count, so the reviews voted on are recounted
+		votedReviews, err := q.ListUserReviewVotes(ctx, id)
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list review votes: %v", err)
+		}
+
+		if err := q.DeleteUserReviewVotes(ctx, id); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to delete review votes: %v", err)
+		}
+
+		for _, reviewID := range votedReviews {
+			if err := q.UpdateHelpfulCount(ctx, reviewID); err != nil {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update helpful count: %v", err)
+			}
+		}
+
+		reviews, err := q.ListUsersReviews(ctx, id)
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list reviews: %v", err)
+		}
+
+		for _, review := range reviews {
+			if err := q.DeleteReview(ctx, review.ID); err != nil {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to delete review: %v", err)
+			}
+
+			if review.Status == repository.ReviewApproved {
+				if err := adjustReviewSummary(ctx, q, review.ProductID, review.Rating, -1); err != nil {
+					return err
+				}
+			}
+
+			if err := q.UpdateRating(ctx, review.ProductID); err != nil {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to update rating: %v", err)
+			}
+		}
+
+		// comments are emptied like a deletion by the user so replies keep their thread
+		comments, err := q.ListUserComments(ctx, id)
+		if err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to list comments: %v", err)
+		}
+
+		for _, comment := range comments {
+			if err := q.DeleteComment(ctx, comment.BlogComment.ID); err != nil {
+				return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to delete comment: %v", err)
+			}
+
+			if err := adjustCommentCount(ctx, q, comment.BlogComment.BlogID, commentCounted(comment.BlogComment), false); err != nil {
+				return err
+			}
+		}
+
+		if err := q.DeleteUserCart(ctx, id); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to delete cart: %v", err)
+		}
+
+		if err := q.DeleteUserAddresses(ctx, id); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to delete addresses: %v", err)
+		}
+
+		if err := q.DeleteUserIdentities(ctx, id); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to delete identities: %v", err)
+		}
+
+		if err := q.DeleteUserTwoFactor(ctx, id); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to delete two factor: %v", err)
+		}
+
+		if err := q.DeleteUserPasswordTokens(ctx, id); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to delete password tokens: %v", err)
+		}
+
+		if err := q.DeleteUserAPIKeys(ctx, id); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to delete api keys: %v", err)
+		}
+
+		if err := q.DeleteUserSubscriber(ctx, sql.NullInt32{Valid: true, Int32: int32(id)}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to delete newsletter subscriber: %v", err)
+		}
+
+		// deliveries stay for the campaign stats, pending ones are not sent anymore
+		if err := q.RedactUserDeliveries(ctx, sql.NullInt32{Valid: true, Int32: int32(id)}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to redact newsletter deliveries: %v", err)
+		}
+
+		if err := q.DeleteRecipientOutboxEmails(ctx, user.Email); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to delete outbox emails: %v", err)
+		}
+
+		if err := q.DeleteUserNotifications(ctx, id); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to delete notifications: %v", err)
+		}
+
+		if err := q.DeleteNotificationPreferences(ctx, id); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to delete notification preferences: %v", err)
+		}
+
+		// security events stay for the security review but lose the email and ip
+		if err := q.RedactUserSecurityEvents(ctx, generated.RedactUserSecurityEventsParams{
+			UserID: sql.NullInt32{Valid: true, Int32: int32(id)},
+			Email:  strings.ToLower(strings.TrimSpace(user.Email)),
+		}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to redact security events: %v", err)
+		}
+
+		if err := q.DeleteLoginAttempt(ctx, generated.DeleteLoginAttemptParams{
+			Scope:      repository.LoginScopeEmail,
+			Identifier: strings.ToLower(strings.TrimSpace(user.Email)),
+		}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to clear login attempts: %v", err)
+		}
+
+		// orders stay for accounting but no longer say where the user lives
+		if err := q.RedactUserOrders(ctx, generated.RedactUserOrdersParams{
+			ShippingAddress: redactedShippingAddress,
+			UserID:          id,
+		}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to redact orders: %v", err)
+		}
+
+		if err := q.AnonymiseUser(ctx, generated.AnonymiseUserParams{
+			Email:    fmt.Sprintf("deleted-%d@deleted.invalid", id),
+			Password: hashPass,
+			UpdatedBy: sql.NullInt32{
+				Valid: true,
+				Int32: int32(id),
+			},
+			ID: id,
+		}); err != nil {
+			return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to anonymise user: %v", err)
+		}
+
+		return nil
+	})
+}
+
+func createPasswordToken(ctx context.Context, q *generated.Queries, token *repository.PasswordToken) error {
+	err := q.CreatePasswordToken(ctx, generated.CreatePasswordTokenParams{
+		TokenHash: token.TokenHash,
+		UserID:    token.UserID,
+		Purpose:   token.Purpose,
+		ExpiresAt: token.ExpiresAt,
+		CreatedBy: nullUint32(token.CreatedBy),
+	})
+	if err != nil {
+		return pkg.Errorf(pkg.INTERNAL_ERROR, "failed to create password token: %v", err)
+	}
+
+	return nil
+}
+
+func userFromRow(user generated.User) *repository.User {
+	return &repository.User{
+		ID:                    user.ID,
+		Email:                 user.Email,
+		FullName:              user.FullName,
+		PhoneNumber:           user.PhoneNumber,
+		Password:              user.Password,
+		Subscription:          user.Subscription,
+		Role:                  user.Role,
+		RefreshToken:          user.RefreshToken,
+		PasswordResetRequired: user.PasswordResetRequired,
+		TokensValidAfter:      timePtr(user.TokensValidAfter),
+		UpdatedBy:             uint32(user.UpdatedBy.Int32),
+		UpdatedAt:             user.UpdatedAt,
+		CreatedAt:             user.CreatedAt,
+		DisabledAt:            timePtr(user.DisabledAt),
+		DeletedAt:             timePtr(user.DeletedAt),
+	}
+}
+
+// revokeTokens is stored in tokens_valid_after to refuse every token issued
+// until now. Tokens carry their issue time in whole seconds, so it is cut to
+// the second and a token issued in that same second is still accepted.
+func revokeTokens() sql.NullTime {
+	return sql.NullTime{
+		Valid: true,
+		Time:  time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+func timePtr(v sql.NullTime) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+
+	return &v.Time
+}
+
+func nullTime(v *time.Time) sql.NullTime {
+	if v == nil {
+		return sql.NullTime{}
+	}
+
+	return sql.NullTime{
+		Valid: true,
+		Time:  *v,
+	}
 }
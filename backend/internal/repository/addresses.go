@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+)
+
+type Address struct {
+	ID            uint32 `json:"id"`
+	UserID        uint32 `json:"user_id"`
+	RecipientName string `json:"recipient_name"`
+	PhoneNumber   string `json:"phone_number"`
+	County        string `json:"county"`
+	City          string `json:"city"`
+	Street        string `json:"street"`
+	PostalCode    string `json:"postal_code"`
+	IsDefault     bool   `json:"is_default"`
+
+	// Timestamps
+	UpdatedAt time.Time `json:"updated_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (a *Address) Validate() error {
+	if a.UserID <= 0 {
+		return pkg.Errorf(pkg.INVALID_ERROR, "user_id is required")
+	}
+
+	if a.RecipientName == "" {
+		return pkg.Errorf(pkg.INVALID_ERROR, "recipient_name is required")
+	}
+
+	if a.PhoneNumber == "" {
+		return pkg.Errorf(pkg.INVALID_ERROR, "phone_number is required")
+	}
+
+	if a.County == "" {
+		return pkg.Errorf(pkg.INVALID_ERROR, "county is required")
+	}
+
+	if a.City == "" {
+		return pkg.Errorf(pkg.INVALID_ERROR, "city is required")
+	}
+
+	if a.Street == "" {
+		return pkg.Errorf(pkg.INVALID_ERROR, "street is required")
+	}
+
+	return nil
+}
+
+// Format renders the address as the text snapshot stored on an order, so
+// later edits to the address book do not change where an order was sent.
+func (a *Address) Format() string {
+	lines := []string{
+		a.RecipientName,
+		a.PhoneNumber,
+		a.Street,
+	}
+
+	city := a.City
+	if a.PostalCode != "" {
+		city += " " + a.PostalCode
+	}
+
+	lines = append(lines, city, a.County)
+
+	return strings.Join(lines, ", ")
+}
+
+type UpdateAddress struct {
+	ID            uint32  `json:"id"`
+	UserID        uint32  `json:"user_id"`
+	RecipientName *string `json:"recipient_name"`
+	PhoneNumber   *string `json:"phone_number"`
+	County        *string `json:"county"`
+	City          *string `json:"city"`
+	Street        *string `json:"street"`
+	PostalCode    *string `json:"postal_code"`
+}
+
+type AddressRepository interface {
+	// CreateAddress makes the address the default when it is the users first
+	// one or when IsDefault is set.
+	CreateAddress(ctx context.Context, address *Address) (*Address, error)
+	GetUserAddress(ctx context.Context, userID uint32, id uint32) (*Address, error)
+	GetDefaultAddress(ctx context.Context, userID uint32) (*Address, error)
+	ListUserAddresses(ctx context.Context, userID uint32) ([]*Address, error)
+	UpdateAddress(ctx context.Context, address *UpdateAddress) (*Address, error)
+	SetDefaultAddress(ctx context.Context, userID uint32, id uint32) error
+	DeleteAddress(ctx context.Context, userID uint32, id uint32) error
+}
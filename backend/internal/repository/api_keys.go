@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+type APIKey struct {
+	ID         uint32     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  uint32     `json:"created_by"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (k *APIKey) Validate() error {
+	if strings.TrimSpace(k.Name) == "" {
+		return errors.New("name is required")
+	}
+
+	if len(k.Scopes) == 0 {
+		return errors.New("at least one scope is required")
+	}
+
+	if k.ExpiresAt != nil && !k.ExpiresAt.After(time.Now()) {
+		return errors.New("expires_at must be in the future")
+	}
+
+	return nil
+}
+
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key *APIKey) (*APIKey, error)
+	GetAPIKey(ctx context.Context, id uint32) (*APIKey, error)
+	// AuthenticateAPIKey returns a key that is usable right now together with the
+	// user it acts for. Revoked and expired keys, and keys of disabled or deleted
+	// users, are not found.
+	AuthenticateAPIKey(ctx context.Context, keyHash string) (*APIKey, *User, error)
+	ListAPIKeys(ctx context.Context) ([]*APIKey, error)
+	RevokeAPIKey(ctx context.Context, id uint32) error
+	// TouchAPIKey records a use of the key, at most once per interval.
+	TouchAPIKey(ctx context.Context, id uint32, interval time.Duration) error
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// audit actions
+const (
+	AuditCreate = "CREATE"
+	AuditUpdate = "UPDATE"
+	AuditDelete = "DELETE"
+)
+
+// audited entity types
+const (
+	AuditEntityProduct  = "product"
+	AuditEntityCategory = "category"
+	AuditEntityOrder    = "order"
+	AuditEntityPayment  = "payment"
+	AuditEntityUser     = "user"
+	AuditEntityReview   = "review"
+	AuditEntityBlog     = "blog"
+	AuditEntityRole     = "role"
+	AuditEntityAPIKey   = "api_key"
+	AuditEntityComment  = "comment"
+	AuditEntityCampaign = "newsletter_campaign"
+)
+
+// AuditEntry records one administrative change. Before and After only hold
+// the fields that changed, a create has no Before and a delete has no After.
+type AuditEntry struct {
+	ID         uint32          `json:"id"`
+	ActorID    *uint32         `json:"actor_id"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	IPAddress  string          `json:"ip_address"`
+	RequestID  string          `json:"request_id"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type AuditFilter struct {
+	ActorID       *uint32
+	Action        *string
+	EntityType    *string
+	EntityID      *string
+	RequestID     *string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	Limit         int32
+	Offset        int32
+}
+
+type AuditRepository interface {
+	CreateAuditEntry(ctx context.Context, entry *AuditEntry) error
+	ListAuditEntries(ctx context.Context, filter AuditFilter) ([]*AuditEntry, error)
+}
@@ -9,14 +9,42 @@ import (
 	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
 )
 
+// blog statuses
+const (
+	BlogDraft     = "DRAFT"
+	BlogPublished = "PUBLISHED"
+	BlogArchived  = "ARCHIVED"
+)
+
+const (
+	maxBlogTags     = 10
+	maxBlogProducts = 10
+)
+
 type Blog struct {
-	ID      uint32          `json:"id"`
-	Author  uint32          `json:"author"`
-	Title   string          `json:"title"`
-	Content string          `json:"content"`
-	ImgUrls json.RawMessage `json:"img_urls"`
+	ID     uint32 `json:"id"`
+	Author uint32 `json:"author"`
+	Title  string `json:"title"`
+	Slug   string `json:"slug"`
+	// Content is the markdown written by the author, ContentHTML is what readers
+	// are served. It is rendered and sanitized on save so clients never have to.
+	Content        string          `json:"content"`
+	ContentHTML    string          `json:"content_html"`
+	Excerpt        string          `json:"excerpt"`
+	ReadingMinutes uint32          `json:"reading_minutes"`
+	ImgUrls        json.RawMessage `json:"img_urls"`
+	Status         string          `json:"status"`
+	Tags           []string        `json:"tags"`
+	CommentCount   uint32          `json:"comment_count"`
+	// ProductIDs links catalogue products to the post, Products is filled with
+	// their current price and stock when a single blog is read.
+	ProductIDs []uint32   `json:"-"`
+	Products   []*Product `json:"products,omitempty"`
+	// the blog is public once it is PUBLISHED and this time has passed
+	PublishAt *time.Time `json:"publish_at"`
 
 	// Timestamps
+	UpdatedAt time.Time `json:"updated_at"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
@@ -53,14 +81,63 @@ func (p *Blog) Validate() error {
 		return pkg.Errorf(pkg.INVALID_ERROR, "content is required")
 	}
 
+	if !validBlogStatus(p.Status) {
+		return pkg.Errorf(pkg.INVALID_ERROR, "status must be %s, %s or %s", BlogDraft, BlogPublished, BlogArchived)
+	}
+
+	return validBlogLinks(p.Tags, p.ProductIDs)
+}
+
+func validBlogLinks(tags []string, productIDs []uint32) error {
+	if len(tags) > maxBlogTags {
+		return pkg.Errorf(pkg.INVALID_ERROR, "a blog can have at most %d tags", maxBlogTags)
+	}
+
+	if len(productIDs) > maxBlogProducts {
+		return pkg.Errorf(pkg.INVALID_ERROR, "a blog can link at most %d products", maxBlogProducts)
+	}
+
 	return nil
 }
 
+func validBlogStatus(status string) bool {
+	return status == BlogDraft || status == BlogPublished || status == BlogArchived
+}
+
 type UpdateBlog struct {
-	ID      uint32           `json:"id"`
-	Title   *string          `json:"title"`
-	Content *string          `json:"content"`
-	ImgUrls *json.RawMessage `json:"img_urls"`
+	ID        uint32           `json:"id"`
+	Author    uint32           `json:"author"`
+	Title     *string          `json:"title"`
+	Slug      *string          `json:"slug"`
+	Content   *string          `json:"content"`
+	ImgUrls   *json.RawMessage `json:"img_urls"`
+	Status    *string          `json:"status"`
+	PublishAt *time.Time       `json:"publish_at"`
+	// nil leaves the tags or products alone, an empty slice removes them all
+	Tags       *[]string `json:"tags"`
+	ProductIDs *[]uint32 `json:"product_ids"`
+}
+
+func (p *UpdateBlog) Validate() error {
+	if p.Status != nil && !validBlogStatus(*p.Status) {
+		return pkg.Errorf(pkg.INVALID_ERROR, "status must be %s, %s or %s", BlogDraft, BlogPublished, BlogArchived)
+	}
+
+	if p.Slug != nil && *p.Slug == "" {
+		return pkg.Errorf(pkg.INVALID_ERROR, "slug cannot be empty")
+	}
+
+	var tags []string
+	if p.Tags != nil {
+		tags = *p.Tags
+	}
+
+	var productIDs []uint32
+	if p.ProductIDs != nil {
+		productIDs = *p.ProductIDs
+	}
+
+	return validBlogLinks(tags, productIDs)
 }
 
 func (p *UpdateBlog) MarshalOptions(imgUrls []string) error {
@@ -76,11 +153,39 @@ func (p *UpdateBlog) MarshalOptions(imgUrls []string) error {
 	return nil
 }
 
+// BlogFilter narrows the public blog list, Query is a full-text search on the
+// title and content.
+type BlogFilter struct {
+	Tag    *string
+	Query  *string
+	Limit  int32
+	Offset int32
+}
+
+type Tag struct {
+	Name      string `json:"name"`
+	BlogCount int64  `json:"blog_count"`
+}
+
 type BlogRepository interface {
+	// CreateBlog derives the slug from the title when none is given, adding a
+	// number when it is taken. PUBLISHED blogs without PublishAt go live at once.
 	CreateBlog(ctx context.Context, blog *Blog) (*Blog, error)
+	// GetBlog returns the blog whatever its status, use GetPublishedBlog for readers.
+	// Single blogs come with their linked products, lists only with tags.
 	GetBlog(ctx context.Context, id uint32) (*Blog, error)
+	GetPublishedBlog(ctx context.Context, id uint32) (*Blog, error)
+	GetPublishedBlogBySlug(ctx context.Context, slug string) (*Blog, error)
+	// GetBlogsByAuthor and ListBlogs only return published blogs whose publish_at has passed.
 	GetBlogsByAuthor(ctx context.Context, author uint32) ([]*Blog, error)
 	ListBlogs(ctx context.Context) ([]*Blog, error)
+	// SearchBlogs orders by relevance when there is a query, newest first otherwise.
+	SearchBlogs(ctx context.Context, filter BlogFilter) ([]*Blog, error)
+	// ListTags returns the tags used by published blogs, most used first.
+	ListTags(ctx context.Context) ([]*Tag, error)
+	// ListAuthorBlogs is the editor view, drafts and scheduled blogs included.
+	ListAuthorBlogs(ctx context.Context, author uint32, status *string) ([]*Blog, error)
+	// UpdateBlog and DeleteBlog fail with FORBIDDEN_ERROR when author did not write the blog.
 	UpdateBlog(ctx context.Context, blog *UpdateBlog) error
-	DeleteBlog(ctx context.Context, id uint32) error
+	DeleteBlog(ctx context.Context, id uint32, author uint32) error
 }
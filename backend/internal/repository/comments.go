@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// comment statuses
+const (
+	CommentPending  = "PENDING"
+	CommentApproved = "APPROVED"
+	CommentHidden   = "HIDDEN"
+)
+
+type Comment struct {
+	ID         uint32 `json:"id"`
+	BlogID     uint32 `json:"blog_id"`
+	UserID     uint32 `json:"user_id"`
+	AuthorName string `json:"author_name"`
+	// ParentID is set on replies
+	ParentID         *uint32    `json:"parent_id"`
+	Content          string     `json:"content"`
+	Status           string     `json:"status"`
+	ModerationReason string     `json:"moderation_reason"`
+	ModeratedBy      *uint32    `json:"moderated_by"`
+	ModeratedAt      *time.Time `json:"moderated_at"`
+	// deleted comments keep their place in the thread with an empty content
+	DeletedAt *time.Time `json:"deleted_at"`
+
+	// Timestamps
+	UpdatedAt time.Time `json:"updated_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CommentRepository interface {
+	// CreateComment fails with TOO_MANY_REQUESTS_ERROR once the user posted
+	// COMMENT_RATE_LIMIT comments within COMMENT_RATE_WINDOW. Replies must be
+	// to an approved comment on the same blog.
+	CreateComment(ctx context.Context, comment *Comment) (*Comment, error)
+	GetComment(ctx context.Context, id uint32) (*Comment, error)
+	// ListBlogComments returns the approved comments of a published blog oldest
+	// first, deleted ones included so their replies can be placed.
+	ListBlogComments(ctx context.Context, blogID uint32) ([]*Comment, error)
+	ListCommentsByStatus(ctx context.Context, status string, limit int32, offset int32) ([]*Comment, error)
+	ListUserComments(ctx context.Context, userID uint32) ([]*Comment, error)
+	// UpdateComment and DeleteComment fail with FORBIDDEN_ERROR when userID did
+	// not write the comment. Edits go through the content filter again.
+	UpdateComment(ctx context.Context, id uint32, userID uint32, content string) (*Comment, error)
+	DeleteComment(ctx context.Context, id uint32, userID uint32) error
+	ModerateComment(ctx context.Context, id uint32, moderatorID uint32, status string, reason string) (*Comment, error)
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// outbox email statuses
+const (
+	EmailPending = "PENDING"
+	EmailSent    = "SENT"
+	EmailFailed  = "FAILED"
+)
+
+// OutboxEmail is a rendered email waiting to be sent by the outbox worker.
+type OutboxEmail struct {
+	ID       uint32            `json:"id"`
+	To       string            `json:"to"`
+	Subject  string            `json:"subject"`
+	Template string            `json:"template"`
+	Text     string            `json:"text"`
+	HTML     string            `json:"html"`
+	Headers  map[string]string `json:"headers"`
+	Status   string            `json:"status"`
+	Attempts uint32            `json:"attempts"`
+	// LastError is why the last attempt failed, empty when it did not
+	LastError     string     `json:"last_error"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	SentAt        *time.Time `json:"sent_at"`
+
+	// Timestamps
+	UpdatedAt time.Time `json:"updated_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type EmailOutboxRepository interface {
+	// EnqueueEmail stores the email to be sent as soon as the worker polls.
+	EnqueueEmail(ctx context.Context, email *OutboxEmail) error
+	// ListRecipientEmails lists the emails queued or sent to the address, for the data export.
+	ListRecipientEmails(ctx context.Context, recipient string) ([]*OutboxEmail, error)
+
+	// used by the outbox worker
+	// ClaimDueEmails returns up to limit due emails and holds them back from
+	// other workers for lease, so each email is sent by one worker only.
+	ClaimDueEmails(ctx context.Context, limit int32, lease time.Duration) ([]*OutboxEmail, error)
+	// MarkEmailSent also empties the body, it can hold password reset links.
+	MarkEmailSent(ctx context.Context, id uint32) error
+	// MarkEmailFailed retries the email at retryAt until EMAIL_MAX_ATTEMPTS is reached.
+	MarkEmailFailed(ctx context.Context, id uint32, reason string, retryAt time.Time) error
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// campaign statuses
+const (
+	CampaignSending = "SENDING"
+	CampaignSent    = "SENT"
+)
+
+// delivery statuses
+const (
+	DeliveryPending = "PENDING"
+	DeliverySent    = "SENT"
+	DeliveryFailed  = "FAILED"
+	DeliverySkipped = "SKIPPED"
+)
+
+// subscriber statuses
+const (
+	SubscriberPending      = "PENDING"
+	SubscriberConfirmed    = "CONFIRMED"
+	SubscriberUnsubscribed = "UNSUBSCRIBED"
+)
+
+type Campaign struct {
+	ID      uint32 `json:"id"`
+	Subject string `json:"subject"`
+	// Content is markdown, ContentHTML is rendered from it when the campaign is created
+	Content     string `json:"content"`
+	ContentHTML string `json:"content_html"`
+	// BlogID is set on the campaigns sent when a blog is published
+	BlogID         *uint32    `json:"blog_id"`
+	Status         string     `json:"status"`
+	RecipientCount uint32     `json:"recipient_count"`
+	CreatedBy      *uint32    `json:"created_by"`
+	SentAt         *time.Time `json:"sent_at"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Delivery struct {
+	ID         uint32 `json:"id"`
+	CampaignID uint32 `json:"campaign_id"`
+	// UserID is set for registered users, SubscriberID for subscribers without an account
+	UserID       *uint32    `json:"user_id"`
+	SubscriberID *uint32    `json:"subscriber_id"`
+	Email        string     `json:"email"`
+	Status       string     `json:"status"`
+	Attempts     uint32     `json:"attempts"`
+	LastError    string     `json:"last_error"`
+	SentAt       *time.Time `json:"sent_at"`
+	// Subscribed is whether the recipient is still subscribed, only filled for pending deliveries
+	Subscribed bool `json:"-"`
+
+	// Timestamps
+	UpdatedAt time.Time `json:"updated_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Subscriber is someone who signed up for the newsletter with just an email.
+// Once an account is registered with the email the user's subscription is
+// used instead.
+type Subscriber struct {
+	ID             uint32     `json:"id"`
+	Email          string     `json:"email"`
+	Status         string     `json:"status"`
+	UserID         *uint32    `json:"user_id"`
+	ConfirmedAt    *time.Time `json:"confirmed_at"`
+	UnsubscribedAt *time.Time `json:"unsubscribed_at"`
+
+	// Timestamps
+	UpdatedAt time.Time `json:"updated_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CampaignStats struct {
+	CampaignID uint32 `json:"campaign_id"`
+	Total      int64  `json:"total"`
+	Pending    int64  `json:"pending"`
+	Sent       int64  `json:"sent"`
+	Failed     int64  `json:"failed"`
+	Skipped    int64  `json:"skipped"`
+}
+
+type NewsletterRepository interface {
+	// CreateCampaign renders the content and queues a delivery to every
+	// subscribed user and confirmed subscriber. A campaign with a BlogID also marks the blog announced,
+	// each blog is announced once.
+	CreateCampaign(ctx context.Context, campaign *Campaign) (*Campaign, error)
+	GetCampaign(ctx context.Context, id uint32) (*Campaign, error)
+	ListCampaigns(ctx context.Context, limit int32, offset int32) ([]*Campaign, error)
+	GetCampaignStats(ctx context.Context, id uint32) (*CampaignStats, error)
+	// ListCampaignDeliveries lists every delivery when status is empty.
+	ListCampaignDeliveries(ctx context.Context, id uint32, status string, limit int32, offset int32) ([]*Delivery, error)
+	// ListUserDeliveries lists the campaigns sent to a registered user, for the data export.
+	ListUserDeliveries(ctx context.Context, userID uint32) ([]*Delivery, error)
+	// ListUnannouncedBlogs returns the blogs that are public but were not sent to subscribers yet.
+	ListUnannouncedBlogs(ctx context.Context) ([]*Blog, error)
+
+	// Subscribe stores a confirmation token for the email, the subscription
+	// starts once ConfirmSubscriber is called with it. It returns false when
+	// no confirmation email has to be sent, because the email is already
+	// subscribed or a confirmation was sent moments ago.
+	Subscribe(ctx context.Context, email string, tokenHash string, expiresAt time.Time) (bool, error)
+	// ConfirmSubscriber also subscribes the user registered with the email.
+	ConfirmSubscriber(ctx context.Context, tokenHash string) (*Subscriber, error)
+	// UnsubscribeSubscriber also unsubscribes the user registered with the email.
+	UnsubscribeSubscriber(ctx context.Context, id uint32) error
+
+	// used by the newsletter worker
+	// ClaimPendingDeliveries returns up to limit pending deliveries and holds
+	// them back from other workers for lease, so each is sent once only.
+	ClaimPendingDeliveries(ctx context.Context, limit int32, lease time.Duration) ([]*Delivery, error)
+	MarkDeliverySent(ctx context.Context, id uint32) error
+	// MarkDeliveryFailed keeps the delivery pending for another attempt until
+	// NEWSLETTER_MAX_ATTEMPTS is reached.
+	MarkDeliveryFailed(ctx context.Context, id uint32, reason string) error
+	MarkDeliverySkipped(ctx context.Context, id uint32) error
+	// FinishCampaigns marks the campaigns with no pending deliveries left as sent.
+	FinishCampaigns(ctx context.Context) error
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// notification types
+const (
+	NotificationReviewApproved = "REVIEW_APPROVED"
+	NotificationReviewRejected = "REVIEW_REJECTED"
+	NotificationReviewReplied  = "REVIEW_REPLIED"
+)
+
+type Notification struct {
+	ID      uint32     `json:"id"`
+	UserID  uint32     `json:"user_id"`
+	Type    string     `json:"type"`
+	Title   string     `json:"title"`
+	Message string     `json:"message"`
+	ReadAt  *time.Time `json:"read_at"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type NotificationFilter struct {
+	UserID uint32
+	Unread bool
+	Limit  int32
+	Offset int32
+}
+
+// NotificationPreferences are the channels a user is told about their orders on.
+type NotificationPreferences struct {
+	UserID     uint32 `json:"user_id"`
+	OrderEmail bool   `json:"order_email"`
+	OrderSMS   bool   `json:"order_sms"`
+}
+
+type NotificationRepository interface {
+	ListUserNotifications(ctx context.Context, filter NotificationFilter) ([]*Notification, error)
+	// MarkNotificationRead fails with NOT_FOUND_ERROR when the notification is not an unread one of userID.
+	MarkNotificationRead(ctx context.Context, userID uint32, id uint32) error
+	MarkAllNotificationsRead(ctx context.Context, userID uint32) error
+
+	// GetNotificationPreferences returns the defaults, email only, until the user changes them.
+	GetNotificationPreferences(ctx context.Context, userID uint32) (*NotificationPreferences, error)
+	UpdateNotificationPreferences(ctx context.Context, preferences *NotificationPreferences) error
+}
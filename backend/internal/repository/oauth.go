@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+type OAuthState struct {
+	State        string `json:"state"`
+	Provider     string `json:"provider"`
+	CodeVerifier string `json:"-"`
+	Nonce        string `json:"-"`
+
+	// Timestamps
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type UserIdentity struct {
+	Provider string `json:"provider"`
+	Subject  string `json:"subject"`
+	UserID   uint32 `json:"user_id"`
+	Email    string `json:"email"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type OAuthRepository interface {
+	CreateOAuthState(ctx context.Context, state *OAuthState) error
+	// ConsumeOAuthState returns the state and deletes it so a callback can only be used once.
+	ConsumeOAuthState(ctx context.Context, state string) (*OAuthState, error)
+
+	CreateUserIdentity(ctx context.Context, identity *UserIdentity) error
+	GetUserIdentity(ctx context.Context, provider string, subject string) (*UserIdentity, error)
+	ListUserIdentities(ctx context.Context, userID uint32) ([]*UserIdentity, error)
+}
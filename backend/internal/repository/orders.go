@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
@@ -14,6 +15,7 @@ type Order struct {
 	ShippingAmount  float64   `json:"shipping_amount"`
 	Status          string    `json:"status"`
 	ShippingAddress string    `json:"shipping_address"`
+	AddressID       *uint32   `json:"address_id"`
 	UpdatedBy       uint32    `json:"updated_by"`
 	UpdatedAt       time.Time `json:"updated_at"`
 	CreatedAt       time.Time `json:"created_at"`
@@ -86,6 +88,21 @@ func (o *OrderItem) Validate() error {
 	return nil
 }
 
+// Payment is the result of a payment attempt for an order, kept in the transactions table.
+type Payment struct {
+	ID            uint32  `json:"id"`
+	UserID        uint32  `json:"user_id"`
+	OrderID       uint32  `json:"order_id"`
+	PaymentMethod string  `json:"payment_method"`
+	Amount        float64 `json:"amount"`
+	Succeeded     bool    `json:"succeeded"`
+	// PaymentDetails is what the provider returned, as is
+	PaymentDetails    json.RawMessage `json:"payment_details"`
+	ResultDescription string          `json:"result_description"`
+	UpdatedAt         time.Time       `json:"updated_at"`
+	CreatedAt         time.Time       `json:"created_at"`
+}
+
 type OrderRepository interface {
 	// Order CRUD
 	CreateOrder(ctx context.Context, order *Order, orderItems []*OrderItem) (*Order, error)
@@ -96,6 +113,10 @@ type OrderRepository interface {
 	UpdateOrder(ctx context.Context, order *UpdateOrder) error
 	DeleteOrder(ctx context.Context, id uint32) error
 
+	// Payments
+	RecordPayment(ctx context.Context, payment *Payment) (*Payment, error)
+	ListOrderPayments(ctx context.Context, orderID uint32) ([]*Payment, error)
+
 	// OrderItem CRUD
 	CreateOrderItem(ctx context.Context, orderItem *OrderItem) error
 	ListOrderOrderItems(ctx context.Context, orderID uint32) ([]*OrderItem, error)
@@ -2,18 +2,72 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
 )
 
+// review moderation statuses
+const (
+	ReviewPending  = "PENDING"
+	ReviewApproved = "APPROVED"
+	ReviewRejected = "REJECTED"
+)
+
+// product review orderings
+const (
+	ReviewSortNewest  = "newest"
+	ReviewSortHelpful = "helpful"
+)
+
 type Review struct {
-	ID        uint32    `json:"id"`
-	UserID    uint32    `json:"user_id"`
-	ProductID uint32    `json:"product_id"`
-	Rating    uint32    `json:"rating"`
-	Review    string    `json:"review"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        uint32 `json:"id"`
+	UserID    uint32 `json:"user_id"`
+	ProductID uint32 `json:"product_id"`
+	Rating    uint32 `json:"rating"`
+	Review    string `json:"review"`
+	// photos of the item the customer received
+	ImgUrls      json.RawMessage `json:"img_urls"`
+	HelpfulCount uint32          `json:"helpful_count"`
+	// the author had a delivered order with the product when the review was last saved
+	VerifiedPurchase bool `json:"verified_purchase"`
+	// only approved reviews are shown and count towards the product rating
+	Status           string     `json:"status"`
+	ModerationReason string     `json:"moderation_reason"`
+	ModeratedBy      *uint32    `json:"moderated_by"`
+	ModeratedAt      *time.Time `json:"moderated_at"`
+	// public answer from the shop
+	Reply     *string    `json:"reply"`
+	RepliedBy *uint32    `json:"replied_by"`
+	RepliedAt *time.Time `json:"replied_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (r *Review) UnmarshalOptions() ([]string, error) {
+	var imgUrls []string
+	if err := json.Unmarshal(r.ImgUrls, &imgUrls); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal img_urls: %w", err)
+	}
+
+	return imgUrls, nil
+}
+
+func (r *Review) MarshalOptions(imgUrls []string) error {
+	if imgUrls == nil {
+		imgUrls = []string{}
+	}
+
+	imgUrlsData, err := json.Marshal(imgUrls)
+	if err != nil {
+		return fmt.Errorf("failed to marshal img_urls: %w", err)
+	}
+
+	r.ImgUrls = json.RawMessage(imgUrlsData)
+
+	return nil
 }
 
 func (r *Review) Validate() error {
@@ -36,11 +90,58 @@ func (r *Review) Validate() error {
 	return nil
 }
 
+// ReviewSummary is kept up to date as reviews are approved, edited and deleted
+// so product pages do not have to aggregate every review.
+type ReviewSummary struct {
+	ProductID   uint32  `json:"product_id"`
+	ReviewCount uint32  `json:"review_count"`
+	RatingTotal uint32  `json:"-"`
+	Average     float64 `json:"average"`
+	// number of reviews per star, 1 to 5
+	Distribution map[uint32]uint32 `json:"distribution"`
+	// share of reviews rated 4 or 5
+	RecommendPercent float64 `json:"recommend_percent"`
+}
+
+// WeightedRating is the Bayesian average of the product, as if it already had
+// priorWeight reviews at the store wide average. Products with a handful of
+// reviews stay close to the store average until enough customers agree.
+func (s *ReviewSummary) WeightedRating(priorWeight float64, storeAverage float64) float64 {
+	if priorWeight+float64(s.ReviewCount) == 0 {
+		return 0
+	}
+
+	return (priorWeight*storeAverage + float64(s.RatingTotal)) / (priorWeight + float64(s.ReviewCount))
+}
+
 type ReviewRepository interface {
+	// CreateReview allows one review per user and product. When REVIEWS_REQUIRE_PURCHASE
+	// is set only users with a delivered order of the product may review it. New reviews
+	// are PENDING unless REVIEW_FILTER_ENABLED is set and the filter finds nothing wrong.
 	CreateReview(ctx context.Context, review *Review) (*Review, error)
+	// UpdateUserReview fails with FORBIDDEN_ERROR when the review was not written by review.UserID.
+	// An edited review goes through moderation again.
+	UpdateUserReview(ctx context.Context, review *Review) (*Review, error)
 	GetReview(ctx context.Context, id uint32) (*Review, error)
 	ListReviews(ctx context.Context) ([]*Review, error)
 	ListUsersReviews(ctx context.Context, userID uint32) ([]*Review, error)
-	ListProductsReviews(ctx context.Context, productID uint32) ([]*Review, error)
+	// ListProductsReviews orders by ReviewSortNewest or ReviewSortHelpful.
+	ListProductsReviews(ctx context.Context, productID uint32, sort string) ([]*Review, error)
+	// ListReviewsByStatus returns the oldest reviews first so the queue is worked in order.
+	ListReviewsByStatus(ctx context.Context, status string, limit int32, offset int32) ([]*Review, error)
+	// ModerateReview approves or rejects a review, updates the product rating and notifies the author.
+	ModerateReview(ctx context.Context, id uint32, moderatorID uint32, status string, reason string) (*Review, error)
 	DeleteReview(ctx context.Context, id uint32) error
+	// VoteReviewHelpful allows one vote per user and approved review, authors cannot vote on their own reviews.
+	VoteReviewHelpful(ctx context.Context, id uint32, userID uint32) (*Review, error)
+	RemoveReviewVote(ctx context.Context, id uint32, userID uint32) (*Review, error)
+	// ReplyToReview sets or replaces the shop reply and notifies the author.
+	ReplyToReview(ctx context.Context, id uint32, userID uint32, reply string) (*Review, error)
+	DeleteReviewReply(ctx context.Context, id uint32) (*Review, error)
+
+	// GetProductReviewSummary returns an empty summary for products without approved reviews.
+	GetProductReviewSummary(ctx context.Context, productID uint32) (*ReviewSummary, error)
+	ListProductReviewSummaries(ctx context.Context) ([]*ReviewSummary, error)
+	// DeleteUserReview fails with FORBIDDEN_ERROR when the review was not written by userID.
+	DeleteUserReview(ctx context.Context, userID uint32, id uint32) error
 }
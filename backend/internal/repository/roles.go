@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+)
+
+type Role struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (r *Role) Validate() error {
+	if r.Name == "" {
+		return pkg.Errorf(pkg.INVALID_ERROR, "name is required")
+	}
+
+	if r.Name != strings.ToUpper(r.Name) || strings.ContainsAny(r.Name, " \t") {
+		return pkg.Errorf(pkg.INVALID_ERROR, "name must be upper case without spaces")
+	}
+
+	return nil
+}
+
+type Permission struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type RoleRepository interface {
+	CreateRole(ctx context.Context, role *Role) (*Role, error)
+	GetRole(ctx context.Context, name string) (*Role, error)
+	ListRoles(ctx context.Context) ([]*Role, error)
+	DeleteRole(ctx context.Context, name string) error
+
+	ListPermissions(ctx context.Context) ([]*Permission, error)
+	// SetRolePermissions replaces every permission the role holds.
+	SetRolePermissions(ctx context.Context, role string, permissions []string) error
+	RoleHasPermission(ctx context.Context, role string, permission string) (bool, error)
+}
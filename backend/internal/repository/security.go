@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// login attempt scopes
+const (
+	LoginScopeEmail = "EMAIL"
+	LoginScopeIP    = "IP"
+)
+
+// security event names
+const (
+	EventLoginSucceeded = "LOGIN_SUCCEEDED"
+	EventLoginFailed    = "LOGIN_FAILED"
+	EventLoginBlocked   = "LOGIN_BLOCKED"
+	EventAccountLocked  = "ACCOUNT_LOCKED"
+	EventLoginUnlocked  = "LOGIN_UNLOCKED"
+	EventIdentityLinked = "IDENTITY_LINKED"
+	EventAccountDeleted = "ACCOUNT_DELETED"
+	EventUserInvited    = "USER_INVITED"
+	EventUserDisabled   = "USER_DISABLED"
+	EventUserEnabled    = "USER_ENABLED"
+	EventRoleChanged    = "ROLE_CHANGED"
+	EventResetForced    = "PASSWORD_RESET_FORCED"
+	EventResetRequested = "PASSWORD_RESET_REQUESTED"
+	EventPasswordReset  = "PASSWORD_RESET"
+)
+
+type LoginAttempt struct {
+	Scope          string     `json:"scope"`
+	Identifier     string     `json:"identifier"`
+	FailedAttempts uint32     `json:"failed_attempts"`
+	LockedUntil    *time.Time `json:"locked_until"`
+	LastFailedAt   time.Time  `json:"last_failed_at"`
+}
+
+func (a *LoginAttempt) Locked(now time.Time) bool {
+	return a.LockedUntil != nil && a.LockedUntil.After(now)
+}
+
+// LoginPolicy decides when repeated failures lock a scope and for how long.
+// Once MaxAttempts failures happen inside Window the scope is locked for
+// Lockout, doubling with every further failure up to MaxLockout.
+type LoginPolicy struct {
+	MaxAttempts uint32
+	Window      time.Duration
+	Lockout     time.Duration
+	MaxLockout  time.Duration
+}
+
+func (p LoginPolicy) LockoutFor(failedAttempts uint32) time.Duration {
+	if p.MaxAttempts == 0 || failedAttempts < p.MaxAttempts {
+		return 0
+	}
+
+	lockout := p.Lockout
+	for i := p.MaxAttempts; i < failedAttempts && lockout < p.MaxLockout; i++ {
+		lockout *= 2
+	}
+
+	if p.MaxLockout > 0 && lockout > p.MaxLockout {
+		lockout = p.MaxLockout
+	}
+
+	return lockout
+}
+
+type SecurityEvent struct {
+	ID        uint32  `json:"id"`
+	Event     string  `json:"event"`
+	UserID    *uint32 `json:"user_id"`
+	ActorID   *uint32 `json:"actor_id"`
+	Email     string  `json:"email"`
+	IPAddress string  `json:"ip_address"`
+	Details   string  `json:"details"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SecurityEventFilter struct {
+	Event  *string
+	Email  *string
+	UserID *uint32
+	Limit  int32
+	Offset int32
+}
+
+type SecurityRepository interface {
+	GetLoginAttempt(ctx context.Context, scope string, identifier string) (*LoginAttempt, error)
+	// RecordFailedLogin counts a failure against the scope and locks it when the policy says so.
+	RecordFailedLogin(ctx context.Context, scope string, identifier string, policy LoginPolicy) (*LoginAttempt, error)
+	ClearLoginAttempts(ctx context.Context, scope string, identifier string) error
+	ListLockedLogins(ctx context.Context) ([]*LoginAttempt, error)
+
+	CreateSecurityEvent(ctx context.Context, event *SecurityEvent) error
+	ListSecurityEvents(ctx context.Context, filter SecurityEventFilter) ([]*SecurityEvent, error)
+}
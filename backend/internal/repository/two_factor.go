@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type TwoFactor struct {
+	UserID        uint32          `json:"user_id"`
+	Secret        string          `json:"secret"`
+	Enabled       bool            `json:"enabled"`
+	RecoveryCodes json.RawMessage `json:"recovery_codes"` // hashed codes
+	LastUsedStep  uint64          `json:"last_used_step"`
+
+	// Timestamps
+	UpdatedAt time.Time `json:"updated_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (t *TwoFactor) UnmarshalRecoveryCodes() ([]string, error) {
+	var codes []string
+	if err := json.Unmarshal(t.RecoveryCodes, &codes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal recovery_codes: %w", err)
+	}
+
+	return codes, nil
+}
+
+type TwoFactorRepository interface {
+	GetTwoFactor(ctx context.Context, userID uint32) (*TwoFactor, error)
+	// CreateTwoFactor stores a new secret that stays disabled until EnableTwoFactor is called.
+	CreateTwoFactor(ctx context.Context, userID uint32, secret string) error
+	EnableTwoFactor(ctx context.Context, userID uint32, code string, recoveryCodes []string) error
+	// VerifyTwoFactorCode accepts a totp code that has not been used before.
+	VerifyTwoFactorCode(ctx context.Context, userID uint32, code string) error
+	// UseRecoveryCode consumes a single use recovery code.
+	UseRecoveryCode(ctx context.Context, userID uint32, code string) error
+	UpdateRecoveryCodes(ctx context.Context, userID uint32, recoveryCodes []string) error
+	DeleteTwoFactor(ctx context.Context, userID uint32) error
+}
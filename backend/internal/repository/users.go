@@ -10,15 +10,25 @@ import (
 type User struct {
 	ID           uint32 `json:"id"`
 	Email        string `json:"email"`
+	FullName     string `json:"full_name"`
+	PhoneNumber  string `json:"phone_number"`
 	Password     string `json:"password"`
 	Subscription bool   `json:"subscription"`
 	Role         string `json:"role"`
 	RefreshToken string `json:"refresh_token"`
 	UpdatedBy    uint32 `json:"updated_by"`
 
+	// PasswordResetRequired refuses logins until the password is reset.
+	PasswordResetRequired bool `json:"password_reset_required"`
+
+	// TokensValidAfter refuses every token issued before it, see TokenRevoked.
+	TokensValidAfter *time.Time `json:"tokens_valid_after"`
+
 	// Timestamps
-	UpdatedAt time.Time `json:"updated_at"`
-	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	DisabledAt *time.Time `json:"disabled_at"`
+	DeletedAt  *time.Time `json:"deleted_at"`
 }
 
 func (u *User) Validate() error {
@@ -30,8 +40,8 @@ func (u *User) Validate() error {
 		return pkg.Errorf(pkg.INVALID_ERROR, "password is required")
 	}
 
-	if u.Role != "USER" && u.Role != "ADMIN" {
-		return pkg.Errorf(pkg.INVALID_ERROR, "invalid user role")
+	if u.Role == "" {
+		return pkg.Errorf(pkg.INVALID_ERROR, "role is required")
 	}
 
 	if u.RefreshToken == "" {
@@ -41,6 +51,47 @@ func (u *User) Validate() error {
 	return nil
 }
 
+// TokenRevoked reports whether a token issued at issuedAt was revoked by a
+// forced reset, a password reset or disabling the account.
+func (u *User) TokenRevoked(issuedAt time.Time) bool {
+	return u.TokensValidAfter != nil && issuedAt.Before(*u.TokensValidAfter)
+}
+
+type UserFilter struct {
+	Email         *string
+	Role          *string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	HasOrders     *bool
+	Disabled      *bool
+	Limit         int32
+	Offset        int32
+}
+
+// password token purposes
+const (
+	PasswordTokenReset  = "RESET"
+	PasswordTokenInvite = "INVITE"
+)
+
+// PasswordToken lets the holder set a new password once. Only the hash of
+// the token is stored.
+type PasswordToken struct {
+	TokenHash string    `json:"-"`
+	UserID    uint32    `json:"user_id"`
+	Purpose   string    `json:"purpose"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedBy *uint32   `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type UpdateUserProfile struct {
+	ID          uint32  `json:"id"`
+	FullName    *string `json:"full_name"`
+	PhoneNumber *string `json:"phone_number"`
+	UpdatedBy   uint32  `json:"updated_by"`
+}
+
 type UserRepository interface {
 	CreateUser(ctx context.Context, user *User) (*User, error)
 	GetUserById(ctx context.Context, id uint32) (*User, error)
@@ -48,9 +99,23 @@ type UserRepository interface {
 	GetUserEmail(ctx context.Context, id uint32) (string, error)
 	GetSubscribedUsers(ctx context.Context) ([]*User, error)
 	ListUsers(ctx context.Context) ([]*User, error)
+	SearchUsers(ctx context.Context, filter UserFilter) ([]*User, error)
 	UpdateUserCredentials(ctx context.Context, id uint32, password string) error
 	UpdateUserSubscriptionStatus(ctx context.Context, id uint32, status bool) error
+	UpdateUserProfile(ctx context.Context, profile *UpdateUserProfile) error
 	UpdateUserRole(ctx context.Context, adminId uint32, userId uint32, role string) error
 	UpdateRefreshToken(ctx context.Context, id uint32) (string, error)
+	// SetUserDisabled also revokes the refresh token of a disabled user.
+	SetUserDisabled(ctx context.Context, adminId uint32, userId uint32, disabled bool) error
+	// RequirePasswordReset blocks logins until the password is reset with the token.
+	RequirePasswordReset(ctx context.Context, token *PasswordToken) error
+	// InviteUser creates an account that can only be used once the invite token
+	// has been redeemed for a password.
+	InviteUser(ctx context.Context, user *User, token *PasswordToken) (*User, error)
+	// CreatePasswordToken stores a token the user redeems with ResetPassword.
+	CreatePasswordToken(ctx context.Context, token *PasswordToken) error
+	ResetPassword(ctx context.Context, tokenHash string, password string) (*User, error)
+	// DeleteUser anonymises the account and removes its personal data. The row
+	// itself stays because orders and transactions are kept for accounting.
 	DeleteUser(ctx context.Context, id uint32) error
 }
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+)
+
+type Email struct {
+	To      string
+	Subject string
+	// Text is the plain text body, HTML the optional html alternative
+	Text string
+	HTML string
+	// Headers are extra message headers, like List-Unsubscribe
+	Headers map[string]string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, email Email) error
+}
+
+// NewMailer returns the mailer picked by MAILER: log (the default), file or smtp.
+func NewMailer(config pkg.Config) (Mailer, error) {
+	switch strings.ToLower(config.MAILER) {
+	case "", "log":
+		return NewLogMailer(), nil
+	case "file":
+		return NewFileMailer(config.MAIL_DIR, config.MAIL_FROM)
+	case "smtp":
+		return NewSMTPMailer(config.SMTP_HOST, config.SMTP_PORT, config.SMTP_USERNAME, config.SMTP_PASSWORD, config.MAIL_FROM)
+	default:
+		return nil, fmt.Errorf("unknown mailer %q, use log, file or smtp", config.MAILER)
+	}
+}
+
+var _ Mailer = (*LogMailer)(nil)
+
+// LogMailer writes emails to the log instead of sending them, for development.
+type LogMailer struct{}
+
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+func (m *LogMailer) Send(ctx context.Context, email Email) error {
+	log.Printf("email to %s: %s\n%s", email.To, email.Subject, email.Text)
+
+	return nil
+}
+
+var _ Mailer = (*FileMailer)(nil)
+
+// FileMailer writes every email as an .eml file to a directory, for development.
+// The files open in any mail client, html part included.
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+func NewFileMailer(dir string, from string) (*FileMailer, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("MAIL_DIR is required for the file mailer")
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create mail dir: %w", err)
+	}
+
+	return &FileMailer{
+		dir:  dir,
+		from: from,
+	}, nil
+}
+
+func (m *FileMailer) Send(ctx context.Context, email Email) error {
+	msg, err := email.message(m.from, time.Now())
+	if err != nil {
+		return err
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Errorf("failed to name email file: %w", err)
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), hex.EncodeToString(suffix))
+
+	if err := os.WriteFile(filepath.Join(m.dir, name), msg, 0o644); err != nil {
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+
+	return nil
+}
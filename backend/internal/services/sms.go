@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+)
+
+// production endpoint of Africa's Talking, SMS_API_URL overrides it for the sandbox
+const africasTalkingURL = "https://api.africastalking.com/version1/messaging"
+
+type SMSSender interface {
+	SendSMS(ctx context.Context, to string, message string) error
+}
+
+// NewSMSSender returns the provider picked by SMS_PROVIDER: log (the default) or africastalking.
+func NewSMSSender(config pkg.Config) (SMSSender, error) {
+	switch strings.ToLower(config.SMS_PROVIDER) {
+	case "", "log":
+		return NewLogSMSSender(), nil
+	case "africastalking":
+		return NewAfricasTalkingSender(config.SMS_USERNAME, config.SMS_API_KEY, config.SMS_SENDER_ID, config.SMS_API_URL)
+	default:
+		return nil, fmt.Errorf("unknown sms provider %q, use log or africastalking", config.SMS_PROVIDER)
+	}
+}
+
+var _ SMSSender = (*LogSMSSender)(nil)
+
+// LogSMSSender writes messages to the log instead of sending them, for development.
+type LogSMSSender struct{}
+
+func NewLogSMSSender() *LogSMSSender {
+	return &LogSMSSender{}
+}
+
+func (s *LogSMSSender) SendSMS(ctx context.Context, to string, message string) error {
+	log.Printf("sms to %s: %s", to, message)
+
+	return nil
+}
+
+var _ SMSSender = (*AfricasTalkingSender)(nil)
+
+// AfricasTalkingSender sends messages through the Africa's Talking SMS api.
+type AfricasTalkingSender struct {
+	username string
+	apiKey   string
+	senderID string // empty uses the provider's shared short code
+	url      string
+	client   *http.Client
+}
+
+func NewAfricasTalkingSender(username string, apiKey string, senderID string, apiURL string) (*AfricasTalkingSender, error) {
+	if username == "" || apiKey == "" {
+		return nil, fmt.Errorf("SMS_USERNAME and SMS_API_KEY are required for africastalking")
+	}
+
+	if apiURL == "" {
+		apiURL = africasTalkingURL
+	}
+
+	return &AfricasTalkingSender{
+		username: username,
+		apiKey:   apiKey,
+		senderID: senderID,
+		url:      apiURL,
+		client:   &http.Client{Timeout: 15 * time.Second},
+	}, nil
+}
+
+type africasTalkingResponse struct {
+	SMSMessageData struct {
+		Message    string `json:"Message"`
+		Recipients []struct {
+			Number string `json:"number"`
+			Status string `json:"status"`
+		} `json:"Recipients"`
+	} `json:"SMSMessageData"`
+}
+
+func (s *AfricasTalkingSender) SendSMS(ctx context.Context, to string, message string) error {
+	form := url.Values{}
+	form.Set("username", s.username)
+	form.Set("to", to)
+	form.Set("message", message)
+
+	if s.senderID != "" {
+		form.Set("from", s.senderID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create sms request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("apiKey", s.apiKey)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send sms: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("sms provider returned %s", resp.Status)
+	}
+
+	var body africasTalkingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fmt.Errorf("failed to read sms response: %w", err)
+	}
+
+	if len(body.SMSMessageData.Recipients) == 0 {
+		return fmt.Errorf("sms was not sent: %s", body.SMSMessageData.Message)
+	}
+
+	if status := body.SMSMessageData.Recipients[0].Status; status != "Success" {
+		return fmt.Errorf("sms was not sent: %s", status)
+	}
+
+	return nil
+}
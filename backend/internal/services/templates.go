@@ -0,0 +1,135 @@
+package services
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// email templates, each has a <name>.subject and <name>.txt text template and
+// a <name>.html html template
+const (
+	EmailInvitation            = "invitation"
+	EmailOrderConfirmation     = "order_confirmation"
+	EmailOrderDelivered        = "order_delivered"
+	EmailOrderPaid             = "order_paid"
+	EmailOrderProcessing       = "order_processing"
+	EmailOrderShipped          = "order_shipped"
+	EmailPasswordReset         = "password_reset"
+	EmailPasswordResetRequired = "password_reset_required"
+	EmailVerification          = "verification"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+type OrderEmailItem struct {
+	Name     string
+	Quantity uint32
+	Price    float64
+	Color    string
+	Size     string
+}
+
+// OrderEmailData is used by the order confirmation and shipping emails.
+type OrderEmailData struct {
+	OrderID         uint32
+	Items           []OrderEmailItem
+	Amount          float64
+	ShippingAmount  float64
+	ShippingAddress string
+	Link            string
+}
+
+// PasswordResetEmailData is the data of the password reset, password reset
+// required and invitation emails.
+type PasswordResetEmailData struct {
+	Link      string
+	ExpiresIn time.Duration
+}
+
+// VerificationEmailData asks the recipient to prove they own the address,
+// Reason finishes the sentence "Confirm your email address ...".
+type VerificationEmailData struct {
+	Link      string
+	Reason    string
+	ExpiresIn time.Duration
+}
+
+// Templates renders the transactional emails. Text and html are rendered from
+// separate templates so html/template escapes only what ends up in html.
+type Templates struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// NewTemplates parses the embedded templates, site is the shop name shown in every email.
+func NewTemplates(site string) (*Templates, error) {
+	funcs := map[string]any{
+		"site":     func() string { return site },
+		"money":    func(amount float64) string { return fmt.Sprintf("%.2f", amount) },
+		"duration": humanDuration,
+	}
+
+	text, err := texttemplate.New("").Funcs(funcs).ParseFS(templateFS, "templates/*.txt")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse text email templates: %w", err)
+	}
+
+	html, err := htmltemplate.New("").Funcs(funcs).ParseFS(templateFS, "templates/*.html")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse html email templates: %w", err)
+	}
+
+	return &Templates{
+		text: text,
+		html: html,
+	}, nil
+}
+
+func (t *Templates) Render(to string, name string, data any) (Email, error) {
+	var subject, text, html bytes.Buffer
+
+	if err := t.text.ExecuteTemplate(&subject, name+".subject", data); err != nil {
+		return Email{}, fmt.Errorf("failed to render %s subject: %w", name, err)
+	}
+
+	if err := t.text.ExecuteTemplate(&text, name+".txt", data); err != nil {
+		return Email{}, fmt.Errorf("failed to render %s text: %w", name, err)
+	}
+
+	if err := t.html.ExecuteTemplate(&html, name+".html", data); err != nil {
+		return Email{}, fmt.Errorf("failed to render %s html: %w", name, err)
+	}
+
+	return Email{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+		HTML:    html.String(),
+	}, nil
+}
+
+// humanDuration writes link lifetimes the way people say them, 48h is "2 days".
+func humanDuration(d time.Duration) string {
+	plural := func(n int64, unit string) string {
+		if n == 1 {
+			return "1 " + unit
+		}
+
+		return fmt.Sprintf("%d %ss", n, unit)
+	}
+
+	switch {
+	case d >= 24*time.Hour && d%(24*time.Hour) == 0:
+		return plural(int64(d/(24*time.Hour)), "day")
+	case d >= time.Hour && d%time.Hour == 0:
+		return plural(int64(d/time.Hour), "hour")
+	default:
+		return plural(int64(d.Round(time.Minute)/time.Minute), "minute")
+	}
+}
@@ -0,0 +1,154 @@
+package workers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/services"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+)
+
+// used when the EMAIL_OUTBOX_* settings are not set
+const (
+	defaultOutboxBatchSize    = 50
+	defaultOutboxPollInterval = 10 * time.Second
+	defaultOutboxRetryBackoff = time.Minute
+)
+
+// longest wait between two attempts, however many attempts failed
+const maxOutboxRetryBackoff = 6 * time.Hour
+
+// how long a claimed batch is held back from other workers. Emails not sent
+// once half of it is gone are left for the next claim, so a slow send cannot
+// outlast the lease.
+const outboxClaimLease = 10 * time.Minute
+
+// EmailOutboxWorker sends the emails queued in the outbox, a failed send is
+// retried later with an exponential backoff. Every batch is claimed first, so
+// any number of workers can run side by side.
+type EmailOutboxWorker struct {
+	repo   repository.EmailOutboxRepository
+	mailer services.Mailer
+
+	batchSize int32
+	interval  time.Duration
+	backoff   time.Duration
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewEmailOutboxWorker(repo repository.EmailOutboxRepository, mailer services.Mailer, config pkg.Config) *EmailOutboxWorker {
+	w := &EmailOutboxWorker{
+		repo:      repo,
+		mailer:    mailer,
+		batchSize: int32(config.EMAIL_OUTBOX_BATCH_SIZE),
+		interval:  config.EMAIL_OUTBOX_POLL_INTERVAL,
+		backoff:   config.EMAIL_RETRY_BACKOFF,
+	}
+
+	if w.batchSize <= 0 {
+		w.batchSize = defaultOutboxBatchSize
+	}
+
+	if w.interval <= 0 {
+		w.interval = defaultOutboxPollInterval
+	}
+
+	if w.backoff <= 0 {
+		w.backoff = defaultOutboxRetryBackoff
+	}
+
+	return w
+}
+
+func (w *EmailOutboxWorker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	w.cancel = cancel
+	w.done = make(chan struct{})
+
+	go w.run(ctx)
+}
+
+// Stop waits for the email being sent, the rest of the batch is sent once its claim runs out.
+func (w *EmailOutboxWorker) Stop() {
+	log.Println("Shutting down email outbox worker...")
+
+	if w.cancel == nil {
+		return
+	}
+
+	w.cancel()
+	<-w.done
+}
+
+func (w *EmailOutboxWorker) run(ctx context.Context) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.sendBatch(ctx); err != nil {
+			log.Printf("failed to send outbox emails: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *EmailOutboxWorker) sendBatch(ctx context.Context) error {
+	claimedAt := time.Now()
+
+	emails, err := w.repo.ClaimDueEmails(ctx, w.batchSize, outboxClaimLease)
+	if err != nil {
+		return err
+	}
+
+	for _, email := range emails {
+		if ctx.Err() != nil || time.Since(claimedAt) > outboxClaimLease/2 {
+			return nil
+		}
+
+		err := w.mailer.Send(ctx, services.Email{
+			To:      email.To,
+			Subject: email.Subject,
+			Text:    email.Text,
+			HTML:    email.HTML,
+			Headers: email.Headers,
+		})
+		if err != nil {
+			log.Printf("failed to send %s email %d: %v", email.Template, email.ID, err)
+
+			if err := w.repo.MarkEmailFailed(ctx, email.ID, err.Error(), time.Now().Add(w.retryBackoff(email.Attempts))); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err := w.repo.MarkEmailSent(ctx, email.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// retryBackoff doubles the wait after every failed attempt.
+func (w *EmailOutboxWorker) retryBackoff(attempts uint32) time.Duration {
+	backoff := w.backoff
+
+	for i := uint32(0); i < attempts && backoff < maxOutboxRetryBackoff; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, maxOutboxRetryBackoff)
+}
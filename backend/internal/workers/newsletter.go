@@ -0,0 +1,208 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/repository"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/internal/services"
+	"github.com/EmilioCliff/crocheted-ecommerce/backend/pkg"
+)
+
+// used when NEWSLETTER_BATCH_SIZE or NEWSLETTER_POLL_INTERVAL are not set
+const (
+	defaultNewsletterBatchSize    = 50
+	defaultNewsletterPollInterval = time.Minute
+)
+
+// how long a claimed batch of deliveries is held back, see outboxClaimLease
+const newsletterClaimLease = 10 * time.Minute
+
+// NewsletterWorker queues a campaign for every newly published blog and sends
+// pending deliveries in batches. Deliveries are claimed before they are sent,
+// so each replica can run its own worker.
+type NewsletterWorker struct {
+	repo   repository.NewsletterRepository
+	mailer services.Mailer
+	config pkg.Config
+
+	batchSize int32
+	interval  time.Duration
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewNewsletterWorker(repo repository.NewsletterRepository, mailer services.Mailer, config pkg.Config) *NewsletterWorker {
+	w := &NewsletterWorker{
+		repo:      repo,
+		mailer:    mailer,
+		config:    config,
+		batchSize: int32(config.NEWSLETTER_BATCH_SIZE),
+		interval:  config.NEWSLETTER_POLL_INTERVAL,
+	}
+
+	if w.batchSize <= 0 {
+		w.batchSize = defaultNewsletterBatchSize
+	}
+
+	if w.interval <= 0 {
+		w.interval = defaultNewsletterPollInterval
+	}
+
+	return w
+}
+
+func (w *NewsletterWorker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	w.cancel = cancel
+	w.done = make(chan struct{})
+
+	go w.run(ctx)
+}
+
+// Stop waits for the email being sent, the rest of the batch is sent once its claim runs out.
+func (w *NewsletterWorker) Stop() {
+	log.Println("Shutting down newsletter worker...")
+
+	if w.cancel == nil {
+		return
+	}
+
+	w.cancel()
+	<-w.done
+}
+
+func (w *NewsletterWorker) run(ctx context.Context) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.process(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *NewsletterWorker) process(ctx context.Context) {
+	if err := w.announceBlogs(ctx); err != nil {
+		log.Printf("failed to announce blogs: %v", err)
+	}
+
+	if err := w.sendBatch(ctx); err != nil {
+		log.Printf("failed to send newsletter batch: %v", err)
+	}
+
+	if err := w.repo.FinishCampaigns(ctx); err != nil {
+		log.Printf("failed to finish campaigns: %v", err)
+	}
+}
+
+// announceBlogs queues a campaign for each blog that went public since the
+// last poll, scheduled blogs are announced once their publish time passes.
+func (w *NewsletterWorker) announceBlogs(ctx context.Context) error {
+	blogs, err := w.repo.ListUnannouncedBlogs(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, blog := range blogs {
+		link := pkg.JoinURL(w.config.PUBLIC_BASE_URL, "/blogs/"+url.PathEscape(blog.Slug))
+
+		_, err := w.repo.CreateCampaign(ctx, &repository.Campaign{
+			Subject: blog.Title,
+			Content: fmt.Sprintf("%s\n\n[Read the full post](%s)", blog.Excerpt, link),
+			BlogID:  &blog.ID,
+		})
+		if err != nil && pkg.ErrorCode(err) != pkg.ALREADY_EXISTS_ERROR {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *NewsletterWorker) sendBatch(ctx context.Context) error {
+	claimedAt := time.Now()
+
+	deliveries, err := w.repo.ClaimPendingDeliveries(ctx, w.batchSize, newsletterClaimLease)
+	if err != nil {
+		return err
+	}
+
+	campaigns := make(map[uint32]*repository.Campaign)
+
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil || time.Since(claimedAt) > newsletterClaimLease/2 {
+			return nil
+		}
+
+		if !delivery.Subscribed {
+			if err := w.repo.MarkDeliverySkipped(ctx, delivery.ID); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		campaign, ok := campaigns[delivery.CampaignID]
+		if !ok {
+			campaign, err = w.repo.GetCampaign(ctx, delivery.CampaignID)
+			if err != nil {
+				return err
+			}
+
+			campaigns[delivery.CampaignID] = campaign
+		}
+
+		if err := w.mailer.Send(ctx, w.newsletterEmail(campaign, delivery)); err != nil {
+			if err := w.repo.MarkDeliveryFailed(ctx, delivery.ID, err.Error()); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err := w.repo.MarkDeliverySent(ctx, delivery.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// newsletterEmail adds the recipient's own unsubscribe link to the campaign,
+// in the footer and as a one click List-Unsubscribe header (RFC 8058).
+func (w *NewsletterWorker) newsletterEmail(campaign *repository.Campaign, delivery *repository.Delivery) services.Email {
+	var token string
+	if delivery.UserID != nil {
+		token = pkg.UnsubscribeToken(w.config.NEWSLETTER_SIGNING_KEY, pkg.UnsubscribeUser, *delivery.UserID)
+	} else {
+		token = pkg.UnsubscribeToken(w.config.NEWSLETTER_SIGNING_KEY, pkg.UnsubscribeSubscriber, *delivery.SubscriberID)
+	}
+
+	unsubscribe := pkg.JoinURL(w.config.PUBLIC_BASE_URL, "/api/v1/newsletter/unsubscribe") + "?token=" + url.QueryEscape(token)
+
+	return services.Email{
+		To:      delivery.Email,
+		Subject: campaign.Subject,
+		Text:    fmt.Sprintf("%s\n\n--\nUnsubscribe: %s\n", campaign.Content, unsubscribe),
+		HTML: fmt.Sprintf(`%s<hr><p><a href="%s">Unsubscribe</a> from the %s newsletter.</p>`,
+			pkg.AbsoluteHTML(campaign.ContentHTML, w.config.PUBLIC_BASE_URL), html.EscapeString(unsubscribe), html.EscapeString(w.config.FEED_TITLE)),
+		Headers: map[string]string{
+			"List-Unsubscribe":      "<" + unsubscribe + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+	}
+}
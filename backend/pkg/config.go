@@ -1,11 +1,16 @@
 package pkg
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/spf13/viper"
 )
 
+// shortest NEWSLETTER_SIGNING_KEY accepted, whoever knows or guesses the key
+// can forge the unsubscribe link of any user or subscriber
+const minSigningKeyLength = 32
+
 type Config struct {
 	HTTP_PORT               string        `mapstructure:"HTTP_PORT"`
 	MYSQL_USER              string        `mapstructure:"MYSQL_USER"`
@@ -15,9 +20,77 @@ type Config struct {
 	MIGRATION_PATH          string        `mapstructure:"MIGRATION_PATH"`
 	TOKEN_DURATION          time.Duration `mapstructure:"TOKEN_DURATION"`
 	PASSWORD_RESET_DURATION time.Duration `mapstructure:"PASSWORD_RESET_DURATION"`
+	INVITE_DURATION         time.Duration `mapstructure:"INVITE_DURATION"`
 	REFRESH_TOKEN_DURATION  time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
 	TOKEN_SYMMETRY_KEY      string        `mapstructure:"TOKEN_SYMMETRY_KEY"`
 	PASSWORD_COST           int           `mapstructure:"PASSWORD_COST"`
+
+	TOKEN_FORMAT                 string `mapstructure:"TOKEN_FORMAT"`
+	TOKEN_PREVIOUS_SYMMETRY_KEYS string `mapstructure:"TOKEN_PREVIOUS_SYMMETRY_KEYS"`
+	TOKEN_KEYS_DIR               string `mapstructure:"TOKEN_KEYS_DIR"`
+	TOKEN_ACTIVE_KEY_ID          string `mapstructure:"TOKEN_ACTIVE_KEY_ID"`
+	TOKEN_ISSUER                 string `mapstructure:"TOKEN_ISSUER"`
+
+	TWO_FACTOR_ISSUER             string        `mapstructure:"TWO_FACTOR_ISSUER"`
+	TWO_FACTOR_CHALLENGE_DURATION time.Duration `mapstructure:"TWO_FACTOR_CHALLENGE_DURATION"`
+	REQUIRE_ADMIN_TWO_FACTOR      bool          `mapstructure:"REQUIRE_ADMIN_TWO_FACTOR"`
+
+	LOGIN_MAX_ATTEMPTS         uint32        `mapstructure:"LOGIN_MAX_ATTEMPTS"`
+	LOGIN_MAX_IP_ATTEMPTS      uint32        `mapstructure:"LOGIN_MAX_IP_ATTEMPTS"`
+	LOGIN_ATTEMPT_WINDOW       time.Duration `mapstructure:"LOGIN_ATTEMPT_WINDOW"`
+	LOGIN_LOCKOUT_DURATION     time.Duration `mapstructure:"LOGIN_LOCKOUT_DURATION"`
+	LOGIN_MAX_LOCKOUT_DURATION time.Duration `mapstructure:"LOGIN_MAX_LOCKOUT_DURATION"`
+	TRUSTED_PROXIES            string        `mapstructure:"TRUSTED_PROXIES"`
+
+	OIDC_PROVIDER       string        `mapstructure:"OIDC_PROVIDER"`
+	OIDC_ISSUER         string        `mapstructure:"OIDC_ISSUER"`
+	OIDC_AUTH_URL       string        `mapstructure:"OIDC_AUTH_URL"`
+	OIDC_TOKEN_URL      string        `mapstructure:"OIDC_TOKEN_URL"`
+	OIDC_JWKS_URL       string        `mapstructure:"OIDC_JWKS_URL"`
+	OIDC_CLIENT_ID      string        `mapstructure:"OIDC_CLIENT_ID"`
+	OIDC_CLIENT_SECRET  string        `mapstructure:"OIDC_CLIENT_SECRET"`
+	OIDC_REDIRECT_URL   string        `mapstructure:"OIDC_REDIRECT_URL"`
+	OIDC_SCOPES         string        `mapstructure:"OIDC_SCOPES"`
+	OIDC_STATE_DURATION time.Duration `mapstructure:"OIDC_STATE_DURATION"`
+
+	REVIEWS_REQUIRE_PURCHASE bool    `mapstructure:"REVIEWS_REQUIRE_PURCHASE"`
+	REVIEW_FILTER_ENABLED    bool    `mapstructure:"REVIEW_FILTER_ENABLED"`
+	REVIEW_BLOCKED_WORDS     string  `mapstructure:"REVIEW_BLOCKED_WORDS"`
+	REVIEW_RATING_PRIOR      float64 `mapstructure:"REVIEW_RATING_PRIOR"`
+
+	COMMENT_FILTER_ENABLED bool          `mapstructure:"COMMENT_FILTER_ENABLED"`
+	COMMENT_RATE_LIMIT     uint32        `mapstructure:"COMMENT_RATE_LIMIT"`
+	COMMENT_RATE_WINDOW    time.Duration `mapstructure:"COMMENT_RATE_WINDOW"`
+
+	PUBLIC_BASE_URL string `mapstructure:"PUBLIC_BASE_URL"`
+	FEED_TITLE      string `mapstructure:"FEED_TITLE"`
+
+	NEWSLETTER_SIGNING_KEY      string        `mapstructure:"NEWSLETTER_SIGNING_KEY"`
+	NEWSLETTER_BATCH_SIZE       uint32        `mapstructure:"NEWSLETTER_BATCH_SIZE"`
+	NEWSLETTER_POLL_INTERVAL    time.Duration `mapstructure:"NEWSLETTER_POLL_INTERVAL"`
+	NEWSLETTER_MAX_ATTEMPTS     uint32        `mapstructure:"NEWSLETTER_MAX_ATTEMPTS"`
+	NEWSLETTER_CONFIRM_DURATION time.Duration `mapstructure:"NEWSLETTER_CONFIRM_DURATION"`
+
+	MAILER        string `mapstructure:"MAILER"`
+	MAIL_FROM     string `mapstructure:"MAIL_FROM"`
+	MAIL_DIR      string `mapstructure:"MAIL_DIR"`
+	SMTP_HOST     string `mapstructure:"SMTP_HOST"`
+	SMTP_PORT     string `mapstructure:"SMTP_PORT"`
+	SMTP_USERNAME string `mapstructure:"SMTP_USERNAME"`
+	SMTP_PASSWORD string `mapstructure:"SMTP_PASSWORD"`
+
+	EMAIL_OUTBOX_BATCH_SIZE    uint32        `mapstructure:"EMAIL_OUTBOX_BATCH_SIZE"`
+	EMAIL_OUTBOX_POLL_INTERVAL time.Duration `mapstructure:"EMAIL_OUTBOX_POLL_INTERVAL"`
+	EMAIL_MAX_ATTEMPTS         uint32        `mapstructure:"EMAIL_MAX_ATTEMPTS"`
+	EMAIL_RETRY_BACKOFF        time.Duration `mapstructure:"EMAIL_RETRY_BACKOFF"`
+
+	SMS_PROVIDER  string `mapstructure:"SMS_PROVIDER"`
+	SMS_USERNAME  string `mapstructure:"SMS_USERNAME"`
+	SMS_API_KEY   string `mapstructure:"SMS_API_KEY"`
+	SMS_SENDER_ID string `mapstructure:"SMS_SENDER_ID"`
+	SMS_API_URL   string `mapstructure:"SMS_API_URL"`
+
+	REDIS_URL string `mapstructure:"REDIS_URL"`
 }
 
 // Loads app configuration from .env file.
@@ -33,7 +106,13 @@ func LoadConfig(path string) (Config, error) {
 	}
 
 	var config Config
-	err := viper.Unmarshal(&config)
+	if err := viper.Unmarshal(&config); err != nil {
+		return Config{}, err
+	}
+
+	if len(config.NEWSLETTER_SIGNING_KEY) < minSigningKeyLength {
+		return Config{}, fmt.Errorf("NEWSLETTER_SIGNING_KEY must be at least %d characters", minSigningKeyLength)
+	}
 
-	return config, err
+	return config, nil
 }
@@ -0,0 +1,50 @@
+package pkg
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// reviews with more links than this are treated as spam
+const maxContentLinks = 1
+
+var linkPattern = regexp.MustCompile(`(?i)(https?://|www\.)\S+`)
+
+// ContentFilter flags user written text that contains blocked words or looks
+// like spam. Words are matched case insensitively on whole words.
+type ContentFilter struct {
+	blocked map[string]struct{}
+}
+
+// NewContentFilter builds a filter from a comma separated word list.
+func NewContentFilter(words string) *ContentFilter {
+	f := &ContentFilter{blocked: make(map[string]struct{})}
+
+	for _, word := range SplitList(words) {
+		f.blocked[strings.ToLower(word)] = struct{}{}
+	}
+
+	return f
+}
+
+// Check returns a reason when the text should be held for a moderator and
+// an empty string when it is clean.
+func (f *ContentFilter) Check(text string) string {
+	if links := len(linkPattern.FindAllString(text, -1)); links > maxContentLinks {
+		return fmt.Sprintf("contains %d links", links)
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	for _, word := range words {
+		if _, ok := f.blocked[word]; ok {
+			return fmt.Sprintf("contains blocked word %q", word)
+		}
+	}
+
+	return ""
+}
@@ -7,12 +7,14 @@ import (
 )
 
 const (
-	ALREADY_EXISTS_ERROR  = "already_exists"
-	INTERNAL_ERROR        = "internal"
-	INVALID_ERROR         = "invalid"
-	NOT_FOUND_ERROR       = "not_found"
-	NOT_IMPLEMENTED_ERROR = "not_implemented"
-	AUTHENTICATION_ERROR  = "authentication"
+	ALREADY_EXISTS_ERROR    = "already_exists"
+	INTERNAL_ERROR          = "internal"
+	INVALID_ERROR           = "invalid"
+	NOT_FOUND_ERROR         = "not_found"
+	NOT_IMPLEMENTED_ERROR   = "not_implemented"
+	AUTHENTICATION_ERROR    = "authentication"
+	FORBIDDEN_ERROR         = "forbidden"
+	TOO_MANY_REQUESTS_ERROR = "too_many_requests"
 )
 
 type Error struct {
@@ -70,6 +72,10 @@ func PkgErrorToHttpError(err error) int {
 		return http.StatusNotImplemented
 	case AUTHENTICATION_ERROR:
 		return http.StatusUnauthorized
+	case FORBIDDEN_ERROR:
+		return http.StatusForbidden
+	case TOO_MANY_REQUESTS_ERROR:
+		return http.StatusTooManyRequests
 	default:
 		return http.StatusInternalServerError
 	}
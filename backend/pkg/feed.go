@@ -0,0 +1,238 @@
+package pkg
+
+import (
+	"encoding/xml"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var htmlURLAttrPattern = regexp.MustCompile(`\b(href|src)="([^"]*)"`)
+
+// Feed is a list of entries that can be written as RSS 2.0 or Atom. Links are
+// expected to be absolute already, see AbsoluteURL and AbsoluteHTML.
+type Feed struct {
+	Title       string
+	Description string
+	// Link is the page the feed follows, SelfLink is where the feed itself is served
+	Link     string
+	SelfLink string
+	Items    []FeedItem
+}
+
+type FeedItem struct {
+	Title       string
+	Link        string
+	Author      string
+	Summary     string
+	ContentHTML string
+	Categories  []string
+	Published   time.Time
+	Updated     time.Time
+}
+
+// Updated is the latest update of any item. An empty feed reports the unix
+// epoch so it renders the same bytes every time.
+func (f *Feed) Updated() time.Time {
+	updated := time.Unix(0, 0)
+
+	for _, item := range f.Items {
+		if item.Updated.After(updated) {
+			updated = item.Updated
+		}
+	}
+
+	return updated.UTC()
+}
+
+type rssDocument struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	AtomNS    string     `xml:"xmlns:atom,attr"`
+	ContentNS string     `xml:"xmlns:content,attr"`
+	DCNS      string     `xml:"xmlns:dc,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Self          atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	Creator     string   `xml:"dc:creator,omitempty"`
+	Description string   `xml:"description"`
+	Content     string   `xml:"content:encoded,omitempty"`
+	Categories  []string `xml:"category"`
+	PubDate     string   `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// RSS writes the feed as RSS 2.0 with the full content in content:encoded.
+func (f *Feed) RSS() ([]byte, error) {
+	doc := rssDocument{
+		Version:   "2.0",
+		AtomNS:    "http://www.w3.org/2005/Atom",
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		DCNS:      "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:         f.Title,
+			Link:          f.Link,
+			Description:   f.Description,
+			LastBuildDate: f.Updated().Format(time.RFC1123Z),
+			Self:          atomLink{Href: f.SelfLink, Rel: "self", Type: "application/rss+xml"},
+		},
+	}
+
+	for _, item := range f.Items {
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			GUID:        rssGUID{IsPermaLink: true, Value: item.Link},
+			Creator:     item.Author,
+			Description: item.Summary,
+			Content:     item.ContentHTML,
+			Categories:  item.Categories,
+			PubDate:     item.Published.UTC().Format(time.RFC1123Z),
+		})
+	}
+
+	return marshalFeed(doc)
+}
+
+type atomDocument struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     *atomAuthor    `xml:"author,omitempty"`
+	Summary    atomText       `xml:"summary"`
+	Content    *atomText      `xml:"content,omitempty"`
+	Categories []atomCategory `xml:"category"`
+}
+
+// Atom writes the feed as Atom 1.0. The feed title doubles as the author of
+// entries that do not name one.
+func (f *Feed) Atom() ([]byte, error) {
+	doc := atomDocument{
+		Title:   f.Title,
+		ID:      f.SelfLink,
+		Updated: f.Updated().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: f.Link, Rel: "alternate", Type: "text/html"},
+			{Href: f.SelfLink, Rel: "self", Type: "application/atom+xml"},
+		},
+		Author: atomAuthor{Name: f.Title},
+	}
+
+	for _, item := range f.Items {
+		entry := atomEntry{
+			Title:     item.Title,
+			ID:        item.Link,
+			Link:      atomLink{Href: item.Link, Rel: "alternate", Type: "text/html"},
+			Published: item.Published.UTC().Format(time.RFC3339),
+			Updated:   item.Updated.UTC().Format(time.RFC3339),
+			Summary:   atomText{Type: "text", Value: item.Summary},
+		}
+
+		if item.Author != "" {
+			entry.Author = &atomAuthor{Name: item.Author}
+		}
+
+		if item.ContentHTML != "" {
+			entry.Content = &atomText{Type: "html", Value: item.ContentHTML}
+		}
+
+		for _, category := range item.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: category})
+		}
+
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	return marshalFeed(doc)
+}
+
+func marshalFeed(doc any) ([]byte, error) {
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, Errorf(INTERNAL_ERROR, "failed to write feed: %v", err)
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
+
+// AbsoluteURL resolves ref against base. ref is returned unchanged when either
+// of them cannot be parsed.
+func AbsoluteURL(base string, ref string) string {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+
+	return baseURL.ResolveReference(refURL).String()
+}
+
+// AbsoluteHTML resolves the href and src attributes of HTML written by
+// RenderMarkdown against base, feed readers have no page to resolve them from.
+func AbsoluteHTML(rendered string, base string) string {
+	return htmlURLAttrPattern.ReplaceAllStringFunc(rendered, func(attr string) string {
+		match := htmlURLAttrPattern.FindStringSubmatch(attr)
+		resolved := AbsoluteURL(base, html.UnescapeString(match[2]))
+
+		return match[1] + `="` + html.EscapeString(resolved) + `"`
+	})
+}
+
+// JoinURL appends path to base, a trailing slash on base does not matter.
+func JoinURL(base string, path string) string {
+	return strings.TrimRight(base, "/") + "/" + strings.TrimLeft(path, "/")
+}
@@ -0,0 +1,484 @@
+package pkg
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// words a reader gets through in a minute, used for the reading time
+const wordsPerMinute = 200
+
+var (
+	headingPattern      = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	rulePattern         = regexp.MustCompile(`^(\*\s*){3,}$|^(-\s*){3,}$|^(_\s*){3,}$`)
+	bulletPattern       = regexp.MustCompile(`^\s{0,3}[-*+]\s+(.*)$`)
+	orderedPattern      = regexp.MustCompile(`^\s{0,3}\d{1,9}[.)]\s+(.*)$`)
+	codeLanguage        = regexp.MustCompile(`^[A-Za-z0-9_+-]+$`)
+	htmlTagPattern      = regexp.MustCompile(`<[^>]*>`)
+	markdownPunctuation = "\\`*_{}[]()#+-.!>|~<"
+)
+
+// RenderMarkdown converts markdown to HTML. Nothing from the source is passed
+// through as markup: text is escaped, the only tags are the ones the renderer
+// writes itself and links or images with schemes other than http, https or
+// mailto are dropped, so the result is safe to serve to browsers as is.
+//
+// Supported are headings, paragraphs, emphasis, strong, inline and fenced
+// code, block quotes, flat bullet and numbered lists, rules, links and images.
+func RenderMarkdown(src string) string {
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+
+	var b strings.Builder
+	renderBlocks(&b, lines)
+
+	return strings.TrimSpace(b.String())
+}
+
+// HTMLText returns the visible text of HTML written by RenderMarkdown.
+func HTMLText(rendered string) string {
+	text := htmlTagPattern.ReplaceAllString(rendered, " ")
+
+	return strings.Join(strings.Fields(html.UnescapeString(text)), " ")
+}
+
+// ReadingMinutes estimates how long text takes to read, never less than a minute.
+func ReadingMinutes(text string) uint32 {
+	words := len(strings.Fields(text))
+
+	minutes := (words + wordsPerMinute - 1) / wordsPerMinute
+	if minutes < 1 {
+		minutes = 1
+	}
+
+	return uint32(minutes)
+}
+
+// Excerpt shortens text to at most maxRunes, cutting at a word boundary.
+func Excerpt(text string, maxRunes int) string {
+	if utf8.RuneCountInString(text) <= maxRunes {
+		return text
+	}
+
+	runes := []rune(text)[:maxRunes]
+
+	cut := strings.LastIndexFunc(string(runes), unicode.IsSpace)
+	if cut <= 0 {
+		cut = len(string(runes))
+	}
+
+	return strings.TrimRightFunc(string(runes)[:cut], func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}) + "…"
+}
+
+func renderBlocks(b *strings.Builder, lines []string) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			i++
+
+		case strings.HasPrefix(trimmed, "```"):
+			i = renderFence(b, lines, i)
+
+		case headingPattern.MatchString(trimmed):
+			m := headingPattern.FindStringSubmatch(trimmed)
+			fmt.Fprintf(b, "<h%d>%s</h%d>\n", len(m[1]), renderInline(m[2]), len(m[1]))
+			i++
+
+		case rulePattern.MatchString(trimmed):
+			b.WriteString("<hr>\n")
+			i++
+
+		case strings.HasPrefix(trimmed, ">"):
+			var quoted []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				quoted = append(quoted, strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(lines[i]), ">"), " "))
+			}
+
+			b.WriteString("<blockquote>\n")
+			renderBlocks(b, quoted)
+			b.WriteString("</blockquote>\n")
+
+		case bulletPattern.MatchString(line):
+			i = renderList(b, lines, i, bulletPattern, "ul")
+
+		case orderedPattern.MatchString(line):
+			i = renderList(b, lines, i, orderedPattern, "ol")
+
+		default:
+			i = renderParagraph(b, lines, i)
+		}
+	}
+}
+
+func renderFence(b *strings.Builder, lines []string, start int) int {
+	lang := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(lines[start]), "```"))
+
+	var code []string
+
+	i := start + 1
+	for ; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+		code = append(code, lines[i])
+	}
+
+	if codeLanguage.MatchString(lang) {
+		fmt.Fprintf(b, "<pre><code class=\"language-%s\">", lang)
+	} else {
+		b.WriteString("<pre><code>")
+	}
+
+	b.WriteString(html.EscapeString(strings.Join(code, "\n")))
+	b.WriteString("</code></pre>\n")
+
+	// skip the closing fence, an unclosed fence runs to the end
+	return i + 1
+}
+
+func renderList(b *strings.Builder, lines []string, start int, marker *regexp.Regexp, tag string) int {
+	var items []string
+
+	i := start
+	for ; i < len(lines); i++ {
+		line := lines[i]
+
+		if m := marker.FindStringSubmatch(line); m != nil {
+			items = append(items, m[1])
+
+			continue
+		}
+
+		// indented lines continue the previous item
+		if strings.TrimSpace(line) != "" && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			items[len(items)-1] += "\n" + strings.TrimSpace(line)
+
+			continue
+		}
+
+		break
+	}
+
+	fmt.Fprintf(b, "<%s>\n", tag)
+
+	for _, item := range items {
+		fmt.Fprintf(b, "<li>%s</li>\n", renderInline(item))
+	}
+
+	fmt.Fprintf(b, "</%s>\n", tag)
+
+	return i
+}
+
+func renderParagraph(b *strings.Builder, lines []string, start int) int {
+	var text []string
+
+	i := start
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		if trimmed == "" || (i > start && startsBlock(line)) {
+			break
+		}
+
+		text = append(text, line)
+	}
+
+	b.WriteString("<p>")
+
+	for n, line := range text {
+		// two trailing spaces or a backslash force a line break
+		hardBreak := strings.HasSuffix(line, "  ") || strings.HasSuffix(line, "\\")
+
+		b.WriteString(renderInline(strings.TrimSuffix(strings.TrimSpace(line), "\\")))
+
+		if n < len(text)-1 {
+			if hardBreak {
+				b.WriteString("<br>")
+			}
+
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("</p>\n")
+
+	return i
+}
+
+func startsBlock(line string) bool {
+	trimmed := strings.TrimSpace(line)
+
+	return strings.HasPrefix(trimmed, "```") ||
+		strings.HasPrefix(trimmed, ">") ||
+		headingPattern.MatchString(trimmed) ||
+		rulePattern.MatchString(trimmed) ||
+		bulletPattern.MatchString(line) ||
+		orderedPattern.MatchString(line)
+}
+
+func renderInline(text string) string {
+	var b strings.Builder
+
+	// found on the first [ or delimiter as most text has no links or emphasis
+	var (
+		pairs   map[int]int
+		closers map[string][]int
+	)
+
+	for i := 0; i < len(text); {
+		c := text[i]
+
+		switch {
+		case c == '\\' && i+1 < len(text) && strings.IndexByte(markdownPunctuation, text[i+1]) >= 0:
+			b.WriteString(html.EscapeString(text[i+1 : i+2]))
+			i += 2
+
+			continue
+
+		case c == '`':
+			ticks := countRun(text[i:], '`')
+			if end := strings.Index(text[i+ticks:], text[i:i+ticks]); end >= 0 {
+				code := strings.TrimSpace(text[i+ticks : i+ticks+end])
+				b.WriteString("<code>" + html.EscapeString(code) + "</code>")
+				i += ticks + end + ticks
+
+				continue
+			}
+
+		case c == '!' && strings.HasPrefix(text[i+1:], "["):
+			if pairs == nil {
+				pairs = bracketPairs(text)
+			}
+
+			if label, target, n, ok := parseLink(text, i+1, pairs); ok {
+				if src, safe := safeURL(target, false); safe {
+					fmt.Fprintf(&b, "<img src=\"%s\" alt=\"%s\" loading=\"lazy\">", html.EscapeString(src), html.EscapeString(label))
+				} else {
+					b.WriteString(html.EscapeString(label))
+				}
+
+				i += 1 + n
+
+				continue
+			}
+
+		case c == '[':
+			if pairs == nil {
+				pairs = bracketPairs(text)
+			}
+
+			if label, target, n, ok := parseLink(text, i, pairs); ok {
+				if href, safe := safeURL(target, true); safe {
+					fmt.Fprintf(&b, "<a href=\"%s\" rel=\"nofollow noopener noreferrer\">%s</a>", html.EscapeString(href), renderInline(label))
+				} else {
+					b.WriteString(renderInline(label))
+				}
+
+				i += n
+
+				continue
+			}
+
+		case c == '<':
+			if end := strings.IndexByte(text[i:], '>'); end > 0 {
+				if href, safe := safeURL(text[i+1:i+end], true); safe && strings.Contains(href, ":") {
+					fmt.Fprintf(&b, "<a href=\"%s\" rel=\"nofollow noopener noreferrer\">%s</a>", html.EscapeString(href), html.EscapeString(href))
+					i += end + 1
+
+					continue
+				}
+			}
+
+		case c == '*' || c == '_':
+			if closers == nil {
+				closers = emphasisClosers(text)
+			}
+
+			if out, n, ok := renderEmphasis(text, i, closers); ok {
+				b.WriteString(out)
+				i += n
+
+				continue
+			}
+		}
+
+		_, size := utf8.DecodeRuneInString(text[i:])
+		b.WriteString(html.EscapeString(text[i : i+size]))
+		i += size
+	}
+
+	return b.String()
+}
+
+// renderEmphasis handles *em*, _em_, **strong** and __strong__ starting at i.
+// closers comes from emphasisClosers(text).
+func renderEmphasis(text string, i int, closers map[string][]int) (string, int, bool) {
+	c := text[i]
+
+	// intra word underscores as in snake_case are left alone
+	if c == '_' && i > 0 && isWordByte(text[i-1]) {
+		return "", 0, false
+	}
+
+	delim := string(c)
+	tag := "em"
+
+	if strings.HasPrefix(text[i:], delim+delim) {
+		delim += delim
+		tag = "strong"
+	}
+
+	start := i + len(delim)
+	if start >= len(text) || text[start] == ' ' {
+		return "", 0, false
+	}
+
+	end := closers[delim][start+1]
+	if end < 0 {
+		return "", 0, false
+	}
+
+	inner := text[start:end]
+
+	return "<" + tag + ">" + renderInline(inner) + "</" + tag + ">", end + len(delim) - i, true
+}
+
+// emphasisClosers maps each of *, **, _ and __ to the offset of the first
+// delimiter at or after every offset of text that can close emphasis, or -1.
+// Finding them all in one pass keeps text full of unclosed delimiters linear
+// to render.
+func emphasisClosers(text string) map[string][]int {
+	closers := map[string][]int{}
+
+	for _, delim := range []string{"*", "**", "_", "__"} {
+		next := make([]int, len(text)+1)
+		next[len(text)] = -1
+
+		for end := len(text) - 1; end >= 0; end-- {
+			next[end] = next[end+1]
+
+			if canCloseEmphasis(text, end, delim) {
+				next[end] = end
+			}
+		}
+
+		closers[delim] = next
+	}
+
+	return closers
+}
+
+// canCloseEmphasis reports whether delim at end can close emphasis. A closing
+// delimiter follows text, and underscores must end the word too, so the inner
+// ones of _snake_case_ do not close it.
+func canCloseEmphasis(text string, end int, delim string) bool {
+	if end == 0 || !strings.HasPrefix(text[end:], delim) || text[end-1] == ' ' {
+		return false
+	}
+
+	after := end + len(delim)
+
+	return delim[0] != '_' || after >= len(text) || !isWordByte(text[after])
+}
+
+// parseLink reads [label](target) from the [ at start and returns how many
+// bytes it used. pairs comes from bracketPairs(text).
+func parseLink(text string, start int, pairs map[int]int) (string, string, int, bool) {
+	end, ok := pairs[start]
+	if !ok || end+1 >= len(text) || text[end+1] != '(' {
+		return "", "", 0, false
+	}
+
+	closing, ok := pairs[end+1]
+	if !ok {
+		return "", "", 0, false
+	}
+
+	// a title after the url is accepted but not rendered
+	target := strings.TrimSpace(text[end+2 : closing])
+	if fields := strings.Fields(target); len(fields) > 0 {
+		target = fields[0]
+	}
+
+	return text[start+1 : end], target, closing + 1 - start, true
+}
+
+// bracketPairs maps every [ and ( in text to the ] or ) closing it. Finding
+// them all in one pass keeps text full of unmatched brackets linear to render.
+func bracketPairs(text string) map[int]int {
+	pairs := map[int]int{}
+
+	var brackets, parens []int
+
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '[':
+			brackets = append(brackets, i)
+		case ']':
+			if n := len(brackets); n > 0 {
+				pairs[brackets[n-1]] = i
+				brackets = brackets[:n-1]
+			}
+		case '(':
+			parens = append(parens, i)
+		case ')':
+			if n := len(parens); n > 0 {
+				pairs[parens[n-1]] = i
+				parens = parens[:n-1]
+			}
+		}
+	}
+
+	return pairs
+}
+
+// safeURL only lets through relative urls and http(s) ones, plus mailto for links.
+func safeURL(raw string, link bool) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", false
+	}
+
+	for _, r := range raw {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return "", false
+		}
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https":
+		return raw, true
+	case "mailto":
+		return raw, link
+	default:
+		return "", false
+	}
+}
+
+func countRun(s string, c byte) int {
+	n := 0
+	for n < len(s) && s[n] == c {
+		n++
+	}
+
+	return n
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
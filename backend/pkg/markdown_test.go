@@ -0,0 +1,65 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// pathological inputs, each quadratic to render with a rescan per opener
+var unmatchedMarkdown = map[string]string{
+	"unmatched brackets": strings.Repeat("[a ", 70000),
+	"unmatched images":   strings.Repeat("![a ", 50000),
+	"unclosed links":     strings.Repeat("[a](b ", 35000),
+	"unclosed emphasis":  strings.Repeat("*a ", 70000),
+	"unclosed underline": strings.Repeat("_a ", 70000),
+	"unclosed strong":    strings.Repeat("**a ", 50000),
+}
+
+func TestRenderMarkdownInline(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"*em* and _em_", "<p><em>em</em> and <em>em</em></p>"},
+		{"**strong** and __strong__", "<p><strong>strong</strong> and <strong>strong</strong></p>"},
+		{"a snake_case_name stays", "<p>a snake_case_name stays</p>"},
+		{"_snake_case_", "<p><em>snake_case</em></p>"},
+		{"a * not emphasis *", "<p>a * not emphasis *</p>"},
+		{"2 * 3 and *closed*", "<p>2 * 3 and <em>closed</em></p>"},
+		{"[a [link](/b)", "<p>[a <a href=\"/b\" rel=\"nofollow noopener noreferrer\">link</a></p>"},
+		{"[bad](javascript:alert(1))", "<p>bad</p>"},
+	}
+
+	for _, tt := range tests {
+		if got := RenderMarkdown(tt.src); got != tt.want {
+			t.Errorf("RenderMarkdown(%q) = %q, want %q", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestRenderMarkdownUnmatchedIsLinear(t *testing.T) {
+	for name, src := range unmatchedMarkdown {
+		t.Run(name, func(t *testing.T) {
+			start := time.Now()
+			RenderMarkdown(src)
+
+			// linear rendering takes milliseconds, a rescan per opener minutes
+			if took := time.Since(start); took > time.Second {
+				t.Fatalf("rendering %d bytes took %v", len(src), took)
+			}
+		})
+	}
+}
+
+func BenchmarkRenderMarkdownUnmatched(b *testing.B) {
+	for name, src := range unmatchedMarkdown {
+		b.Run(name, func(b *testing.B) {
+			b.SetBytes(int64(len(src)))
+
+			for i := 0; i < b.N; i++ {
+				RenderMarkdown(src)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package pkg
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 
 	"golang.org/x/crypto/bcrypt"
@@ -18,3 +20,11 @@ func GenerateHashPassword(password string, cost int) (string, error) {
 func ComparePasswordAndHash(hashPass string, password string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hashPass), []byte(password))
 }
+
+// HashToken returns the value stored for a random one time token, like a
+// password reset token. Tokens are random so a fast hash is enough.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
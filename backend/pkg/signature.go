@@ -0,0 +1,78 @@
+package pkg
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// prefix of the values in newsletter unsubscribe tokens
+const unsubscribePrefix = "unsubscribe:"
+
+// who an unsubscribe token was made for
+const (
+	UnsubscribeUser       = "user"
+	UnsubscribeSubscriber = "subscriber"
+)
+
+// SignValue returns value with an HMAC-SHA256 signature as an url safe token.
+// The value is readable by anyone holding the token, only use it for ids.
+func SignValue(key string, value string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(value)) + "." + base64.RawURLEncoding.EncodeToString(signValue(key, value))
+}
+
+// VerifySignedValue returns the value of a token made by SignValue with the same key.
+func VerifySignedValue(key string, token string) (string, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", Errorf(INVALID_ERROR, "invalid token")
+	}
+
+	value, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", Errorf(INVALID_ERROR, "invalid token")
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, signValue(key, string(value))) {
+		return "", Errorf(INVALID_ERROR, "invalid token")
+	}
+
+	return string(value), nil
+}
+
+func signValue(key string, value string) []byte {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(value))
+
+	return mac.Sum(nil)
+}
+
+// UnsubscribeToken is put in newsletter links so a user or subscriber can
+// unsubscribe without signing in. It does not expire, old emails keep working.
+func UnsubscribeToken(key string, kind string, id uint32) string {
+	return SignValue(key, fmt.Sprintf("%s%s:%d", unsubscribePrefix, kind, id))
+}
+
+// ParseUnsubscribeToken returns the kind and id an UnsubscribeToken was made for.
+func ParseUnsubscribeToken(key string, token string) (string, uint32, error) {
+	value, err := VerifySignedValue(key, token)
+	if err != nil {
+		return "", 0, err
+	}
+
+	kind, rawID, ok := strings.Cut(strings.TrimPrefix(value, unsubscribePrefix), ":")
+	if !ok || !strings.HasPrefix(value, unsubscribePrefix) || (kind != UnsubscribeUser && kind != UnsubscribeSubscriber) {
+		return "", 0, Errorf(INVALID_ERROR, "invalid token")
+	}
+
+	id, err := strconv.ParseUint(rawID, 10, 32)
+	if err != nil || id == 0 {
+		return "", 0, Errorf(INVALID_ERROR, "invalid token")
+	}
+
+	return kind, uint32(id), nil
+}
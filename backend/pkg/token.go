@@ -3,6 +3,7 @@ package pkg
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/aead/chacha20poly1305"
@@ -11,19 +12,62 @@ import (
 
 var ErrTokenExpired = errors.New("Token has expired")
 
+// token formats selected with TOKEN_FORMAT
+const (
+	TokenFormatPaseto       = "paseto"
+	TokenFormatPasetoPublic = "paseto-public"
+	TokenFormatJWT          = "jwt"
+)
+
 type Maker interface {
-	CreateToken(userID uint32, email string, role string, duration time.Duration) (string, error)
+	CreateToken(userID uint32, email string, role string, tokenType TokenType, duration time.Duration) (string, error)
 	VerifyToken(token string) (*Payload, error)
 }
 
+// KeyPublisher is implemented by makers that sign with asymmetric keys so
+// other services can verify our tokens.
+type KeyPublisher interface {
+	JWKS() JWKSet
+}
+
+// NewMaker returns the token maker chosen by TOKEN_FORMAT, the symmetric
+// paseto maker when it is not set.
+func NewMaker(config Config) (Maker, error) {
+	switch strings.ToLower(config.TOKEN_FORMAT) {
+	case "", TokenFormatPaseto:
+		return NewPaseto(config.TOKEN_SYMMETRY_KEY, SplitList(config.TOKEN_PREVIOUS_SYMMETRY_KEYS)...)
+	case TokenFormatPasetoPublic:
+		keys, err := LoadKeyRing(config.TOKEN_KEYS_DIR, config.TOKEN_ACTIVE_KEY_ID)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewPasetoPublicMaker(keys)
+	case TokenFormatJWT:
+		keys, err := LoadKeyRing(config.TOKEN_KEYS_DIR, config.TOKEN_ACTIVE_KEY_ID)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewJWTMaker(keys, config.TOKEN_ISSUER), nil
+	default:
+		return nil, fmt.Errorf("unknown TOKEN_FORMAT %q", config.TOKEN_FORMAT)
+	}
+}
+
 var _ Maker = (*PasetoMaker)(nil)
 
 type PasetoMaker struct {
 	paseto       *paseto.V2
 	symmetricKey []byte
+	// keys rotated out that tokens issued before the rotation were encrypted with
+	previousKeys [][]byte
 }
 
-func NewPaseto(symmetricKey string) (*PasetoMaker, error) {
+// NewPaseto creates a v2.local maker. Tokens are always encrypted with
+// symmetricKey, previousKeys are only tried when decrypting so rotating the
+// key does not sign everyone out.
+func NewPaseto(symmetricKey string, previousKeys ...string) (*PasetoMaker, error) {
 	if len(symmetricKey) != chacha20poly1305.KeySize {
 		return nil, fmt.Errorf("SymmetricKey too short should be: %v", chacha20poly1305.KeySize)
 	}
@@ -33,11 +77,19 @@ func NewPaseto(symmetricKey string) (*PasetoMaker, error) {
 		symmetricKey: []byte(symmetricKey),
 	}
 
+	for _, key := range previousKeys {
+		if len(key) != chacha20poly1305.KeySize {
+			return nil, fmt.Errorf("previous SymmetricKey should be: %v", chacha20poly1305.KeySize)
+		}
+
+		maker.previousKeys = append(maker.previousKeys, []byte(key))
+	}
+
 	return maker, nil
 }
 
-func (maker *PasetoMaker) CreateToken(userID uint32, email string, role string, duration time.Duration) (string, error) {
-	payload, err := NewPayload(userID, email, role, duration)
+func (maker *PasetoMaker) CreateToken(userID uint32, email string, role string, tokenType TokenType, duration time.Duration) (string, error) {
+	payload, err := NewPayload(userID, email, role, tokenType, duration)
 	if err != nil {
 		return "", err
 	}
@@ -48,7 +100,16 @@ func (maker *PasetoMaker) CreateToken(userID uint32, email string, role string,
 func (maker *PasetoMaker) VerifyToken(token string) (*Payload, error) {
 	payload := &Payload{}
 
-	if err := maker.paseto.Decrypt(token, maker.symmetricKey, payload, nil); err != nil {
+	err := maker.paseto.Decrypt(token, maker.symmetricKey, payload, nil)
+	for _, key := range maker.previousKeys {
+		if err == nil {
+			break
+		}
+
+		err = maker.paseto.Decrypt(token, key, payload, nil)
+	}
+
+	if err != nil {
 		return nil, err
 	}
 
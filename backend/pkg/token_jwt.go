@@ -0,0 +1,167 @@
+package pkg
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var _ Maker = (*JWTMaker)(nil)
+
+// JWTMaker signs tokens as JWTs with the active key of a KeyRing, EdDSA for
+// Ed25519 keys and RS256 for RSA keys.
+type JWTMaker struct {
+	keys   *KeyRing
+	issuer string
+}
+
+// NewJWTMaker creates a JWT maker. When issuer is set it is written to the iss
+// claim and required when verifying.
+func NewJWTMaker(keys *KeyRing, issuer string) *JWTMaker {
+	return &JWTMaker{
+		keys:   keys,
+		issuer: issuer,
+	}
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+	Kid string `json:"kid"`
+}
+
+type jwtClaims struct {
+	ID        string    `json:"jti"`
+	Issuer    string    `json:"iss,omitempty"`
+	Subject   string    `json:"sub"`
+	IssuedAt  int64     `json:"iat"`
+	ExpiresAt int64     `json:"exp"`
+	UserID    uint32    `json:"user_id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	Type      TokenType `json:"token_type"`
+}
+
+func (maker *JWTMaker) CreateToken(userID uint32, email string, role string, tokenType TokenType, duration time.Duration) (string, error) {
+	payload, err := NewPayload(userID, email, role, tokenType, duration)
+	if err != nil {
+		return "", err
+	}
+
+	key := maker.keys.Active()
+
+	header, err := json.Marshal(jwtHeader{
+		Alg: key.Algorithm,
+		Typ: "JWT",
+		Kid: key.ID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	claims, err := json.Marshal(jwtClaims{
+		ID:        payload.ID.String(),
+		Issuer:    maker.issuer,
+		Subject:   strconv.FormatUint(uint64(userID), 10),
+		IssuedAt:  payload.CreatedAt.Unix(),
+		ExpiresAt: payload.ExpiryAt.Unix(),
+		UserID:    userID,
+		Email:     email,
+		Role:      role,
+		Type:      tokenType,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	signature, err := key.sign([]byte(signingInput))
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func (maker *JWTMaker) VerifyToken(token string) (*Payload, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("token is not a JWT")
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid token header: %w", err)
+	}
+
+	var header jwtHeader
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, fmt.Errorf("invalid token header: %w", err)
+	}
+
+	key, err := maker.keys.Key(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	// the algorithm comes from our key, never from the token
+	if header.Alg != key.Algorithm {
+		return nil, fmt.Errorf("token algorithm %q does not match key %s", header.Alg, key.ID)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid token signature: %w", err)
+	}
+
+	if err := key.verify([]byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, err
+	}
+
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid token claims: %w", err)
+	}
+
+	var claims jwtClaims
+	if err := json.Unmarshal(claimsJSON, &claims); err != nil {
+		return nil, fmt.Errorf("invalid token claims: %w", err)
+	}
+
+	if maker.issuer != "" && claims.Issuer != maker.issuer {
+		return nil, fmt.Errorf("unexpected token issuer %q", claims.Issuer)
+	}
+
+	id, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token id: %w", err)
+	}
+
+	payload := &Payload{
+		ID:        id,
+		UserID:    claims.UserID,
+		Email:     claims.Email,
+		Role:      claims.Role,
+		Type:      claims.Type,
+		CreatedAt: time.Unix(claims.IssuedAt, 0),
+		ExpiryAt:  time.Unix(claims.ExpiresAt, 0),
+	}
+
+	if err := payload.Valid(); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+// JWKS publishes the public keys tokens can be verified with.
+func (maker *JWTMaker) JWKS() JWKSet {
+	return maker.keys.JWKS()
+}
@@ -0,0 +1,259 @@
+package pkg
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// signing algorithms of token keys
+const (
+	AlgEdDSA = "EdDSA"
+	AlgRS256 = "RS256"
+)
+
+const minRSAKeyBits = 2048
+
+// TokenKey is one key of a KeyRing. Keys loaded from a public key can only
+// verify, which is how a retired key is kept until its tokens have expired.
+type TokenKey struct {
+	ID        string
+	Algorithm string
+
+	signer crypto.Signer
+	public crypto.PublicKey
+}
+
+// ParseTokenKey reads a PEM encoded PKCS8 private key or PKIX public key.
+// Ed25519 keys sign with EdDSA and RSA keys with RS256.
+func ParseTokenKey(id string, data []byte) (*TokenKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("token key %s is not PEM encoded", id)
+	}
+
+	key := &TokenKey{ID: id}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid token key %s: %w", id, err)
+		}
+
+		signer, ok := private.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("token key %s cannot sign", id)
+		}
+
+		key.signer = signer
+		key.public = signer.Public()
+	case "PUBLIC KEY":
+		public, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid token key %s: %w", id, err)
+		}
+
+		key.public = public
+	default:
+		return nil, fmt.Errorf("token key %s has unsupported PEM type %q", id, block.Type)
+	}
+
+	switch public := key.public.(type) {
+	case ed25519.PublicKey:
+		key.Algorithm = AlgEdDSA
+	case *rsa.PublicKey:
+		if public.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("token key %s must be at least %d bits", id, minRSAKeyBits)
+		}
+
+		key.Algorithm = AlgRS256
+	default:
+		return nil, fmt.Errorf("token key %s must be an Ed25519 or RSA key", id)
+	}
+
+	return key, nil
+}
+
+func (k *TokenKey) CanSign() bool {
+	return k.signer != nil
+}
+
+func (k *TokenKey) sign(message []byte) ([]byte, error) {
+	if k.signer == nil {
+		return nil, fmt.Errorf("token key %s can only verify", k.ID)
+	}
+
+	switch k.Algorithm {
+	case AlgEdDSA:
+		return k.signer.Sign(rand.Reader, message, crypto.Hash(0))
+	case AlgRS256:
+		digest := sha256.Sum256(message)
+
+		return k.signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+
+	return nil, fmt.Errorf("unsupported token key algorithm %q", k.Algorithm)
+}
+
+func (k *TokenKey) verify(message []byte, signature []byte) error {
+	switch k.Algorithm {
+	case AlgEdDSA:
+		if !ed25519.Verify(k.public.(ed25519.PublicKey), message, signature) {
+			return errors.New("invalid token signature")
+		}
+	case AlgRS256:
+		digest := sha256.Sum256(message)
+
+		if err := rsa.VerifyPKCS1v15(k.public.(*rsa.PublicKey), crypto.SHA256, digest[:], signature); err != nil {
+			return errors.New("invalid token signature")
+		}
+	default:
+		return fmt.Errorf("unsupported token key algorithm %q", k.Algorithm)
+	}
+
+	return nil
+}
+
+// KeyRing holds every key a token may have been signed with. New tokens are
+// signed with the active key and carry its id so verification picks the right
+// key. Rotation is done in steps: add the next key so it is published, make
+// it active, then replace the old private key with its public key and remove
+// it once the longest lived token signed with it has expired.
+type KeyRing struct {
+	active *TokenKey
+	keys   map[string]*TokenKey
+}
+
+func NewKeyRing(activeID string, keys ...*TokenKey) (*KeyRing, error) {
+	ring := &KeyRing{
+		keys: map[string]*TokenKey{},
+	}
+
+	for _, key := range keys {
+		if _, ok := ring.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate token key id %s", key.ID)
+		}
+
+		ring.keys[key.ID] = key
+	}
+
+	active, ok := ring.keys[activeID]
+	if !ok {
+		return nil, fmt.Errorf("active token key %q not found", activeID)
+	}
+
+	if !active.CanSign() {
+		return nil, fmt.Errorf("active token key %s must be a private key", activeID)
+	}
+
+	ring.active = active
+
+	return ring, nil
+}
+
+// LoadKeyRing reads every *.pem file in dir, the file name without the
+// extension is the key id.
+func LoadKeyRing(dir string, activeID string) (*KeyRing, error) {
+	if dir == "" {
+		return nil, errors.New("TOKEN_KEYS_DIR is required for asymmetric tokens")
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list token keys: %w", err)
+	}
+
+	var keys []*TokenKey
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read token key: %w", err)
+		}
+
+		key, err := ParseTokenKey(strings.TrimSuffix(filepath.Base(file), ".pem"), data)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	return NewKeyRing(activeID, keys...)
+}
+
+func (r *KeyRing) Active() *TokenKey {
+	return r.active
+}
+
+func (r *KeyRing) Key(id string) (*TokenKey, error) {
+	key, ok := r.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("no token key with kid %q", id)
+	}
+
+	return key, nil
+}
+
+// JWK is the public part of a token key in RFC 7517 form.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys of the ring, sorted by key id.
+func (r *KeyRing) JWKS() JWKSet {
+	set := JWKSet{
+		Keys: []JWK{},
+	}
+
+	for _, key := range r.keys {
+		jwk := JWK{
+			Kid: key.ID,
+			Use: "sig",
+			Alg: key.Algorithm,
+		}
+
+		switch public := key.public.(type) {
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	sort.Slice(set.Keys, func(i, j int) bool {
+		return set.Keys[i].Kid < set.Keys[j].Kid
+	})
+
+	return set
+}
@@ -0,0 +1,185 @@
+package pkg
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const pasetoV4PublicHeader = "v4.public."
+
+var _ Maker = (*PasetoPublicMaker)(nil)
+
+// PasetoPublicMaker signs v4.public PASETO tokens with the active Ed25519 key
+// of a KeyRing. The key id travels in the footer, which is covered by the
+// signature.
+type PasetoPublicMaker struct {
+	keys *KeyRing
+}
+
+func NewPasetoPublicMaker(keys *KeyRing) (*PasetoPublicMaker, error) {
+	if keys.Active().Algorithm != AlgEdDSA {
+		return nil, fmt.Errorf("v4.public tokens need an Ed25519 key, %s is %s", keys.Active().ID, keys.Active().Algorithm)
+	}
+
+	return &PasetoPublicMaker{
+		keys: keys,
+	}, nil
+}
+
+type pasetoFooter struct {
+	Kid string `json:"kid"`
+}
+
+// registered claims of the PASETO spec next to our own
+type pasetoClaims struct {
+	ID        string    `json:"jti"`
+	Subject   string    `json:"sub"`
+	IssuedAt  time.Time `json:"iat"`
+	ExpiresAt time.Time `json:"exp"`
+	UserID    uint32    `json:"user_id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	Type      TokenType `json:"token_type"`
+}
+
+func (maker *PasetoPublicMaker) CreateToken(userID uint32, email string, role string, tokenType TokenType, duration time.Duration) (string, error) {
+	payload, err := NewPayload(userID, email, role, tokenType, duration)
+	if err != nil {
+		return "", err
+	}
+
+	key := maker.keys.Active()
+
+	message, err := json.Marshal(pasetoClaims{
+		ID:        payload.ID.String(),
+		Subject:   fmt.Sprint(userID),
+		IssuedAt:  payload.CreatedAt.UTC(),
+		ExpiresAt: payload.ExpiryAt.UTC(),
+		UserID:    userID,
+		Email:     email,
+		Role:      role,
+		Type:      tokenType,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	footer, err := json.Marshal(pasetoFooter{Kid: key.ID})
+	if err != nil {
+		return "", err
+	}
+
+	signature, err := key.sign(pae([]byte(pasetoV4PublicHeader), message, footer, nil))
+	if err != nil {
+		return "", err
+	}
+
+	return pasetoV4PublicHeader +
+		base64.RawURLEncoding.EncodeToString(append(message, signature...)) + "." +
+		base64.RawURLEncoding.EncodeToString(footer), nil
+}
+
+func (maker *PasetoPublicMaker) VerifyToken(token string) (*Payload, error) {
+	if !strings.HasPrefix(token, pasetoV4PublicHeader) {
+		return nil, errors.New("token is not a v4.public paseto")
+	}
+
+	parts := strings.Split(strings.TrimPrefix(token, pasetoV4PublicHeader), ".")
+	if len(parts) != 2 {
+		return nil, errors.New("token has no key footer")
+	}
+
+	body, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || len(body) < ed25519.SignatureSize {
+		return nil, errors.New("invalid token body")
+	}
+
+	footer, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid token footer: %w", err)
+	}
+
+	var f pasetoFooter
+	if err := json.Unmarshal(footer, &f); err != nil {
+		return nil, fmt.Errorf("invalid token footer: %w", err)
+	}
+
+	key, err := maker.keys.Key(f.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	if key.Algorithm != AlgEdDSA {
+		return nil, fmt.Errorf("token key %s is not an Ed25519 key", key.ID)
+	}
+
+	message := body[:len(body)-ed25519.SignatureSize]
+	signature := body[len(body)-ed25519.SignatureSize:]
+
+	if err := key.verify(pae([]byte(pasetoV4PublicHeader), message, footer, nil), signature); err != nil {
+		return nil, err
+	}
+
+	var claims pasetoClaims
+	if err := json.Unmarshal(message, &claims); err != nil {
+		return nil, fmt.Errorf("invalid token claims: %w", err)
+	}
+
+	id, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token id: %w", err)
+	}
+
+	payload := &Payload{
+		ID:        id,
+		UserID:    claims.UserID,
+		Email:     claims.Email,
+		Role:      claims.Role,
+		Type:      claims.Type,
+		CreatedAt: claims.IssuedAt,
+		ExpiryAt:  claims.ExpiresAt,
+	}
+
+	if err := payload.Valid(); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+// JWKS publishes the public keys tokens can be verified with.
+func (maker *PasetoPublicMaker) JWKS() JWKSet {
+	return maker.keys.JWKS()
+}
+
+// pae is the pre-authentication encoding of the PASETO spec, every piece is
+// prefixed with its length so pieces cannot be shifted into each other.
+func pae(pieces ...[]byte) []byte {
+	var buf bytes.Buffer
+
+	le64 := func(n uint64) {
+		var b [8]byte
+
+		// the spec clears the top bit for interoperability
+		binary.LittleEndian.PutUint64(b[:], n&(1<<63-1))
+		buf.Write(b[:])
+	}
+
+	le64(uint64(len(pieces)))
+
+	for _, piece := range pieces {
+		le64(uint64(len(piece)))
+		buf.Write(piece)
+	}
+
+	return buf.Bytes()
+}
@@ -6,16 +6,26 @@ import (
 	"github.com/google/uuid"
 )
 
+// TokenType tells access tokens from refresh tokens. A refresh token is only
+// good for getting a new access token, never for calling the api.
+type TokenType string
+
+const (
+	TokenTypeAccess  TokenType = "access"
+	TokenTypeRefresh TokenType = "refresh"
+)
+
 type Payload struct {
 	ID        uuid.UUID `json:"id"`
 	UserID    uint32    `json:"user_id"`
 	Email     string    `json:"email"`
 	Role      string    `json:"role"       binding:"oneof=USER ADMIN"`
+	Type      TokenType `json:"token_type"`
 	CreatedAt time.Time `json:"created_at"`
 	ExpiryAt  time.Time `json:"expiry_at"`
 }
 
-func NewPayload(userID uint32, email string, role string, duration time.Duration) (*Payload, error) {
+func NewPayload(userID uint32, email string, role string, tokenType TokenType, duration time.Duration) (*Payload, error) {
 	tokenId, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
@@ -26,6 +36,7 @@ func NewPayload(userID uint32, email string, role string, duration time.Duration
 		UserID:    userID,
 		Email:     email,
 		Role:      role,
+		Type:      tokenType,
 		CreatedAt: time.Now(),
 		ExpiryAt:  time.Now().Add(duration),
 	}
@@ -1,5 +1,11 @@
 package pkg
 
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
 func StringPtr(s string) *string { return &s }
 
 func Uint32Ptr(i uint32) *uint32 { return &i }
@@ -7,3 +13,48 @@ func Uint32Ptr(i uint32) *uint32 { return &i }
 func Float64Ptr(f float64) *float64 { return &f }
 
 func BoolPtr(b bool) *bool { return &b }
+
+func TimePtr(t time.Time) *time.Time { return &t }
+
+// maxSlugLength leaves room for the -2, -3 suffixes added on collisions
+const maxSlugLength = 200
+
+// Slugify turns a title into a lower case, dash separated url segment.
+func Slugify(title string) string {
+	var b strings.Builder
+
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+
+			continue
+		}
+
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+
+	slug := []rune(strings.Trim(b.String(), "-"))
+	if len(slug) > maxSlugLength {
+		slug = slug[:maxSlugLength]
+	}
+
+	return strings.Trim(string(slug), "-")
+}
+
+// SplitList splits a comma separated config value, dropping empty entries.
+func SplitList(s string) []string {
+	var values []string
+
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}